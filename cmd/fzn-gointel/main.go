@@ -0,0 +1,54 @@
+// Command fzn-gointel solves FlatZinc models with gointel, so MiniZinc can use gointel as a backend. To register it,
+// put fzn-gointel on the PATH and copy gointel.msc into a MiniZinc solver configuration directory, with mznlib
+// pointing at this directory's mznlib.
+//
+//	fzn-gointel [-a] [-n solutions] [-t milliseconds] model.fzn
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/mtresnik/gointel/pkg/gointel"
+	"os"
+	"time"
+)
+
+func main() {
+	all := flag.Bool("a", false, "print all solutions, or every improving solution of an optimization")
+	limit := flag.Int("n", 0, "stop after this many solutions, 0 for no limit")
+	timeLimit := flag.Int("t", 0, "time limit in milliseconds, 0 for no limit")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: fzn-gointel [-a] [-n solutions] [-t milliseconds] model.fzn")
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *all || *limit > 0, *limit, time.Duration(*timeLimit)*time.Millisecond); err != nil {
+		fmt.Fprintln(os.Stderr, "fzn-gointel:", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string, all bool, limit int, timeLimit time.Duration) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	model, err := gointel.ReadFlatZinc(file)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeLimit)
+		defer cancel()
+	}
+	out := bufio.NewWriter(os.Stdout)
+	if err := model.WriteSolutions(ctx, out, all, limit); err != nil {
+		return err
+	}
+	return out.Flush()
+}
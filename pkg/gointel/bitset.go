@@ -0,0 +1,85 @@
+package gointel
+
+import "math/bits"
+
+// Bitset is a fixed size set of small non-negative integers.
+type Bitset []uint64
+
+func NewBitset(size int) Bitset {
+	return make(Bitset, (size+63)/64)
+}
+
+func (b Bitset) Has(i int) bool {
+	if i < 0 || i/64 >= len(b) {
+		return false
+	}
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+func (b Bitset) Set(i int) {
+	if i < 0 || i/64 >= len(b) {
+		return
+	}
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+func (b Bitset) Clear(i int) {
+	if i < 0 || i/64 >= len(b) {
+		return
+	}
+	b[i/64] &^= 1 << (uint(i) % 64)
+}
+
+func (b Bitset) Count() int {
+	count := 0
+	for _, word := range b {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+func (b Bitset) IsEmpty() bool {
+	for _, word := range b {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// First returns the smallest member, or -1 when the set is empty.
+func (b Bitset) First() int {
+	for index, word := range b {
+		if word != 0 {
+			return index*64 + bits.TrailingZeros64(word)
+		}
+	}
+	return -1
+}
+
+func (b Bitset) Clone() Bitset {
+	ret := make(Bitset, len(b))
+	copy(ret, b)
+	return ret
+}
+
+// Union sets every member of other in b.
+func (b Bitset) Union(other Bitset) {
+	for i := range b {
+		if i < len(other) {
+			b[i] |= other[i]
+		}
+	}
+}
+
+// Members returns the members in ascending order.
+func (b Bitset) Members() []int {
+	ret := make([]int, 0, b.Count())
+	for index, word := range b {
+		for word != 0 {
+			ret = append(ret, index*64+bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+	return ret
+}
@@ -147,17 +147,12 @@ func NewCardinalityConstraint[VAR comparable, DOMAIN comparable](variables []VAR
 	}
 }
 
+func (c *CardinalityConstraint[VAR, DOMAIN]) asCount() *CountConstraint[VAR, DOMAIN] {
+	return NewAtMostConstraint(c.Variables, c.MaxCount, c.Domain)
+}
+
 func (c *CardinalityConstraint[VAR, DOMAIN]) IsPossiblySatisfied(assignment map[VAR]DOMAIN) bool {
-	count := 0
-	for _, d := range assignment {
-		if d == c.Domain {
-			count++
-		}
-		if count > c.MaxCount {
-			return false
-		}
-	}
-	return true
+	return c.asCount().IsPossiblySatisfied(assignment)
 }
 
 func (c *CardinalityConstraint[VAR, DOMAIN]) GetVariables() []VAR {
@@ -178,11 +173,201 @@ func (c *CardinalityConstraint[VAR, DOMAIN]) IsReusable() bool {
 }
 
 func (c *CardinalityConstraint[VAR, DOMAIN]) ReduceDomain(variable VAR, assignment map[VAR]DOMAIN, domain []DOMAIN) []DOMAIN {
+	return c.asCount().ReduceDomain(variable, assignment, domain)
+}
+
+// </editor-fold>
+
+// CountConstraint <editor-fold>
+
+// CountConstraint bounds how many of its Variables take the value Domain.
+// Use NewAtMostConstraint, NewAtLeastConstraint or NewExactlyConstraint to build one.
+type CountConstraint[VAR comparable, DOMAIN comparable] struct {
+	Variables []VAR
+	Domain    DOMAIN
+	MinCount  int
+	MaxCount  int
+}
+
+func NewAtMostConstraint[VAR comparable, DOMAIN comparable](variables []VAR, maxCount int, domain DOMAIN) *CountConstraint[VAR, DOMAIN] {
+	return &CountConstraint[VAR, DOMAIN]{
+		Variables: variables,
+		Domain:    domain,
+		MinCount:  0,
+		MaxCount:  maxCount,
+	}
+}
+
+func NewAtLeastConstraint[VAR comparable, DOMAIN comparable](variables []VAR, minCount int, domain DOMAIN) *CountConstraint[VAR, DOMAIN] {
+	return &CountConstraint[VAR, DOMAIN]{
+		Variables: variables,
+		Domain:    domain,
+		MinCount:  minCount,
+		MaxCount:  len(variables),
+	}
+}
+
+func NewExactlyConstraint[VAR comparable, DOMAIN comparable](variables []VAR, count int, domain DOMAIN) *CountConstraint[VAR, DOMAIN] {
+	return &CountConstraint[VAR, DOMAIN]{
+		Variables: variables,
+		Domain:    domain,
+		MinCount:  count,
+		MaxCount:  count,
+	}
+}
+
+// countInScope returns how many variables in scope are assigned the value and how many are unassigned, ignoring skip.
+func countInScope[VAR comparable, DOMAIN comparable](variables []VAR, assignment map[VAR]DOMAIN, value DOMAIN, skip *VAR) (int, int) {
+	matched, unassigned := 0, 0
+	for _, variable := range variables {
+		if skip != nil && variable == *skip {
+			continue
+		}
+		if d, ok := assignment[variable]; !ok {
+			unassigned++
+		} else if d == value {
+			matched++
+		}
+	}
+	return matched, unassigned
+}
+
+func (c *CountConstraint[VAR, DOMAIN]) IsPossiblySatisfied(assignment map[VAR]DOMAIN) bool {
+	matched, unassigned := countInScope(c.Variables, assignment, c.Domain, nil)
+	return matched <= c.MaxCount && matched+unassigned >= c.MinCount
+}
+
+func (c *CountConstraint[VAR, DOMAIN]) GetVariables() []VAR {
+	return c.Variables
+}
+
+func (c *CountConstraint[VAR, DOMAIN]) IsSatisfied(assignment map[VAR]DOMAIN) bool {
+	return c.IsPossiblySatisfied(assignment)
+}
+
+func (c *CountConstraint[VAR, DOMAIN]) AsLocal() *LocalConstraint[VAR, DOMAIN] {
+	var localConstraint LocalConstraint[VAR, DOMAIN] = c
+	return &localConstraint
+}
+
+func (c *CountConstraint[VAR, DOMAIN]) IsReusable() bool {
+	return false
+}
+
+func (c *CountConstraint[VAR, DOMAIN]) ReduceDomain(variable VAR, assignment map[VAR]DOMAIN, domain []DOMAIN) []DOMAIN {
+	if !goutils.Contains(c.Variables, func(v VAR) bool { return v == variable }) {
+		return domain
+	}
+	matched, unassigned := countInScope(c.Variables, assignment, c.Domain, &variable)
+	if matched >= c.MaxCount {
+		// The value is used up, so the variable has to take something else
+		return goutils.Filter(domain, func(d DOMAIN) bool { return d != c.Domain })
+	}
+	if matched+unassigned < c.MinCount {
+		// The remaining variables cannot reach the minimum without this one
+		return goutils.Filter(domain, func(d DOMAIN) bool { return d == c.Domain })
+	}
 	return domain
 }
 
 // </editor-fold>
 
+// GlobalCardinalityConstraint <editor-fold>
+
+type CardinalityBounds struct {
+	Min int
+	Max int
+}
+
+// GlobalCardinalityConstraint restricts, for every value in Bounds, how many of Variables take that value.
+// Values without bounds are unrestricted.
+type GlobalCardinalityConstraint[VAR comparable, DOMAIN comparable] struct {
+	Variables []VAR
+	Bounds    map[DOMAIN]CardinalityBounds
+}
+
+func NewGlobalCardinalityConstraint[VAR comparable, DOMAIN comparable](variables []VAR, bounds map[DOMAIN]CardinalityBounds) *GlobalCardinalityConstraint[VAR, DOMAIN] {
+	return &GlobalCardinalityConstraint[VAR, DOMAIN]{
+		Variables: variables,
+		Bounds:    bounds,
+	}
+}
+
+// counts returns the occurrences of each value in scope, the number of unassigned variables
+// and the total number of occurrences still required to meet every minimum.
+func (g *GlobalCardinalityConstraint[VAR, DOMAIN]) counts(assignment map[VAR]DOMAIN, skip *VAR) (map[DOMAIN]int, int, int) {
+	occurrences := map[DOMAIN]int{}
+	unassigned := 0
+	for _, variable := range g.Variables {
+		if skip != nil && variable == *skip {
+			continue
+		}
+		if d, ok := assignment[variable]; ok {
+			occurrences[d]++
+		} else {
+			unassigned++
+		}
+	}
+	deficit := 0
+	for value, bounds := range g.Bounds {
+		if missing := bounds.Min - occurrences[value]; missing > 0 {
+			deficit += missing
+		}
+	}
+	return occurrences, unassigned, deficit
+}
+
+func (g *GlobalCardinalityConstraint[VAR, DOMAIN]) IsPossiblySatisfied(assignment map[VAR]DOMAIN) bool {
+	occurrences, unassigned, deficit := g.counts(assignment, nil)
+	for value, bounds := range g.Bounds {
+		if occurrences[value] > bounds.Max {
+			return false
+		}
+	}
+	return deficit <= unassigned
+}
+
+func (g *GlobalCardinalityConstraint[VAR, DOMAIN]) GetVariables() []VAR {
+	return g.Variables
+}
+
+func (g *GlobalCardinalityConstraint[VAR, DOMAIN]) IsSatisfied(assignment map[VAR]DOMAIN) bool {
+	return g.IsPossiblySatisfied(assignment)
+}
+
+func (g *GlobalCardinalityConstraint[VAR, DOMAIN]) AsLocal() *LocalConstraint[VAR, DOMAIN] {
+	var localConstraint LocalConstraint[VAR, DOMAIN] = g
+	return &localConstraint
+}
+
+func (g *GlobalCardinalityConstraint[VAR, DOMAIN]) IsReusable() bool {
+	return false
+}
+
+// ReduceDomain removes values that already reached their maximum and, when the other unassigned
+// variables cannot cover the outstanding minimums on their own, keeps only values that are still short.
+func (g *GlobalCardinalityConstraint[VAR, DOMAIN]) ReduceDomain(variable VAR, assignment map[VAR]DOMAIN, domain []DOMAIN) []DOMAIN {
+	if !goutils.Contains(g.Variables, func(v VAR) bool { return v == variable }) {
+		return domain
+	}
+	occurrences, unassigned, deficit := g.counts(assignment, &variable)
+	if deficit > unassigned+1 {
+		return []DOMAIN{}
+	}
+	return goutils.Filter(domain, func(d DOMAIN) bool {
+		bounds, ok := g.Bounds[d]
+		if !ok {
+			return deficit <= unassigned
+		}
+		if occurrences[d] >= bounds.Max {
+			return false
+		}
+		return deficit <= unassigned || occurrences[d] < bounds.Min
+	})
+}
+
+// </editor-fold>
+
 // MinimumHeuristicConstraint <editor-fold>
 type MinimumHeuristicConstraint[VAR comparable, DOMAIN comparable] struct {
 	Variables []VAR
@@ -233,3 +418,327 @@ func (m *MinimumHeuristicConstraint[VAR, DOMAIN]) ReduceDomain(variable VAR, ass
 }
 
 // </editor-fold>
+
+// LexLessEqConstraint <editor-fold>
+
+// LexLessEqConstraint requires the vector Left to be lexicographically less than or equal to the vector Right.
+// When Values is set, values read from Right are mapped through it before being compared. When Literals is set, Right
+// is read from the image of the assignment, each assigned Right variable and its value mapped onto another pair.
+type LexLessEqConstraint[VAR comparable, DOMAIN comparable] struct {
+	Left      []VAR
+	Right     []VAR
+	Values    map[DOMAIN]DOMAIN
+	Literals  func(variable VAR, value DOMAIN) (VAR, DOMAIN)
+	Compare   func(a, b DOMAIN) int
+	variables []VAR
+}
+
+func NewLexLessEqConstraint[VAR comparable, DOMAIN comparable](left, right []VAR, compare func(a, b DOMAIN) int) *LexLessEqConstraint[VAR, DOMAIN] {
+	return &LexLessEqConstraint[VAR, DOMAIN]{
+		Left:    left,
+		Right:   right,
+		Compare: compare,
+	}
+}
+
+func (l *LexLessEqConstraint[VAR, DOMAIN]) mapValue(value DOMAIN) DOMAIN {
+	if mapped, ok := l.Values[value]; ok {
+		return mapped
+	}
+	return value
+}
+
+// image is the assignment the Right variables are read from, the assignment itself unless Literals is set.
+func (l *LexLessEqConstraint[VAR, DOMAIN]) image(assignment map[VAR]DOMAIN) map[VAR]DOMAIN {
+	if l.Literals == nil {
+		return assignment
+	}
+	ret := make(map[VAR]DOMAIN, len(l.Right))
+	for _, variable := range l.Right {
+		if value, ok := assignment[variable]; ok {
+			mappedVariable, mappedValue := l.Literals(variable, value)
+			ret[mappedVariable] = mappedValue
+		}
+	}
+	return ret
+}
+
+func (l *LexLessEqConstraint[VAR, DOMAIN]) IsPossiblySatisfied(assignment map[VAR]DOMAIN) bool {
+	image := l.image(assignment)
+	for i := 0; i < len(l.Left) && i < len(l.Right); i++ {
+		left, leftOk := assignment[l.Left[i]]
+		right, rightOk := image[l.Right[i]]
+		if !leftOk || !rightOk {
+			return true
+		}
+		cmp := l.Compare(left, l.mapValue(right))
+		if cmp < 0 {
+			return true
+		}
+		if cmp > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *LexLessEqConstraint[VAR, DOMAIN]) GetVariables() []VAR {
+	if l.variables == nil {
+		l.variables = goutils.Unique(append(append([]VAR{}, l.Left...), l.Right...))
+	}
+	return l.variables
+}
+
+func (l *LexLessEqConstraint[VAR, DOMAIN]) IsSatisfied(assignment map[VAR]DOMAIN) bool {
+	return l.IsPossiblySatisfied(assignment)
+}
+
+func (l *LexLessEqConstraint[VAR, DOMAIN]) AsLocal() *LocalConstraint[VAR, DOMAIN] {
+	var localConstraint LocalConstraint[VAR, DOMAIN] = l
+	return &localConstraint
+}
+
+func (l *LexLessEqConstraint[VAR, DOMAIN]) IsReusable() bool {
+	return false
+}
+
+// ReduceDomain prunes the variable when it sits at the first undecided position of the vectors. Literal mappings are
+// only checked once assigned.
+func (l *LexLessEqConstraint[VAR, DOMAIN]) ReduceDomain(variable VAR, assignment map[VAR]DOMAIN, domain []DOMAIN) []DOMAIN {
+	if _, assigned := assignment[variable]; assigned || l.Literals != nil {
+		return domain
+	}
+	for i := 0; i < len(l.Left) && i < len(l.Right); i++ {
+		leftVariable, rightVariable := l.Left[i], l.Right[i]
+		left, leftOk := assignment[leftVariable]
+		right, rightOk := assignment[rightVariable]
+		if leftOk && rightOk {
+			cmp := l.Compare(left, l.mapValue(right))
+			if cmp != 0 {
+				return domain
+			}
+			continue
+		}
+		switch {
+		case leftVariable == variable && rightVariable == variable:
+			return goutils.Filter(domain, func(d DOMAIN) bool { return l.Compare(d, l.mapValue(d)) <= 0 })
+		case leftVariable == variable && rightOk:
+			return goutils.Filter(domain, func(d DOMAIN) bool { return l.Compare(d, l.mapValue(right)) <= 0 })
+		case rightVariable == variable && leftOk:
+			return goutils.Filter(domain, func(d DOMAIN) bool { return l.Compare(left, l.mapValue(d)) <= 0 })
+		}
+		return domain
+	}
+	return domain
+}
+
+// </editor-fold>
+
+// ExtensionConstraint <editor-fold>
+
+// ExtensionConstraint lists the tuples its Variables may take, or the ones they may not when Conflicts is set.
+type ExtensionConstraint[VAR comparable, DOMAIN comparable] struct {
+	Variables []VAR
+	Tuples    [][]DOMAIN
+	Conflicts bool
+}
+
+func NewSupportsConstraint[VAR comparable, DOMAIN comparable](variables []VAR, tuples [][]DOMAIN) *ExtensionConstraint[VAR, DOMAIN] {
+	return &ExtensionConstraint[VAR, DOMAIN]{Variables: variables, Tuples: tuples}
+}
+
+func NewConflictsConstraint[VAR comparable, DOMAIN comparable](variables []VAR, tuples [][]DOMAIN) *ExtensionConstraint[VAR, DOMAIN] {
+	return &ExtensionConstraint[VAR, DOMAIN]{Variables: variables, Tuples: tuples, Conflicts: true}
+}
+
+// matches tells whether the tuple agrees with every assigned variable, and whether all of them are assigned.
+func (e *ExtensionConstraint[VAR, DOMAIN]) matches(tuple []DOMAIN, assignment map[VAR]DOMAIN) (bool, bool) {
+	complete := true
+	for i, variable := range e.Variables {
+		value, ok := assignment[variable]
+		if !ok {
+			complete = false
+		} else if value != tuple[i] {
+			return false, complete
+		}
+	}
+	return true, complete
+}
+
+// IsPossiblySatisfied looks for a supporting tuple consistent with the assigned variables. Conflicts only rule out
+// complete assignments.
+func (e *ExtensionConstraint[VAR, DOMAIN]) IsPossiblySatisfied(assignment map[VAR]DOMAIN) bool {
+	for _, tuple := range e.Tuples {
+		matched, complete := e.matches(tuple, assignment)
+		if e.Conflicts && matched && complete {
+			return false
+		}
+		if !e.Conflicts && matched {
+			return true
+		}
+	}
+	return e.Conflicts
+}
+
+func (e *ExtensionConstraint[VAR, DOMAIN]) GetVariables() []VAR {
+	return e.Variables
+}
+
+func (e *ExtensionConstraint[VAR, DOMAIN]) IsSatisfied(assignment map[VAR]DOMAIN) bool {
+	return e.IsPossiblySatisfied(assignment)
+}
+
+func (e *ExtensionConstraint[VAR, DOMAIN]) AsLocal() *LocalConstraint[VAR, DOMAIN] {
+	var localConstraint LocalConstraint[VAR, DOMAIN] = e
+	return &localConstraint
+}
+
+func (e *ExtensionConstraint[VAR, DOMAIN]) IsReusable() bool {
+	return false
+}
+
+func (e *ExtensionConstraint[VAR, DOMAIN]) ReduceDomain(variable VAR, assignment map[VAR]DOMAIN, domain []DOMAIN) []DOMAIN {
+	return reduceByPossiblySatisfied[VAR, DOMAIN](e, variable, assignment, domain)
+}
+
+// reduceByPossiblySatisfied keeps the values of an unassigned variable in the constraint's scope that leave it
+// possibly satisfied.
+func reduceByPossiblySatisfied[VAR comparable, DOMAIN comparable](constraint LocalConstraint[VAR, DOMAIN], variable VAR, assignment map[VAR]DOMAIN, domain []DOMAIN) []DOMAIN {
+	if _, assigned := assignment[variable]; assigned || !goutils.Contains(constraint.GetVariables(), func(v VAR) bool { return v == variable }) {
+		return domain
+	}
+	retDomain := []DOMAIN{}
+	for _, value := range domain {
+		assignment[variable] = value
+		if constraint.IsPossiblySatisfied(assignment) {
+			retDomain = append(retDomain, value)
+		}
+	}
+	delete(assignment, variable)
+	return retDomain
+}
+
+// </editor-fold>
+
+// LinearSumConstraint <editor-fold>
+
+type SumOperator int
+
+const (
+	SUM_LT SumOperator = iota
+	SUM_LE
+	SUM_EQ
+	SUM_NE
+	SUM_GE
+	SUM_GT
+)
+
+// LinearSumConstraint requires the sum of Coeffs[i] * Variables[i] to compare to Right with Operator.
+// Min and Max bound the values of the variables, so partial assignments can be rejected early. Variables without
+// bounds are assumed to be unbounded.
+type LinearSumConstraint[VAR comparable] struct {
+	Variables []VAR
+	Coeffs    []int
+	Operator  SumOperator
+	Right     int
+	Min       map[VAR]int
+	Max       map[VAR]int
+}
+
+// NewLinearSumConstraint bounds every variable by its domain in domainMap. Nil coeffs are all 1.
+func NewLinearSumConstraint[VAR comparable](variables []VAR, coeffs []int, operator SumOperator, right int, domainMap map[VAR][]int) *LinearSumConstraint[VAR] {
+	if coeffs == nil {
+		coeffs = make([]int, len(variables))
+		for i := range coeffs {
+			coeffs[i] = 1
+		}
+	}
+	ret := &LinearSumConstraint[VAR]{
+		Variables: variables,
+		Coeffs:    coeffs,
+		Operator:  operator,
+		Right:     right,
+		Min:       map[VAR]int{},
+		Max:       map[VAR]int{},
+	}
+	for _, variable := range variables {
+		domain := domainMap[variable]
+		if len(domain) == 0 {
+			continue
+		}
+		ret.Min[variable], ret.Max[variable] = domain[0], domain[0]
+		for _, value := range domain {
+			ret.Min[variable] = min(ret.Min[variable], value)
+			ret.Max[variable] = max(ret.Max[variable], value)
+		}
+	}
+	return ret
+}
+
+// bounds returns the smallest and largest sums the assignment can still reach, bounded reports whether both are finite.
+func (l *LinearSumConstraint[VAR]) bounds(assignment map[VAR]int) (int, int, bool) {
+	low, high := 0, 0
+	for i, variable := range l.Variables {
+		if value, ok := assignment[variable]; ok {
+			low += l.Coeffs[i] * value
+			high += l.Coeffs[i] * value
+			continue
+		}
+		lo, hasMin := l.Min[variable]
+		hi, hasMax := l.Max[variable]
+		if !hasMin || !hasMax {
+			return 0, 0, false
+		}
+		if l.Coeffs[i] < 0 {
+			lo, hi = hi, lo
+		}
+		low += l.Coeffs[i] * lo
+		high += l.Coeffs[i] * hi
+	}
+	return low, high, true
+}
+
+func (l *LinearSumConstraint[VAR]) IsPossiblySatisfied(assignment map[VAR]int) bool {
+	low, high, bounded := l.bounds(assignment)
+	if !bounded {
+		return true
+	}
+	switch l.Operator {
+	case SUM_LT:
+		return low < l.Right
+	case SUM_LE:
+		return low <= l.Right
+	case SUM_EQ:
+		return low <= l.Right && l.Right <= high
+	case SUM_NE:
+		return low != high || low != l.Right
+	case SUM_GE:
+		return high >= l.Right
+	case SUM_GT:
+		return high > l.Right
+	}
+	return true
+}
+
+func (l *LinearSumConstraint[VAR]) GetVariables() []VAR {
+	return l.Variables
+}
+
+func (l *LinearSumConstraint[VAR]) IsSatisfied(assignment map[VAR]int) bool {
+	return l.IsPossiblySatisfied(assignment)
+}
+
+func (l *LinearSumConstraint[VAR]) AsLocal() *LocalConstraint[VAR, int] {
+	var localConstraint LocalConstraint[VAR, int] = l
+	return &localConstraint
+}
+
+func (l *LinearSumConstraint[VAR]) IsReusable() bool {
+	return false
+}
+
+func (l *LinearSumConstraint[VAR]) ReduceDomain(variable VAR, assignment map[VAR]int, domain []int) []int {
+	return reduceByPossiblySatisfied[VAR, int](l, variable, assignment, domain)
+}
+
+// </editor-fold>
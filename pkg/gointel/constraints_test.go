@@ -1 +1,80 @@
 package gointel
+
+import "testing"
+
+func TestCardinalityConstraint_IgnoresOutOfScope(t *testing.T) {
+	constraint := NewCardinalityConstraint([]string{"A", "B"}, 1, "red")
+	assignment := map[string]string{"A": "red", "C": "red", "D": "red"}
+	if !constraint.IsPossiblySatisfied(assignment) {
+		t.Errorf("variables outside the scope should not be counted")
+	}
+	assignment["B"] = "red"
+	if constraint.IsPossiblySatisfied(assignment) {
+		t.Errorf("expected the constraint to fail with two reds in scope")
+	}
+}
+
+func TestCountConstraint_Exactly(t *testing.T) {
+	variables := []string{"A", "B", "C", "D"}
+	domainMap := map[string][]string{}
+	for _, variable := range variables {
+		domainMap[variable] = []string{"a", "b"}
+	}
+	csp := NewCSPTree(domainMap)
+	var c Constraint[string, string] = NewExactlyConstraint(variables, 2, "a")
+	csp.AddConstraint(&c)
+	solutions := csp.FindAllSolutions()
+	if len(solutions) != 6 {
+		t.Fatalf("expected 6 solutions, got %d", len(solutions))
+	}
+	for _, solution := range solutions {
+		count := 0
+		for _, value := range solution {
+			if value == "a" {
+				count++
+			}
+		}
+		if count != 2 {
+			t.Errorf("expected exactly 2 a's, got %v", solution)
+		}
+	}
+}
+
+func TestCountConstraint_ReduceDomain(t *testing.T) {
+	variables := []int{0, 1, 2}
+	atLeast := NewAtLeastConstraint(variables, 2, 7)
+	reduced := atLeast.ReduceDomain(2, map[int]int{0: 7, 1: 3}, []int{3, 7})
+	if len(reduced) != 1 || reduced[0] != 7 {
+		t.Errorf("expected [7], got %v", reduced)
+	}
+	atMost := NewAtMostConstraint(variables, 1, 7)
+	reduced = atMost.ReduceDomain(2, map[int]int{0: 7}, []int{3, 7})
+	if len(reduced) != 1 || reduced[0] != 3 {
+		t.Errorf("expected [3], got %v", reduced)
+	}
+}
+
+func TestGlobalCardinalityConstraint(t *testing.T) {
+	variables := []string{"X", "Y", "Z"}
+	domainMap := map[string][]int{}
+	for _, variable := range variables {
+		domainMap[variable] = []int{1, 2, 3}
+	}
+	csp := NewCSPTree(domainMap)
+	var c Constraint[string, int] = NewGlobalCardinalityConstraint(variables, map[int]CardinalityBounds{
+		1: {Min: 1, Max: 1},
+		2: {Min: 0, Max: 2},
+		3: {Min: 0, Max: 0},
+	})
+	csp.AddConstraint(&c)
+	solutions := csp.FindAllSolutions()
+	if len(solutions) != 3 {
+		t.Fatalf("expected 3 solutions, got %d", len(solutions))
+	}
+
+	gcc := NewGlobalCardinalityConstraint(variables, map[int]CardinalityBounds{1: {Min: 2, Max: 3}})
+	reduced := gcc.ReduceDomain("Z", map[string]int{"X": 2}, []int{1, 2, 3})
+	if len(reduced) != 1 || reduced[0] != 1 {
+		t.Errorf("expected [1], got %v", reduced)
+	}
+}
@@ -1,11 +1,16 @@
 package gointel
 
 import (
+	"context"
+	"fmt"
 	"sort"
+	"strings"
 )
 
 const CSP_MAX_CHILDREN = 200
 
+const CSP_MAX_CUTSET_SIZE = 8
+
 func getSortedVariables[VAR comparable, DOMAIN comparable](variables []VAR, constraints map[VAR][]LocalConstraint[VAR, DOMAIN]) []VAR {
 	if len(constraints) == 0 {
 		return variables
@@ -18,6 +23,16 @@ func getSortedVariables[VAR comparable, DOMAIN comparable](variables []VAR, cons
 	return cloned
 }
 
+// assignmentKey returns a string that is identical for equal assignments regardless of map iteration order.
+func assignmentKey[VAR comparable, DOMAIN comparable](assignment map[VAR]DOMAIN) string {
+	pairs := make([]string, 0, len(assignment))
+	for variable, value := range assignment {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", variable, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
+
 type VarDomainMapCollection[VAR comparable, DOMAIN comparable] interface {
 	GetDomainMap() map[VAR][]DOMAIN
 	SetDomainMap(map[VAR][]DOMAIN)
@@ -31,31 +46,74 @@ type CSP[VAR comparable, DOMAIN comparable] interface {
 	Preprocess()
 	GetLocalConstraints() map[VAR][]*LocalConstraint[VAR, DOMAIN]
 	GetGlobalConstraints() []*GlobalConstraint[VAR, DOMAIN]
-	AddConstraint(constraint *Constraint[VAR, DOMAIN])
-	AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN])
+	AddConstraint(constraint *Constraint[VAR, DOMAIN]) error
+	AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error
 	FindAllSolutions() []map[VAR]DOMAIN
 	FindOneSolution() map[VAR]DOMAIN
 	GetSeeds() *map[VAR]DOMAIN
 }
 
+// Every solver can be used wherever a CSP is expected.
+var (
+	_ CSP[int, int]         = &CSPAgent[int, int]{}
+	_ CSP[int, int]         = &CSPTree[int, int]{}
+	_ CSP[int, int]         = &CSPDomain[int, int]{}
+	_ CSP[int, int]         = &DecomposedCSP[int, int]{}
+	_ CSP[int, int]         = &TreeStructuredCSP[int, int]{}
+	_ CSP[int, int]         = &DistributedCSP[int, int]{}
+	_ CSP[int, int]         = &MinConflictsCSP[int, int]{}
+	_ CSP[int, int]         = &SATCSP[int, int]{}
+	_ CSP[int, int]         = &timeLimitedCSP[int, int]{}
+	_ CSP[int, int]         = &IntCSP{}
+	_ CSP[string, Interval] = &IntervalCSP{}
+)
+
+// CancellableCSP is implemented by solvers whose search can be stopped through a context.
+type CancellableCSP[VAR comparable, DOMAIN comparable] interface {
+	CSP[VAR, DOMAIN]
+	FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN
+}
+
 type MultiCSP[VAR comparable, DOMAIN comparable] interface {
 	CSP[VAR, DOMAIN]
 	GenerateSolutionChannel() chan map[VAR]DOMAIN
 }
 
+// CountSolutions counts the solutions of the csp up to limit, or all of them when limit is 0 or less. Solvers with a
+// CountSolutions method count on their own, those with a GenerateSolutionChannelContext method stop searching at the
+// limit.
+func CountSolutions[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN], limit int) int {
+	if counter, ok := csp.(interface{ CountSolutions(limit int) int }); ok {
+		return counter.CountSolutions(limit)
+	}
+	generator, ok := csp.(interface {
+		GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN
+	})
+	if !ok {
+		count := len(csp.FindAllSolutions())
+		if limit > 0 {
+			return min(count, limit)
+		}
+		return count
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	for range generator.GenerateSolutionChannelContext(ctx) {
+		count++
+		if limit > 0 && count >= limit {
+			break
+		}
+	}
+	return count
+}
+
 func IsLocallyConsistent[VAR comparable, DOMAIN comparable](
 	variable VAR,
 	assignment map[VAR]DOMAIN,
 	localConstraints map[VAR][]*LocalConstraint[VAR, DOMAIN],
 	globalConstraints []*GlobalConstraint[VAR, DOMAIN]) bool {
-	if len(localConstraints) == 0 {
-		return len(globalConstraints) != 0
-	}
-	tempConstraints, ok := localConstraints[variable]
-	if !ok {
-		return false
-	}
-	for _, constraint := range tempConstraints {
+	for _, constraint := range localConstraints[variable] {
 		if !(*constraint).IsPossiblySatisfied(assignment) {
 			return false
 		}
@@ -78,14 +136,7 @@ func IsAbsolutelyConsistent[VAR comparable, DOMAIN comparable](
 	assignment map[VAR]DOMAIN,
 	localConstraints map[VAR][]*LocalConstraint[VAR, DOMAIN],
 	globalConstraints []*GlobalConstraint[VAR, DOMAIN]) bool {
-	if len(localConstraints) == 0 {
-		return len(globalConstraints) != 0
-	}
-	tempConstraints, ok := localConstraints[variable]
-	if !ok {
-		return false
-	}
-	for _, constraint := range tempConstraints {
+	for _, constraint := range localConstraints[variable] {
 		if !(*constraint).IsSatisfied(assignment) {
 			return false
 		}
@@ -146,3 +197,78 @@ func ReduceDomain[VAR comparable, DOMAIN comparable](
 	}
 	return currDomain
 }
+
+// cspConstraints stores constraints the same way CSPTree and CSPDomain do: local constraints are indexed by
+// each of their variables while global constraints are kept in a single list.
+type cspConstraints[VAR comparable, DOMAIN comparable] struct {
+	localConstraints  map[VAR][]*LocalConstraint[VAR, DOMAIN]
+	globalConstraints []*GlobalConstraint[VAR, DOMAIN]
+	cspValidator[VAR, DOMAIN]
+}
+
+func newCSPConstraints[VAR comparable, DOMAIN comparable]() cspConstraints[VAR, DOMAIN] {
+	return cspConstraints[VAR, DOMAIN]{
+		localConstraints:  map[VAR][]*LocalConstraint[VAR, DOMAIN]{},
+		globalConstraints: []*GlobalConstraint[VAR, DOMAIN]{},
+	}
+}
+
+func (C *cspConstraints[VAR, DOMAIN]) add(constraint *Constraint[VAR, DOMAIN], contains func(VAR) bool) error {
+	if constraint == nil {
+		return nil
+	}
+	if err := C.accept(constraint, contains); err != nil {
+		return err
+	}
+	local := (*constraint).AsLocal()
+	if local != nil {
+		for _, variable := range (*local).GetVariables() {
+			if contains(variable) {
+				C.localConstraints[variable] = append(C.localConstraints[variable], local)
+			}
+		}
+	} else {
+		var global GlobalConstraint[VAR, DOMAIN] = *constraint
+		C.globalConstraints = append(C.globalConstraints, &global)
+	}
+	return nil
+}
+
+func (C *cspConstraints[VAR, DOMAIN]) GetLocalConstraints() map[VAR][]*LocalConstraint[VAR, DOMAIN] {
+	return C.localConstraints
+}
+
+func (C *cspConstraints[VAR, DOMAIN]) GetGlobalConstraints() []*GlobalConstraint[VAR, DOMAIN] {
+	return C.globalConstraints
+}
+
+// uniqueLocalConstraints flattens the per-variable lookup, returning each local constraint once in variable order.
+func uniqueLocalConstraints[VAR comparable, DOMAIN comparable](variables []VAR, localConstraints map[VAR][]*LocalConstraint[VAR, DOMAIN]) []*LocalConstraint[VAR, DOMAIN] {
+	seen := map[*LocalConstraint[VAR, DOMAIN]]bool{}
+	ret := []*LocalConstraint[VAR, DOMAIN]{}
+	for _, variable := range variables {
+		for _, constraint := range localConstraints[variable] {
+			if !seen[constraint] {
+				seen[constraint] = true
+				ret = append(ret, constraint)
+			}
+		}
+	}
+	return ret
+}
+
+// copyConstraints adds every constraint of from to each CSP in to, restricted to the variables each of them contains.
+func copyConstraints[VAR comparable, DOMAIN comparable](from CSP[VAR, DOMAIN], to ...CSP[VAR, DOMAIN]) {
+	toAdd := []*Constraint[VAR, DOMAIN]{}
+	for _, constraint := range uniqueLocalConstraints(from.GetVariables(), from.GetLocalConstraints()) {
+		var c Constraint[VAR, DOMAIN] = *constraint
+		toAdd = append(toAdd, &c)
+	}
+	for _, constraint := range from.GetGlobalConstraints() {
+		var c Constraint[VAR, DOMAIN] = *constraint
+		toAdd = append(toAdd, &c)
+	}
+	for _, csp := range to {
+		csp.AddAllConstraints(toAdd...)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mtresnik/goutils/pkg/goutils"
 	"sort"
 	"sync"
+	"time"
 )
 
 type CSPAgent[VAR comparable, DOMAIN comparable] struct {
@@ -16,8 +17,25 @@ type CSPAgent[VAR comparable, DOMAIN comparable] struct {
 	localConstraints  map[VAR][]*LocalConstraint[VAR, DOMAIN]
 	globalConstraints []*GlobalConstraint[VAR, DOMAIN]
 	SortingFunction   *func(a, b VAR) bool
+	// Hints are tried before the other values of their variable.
+	Hints map[VAR]DOMAIN
+	// SearchStrategy is the order nodes are expanded in, the heap of CSPNodeHeap by default.
+	SearchStrategy SearchStrategy
+	// NodePriority orders the heap, and the siblings of the other strategies, before their legal value counts.
+	NodePriority CSPNodePriority[VAR, DOMAIN]
+	// CheckpointPath is where the search writes a CSPCheckpoint every CheckpointInterval and when it stops, not at
+	// all when empty. Only CSP_SEARCH_HEAP writes checkpoints, the other strategies search without them and set
+	// CheckpointError to ErrUnsupported.
+	CheckpointPath     string
+	CheckpointInterval time.Duration
+	solutions          []map[VAR]DOMAIN
+	checkpointErr      error
+	cspValidator[VAR, DOMAIN]
 }
 
+// CSP_CHECKPOINT_INTERVAL is the default time between two checkpoints of a CSPAgent.
+const CSP_CHECKPOINT_INTERVAL = 30 * time.Second
+
 func NewCSPAgent[VAR comparable, DOMAIN comparable](domainMap *map[VAR][]DOMAIN, sortedVariables []VAR, stack []CSPNode[VAR, DOMAIN]) *CSPAgent[VAR, DOMAIN] {
 	return &CSPAgent[VAR, DOMAIN]{
 		SortedVariables:   sortedVariables,
@@ -28,9 +46,12 @@ func NewCSPAgent[VAR comparable, DOMAIN comparable](domainMap *map[VAR][]DOMAIN,
 	}
 }
 
-func (C *CSPAgent[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) {
+func (C *CSPAgent[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
 	if constraint == nil {
-		return
+		return nil
+	}
+	if err := C.accept(constraint, C.Contains); err != nil {
+		return err
 	}
 	local := (*constraint).AsLocal()
 	if local != nil {
@@ -52,12 +73,15 @@ func (C *CSPAgent[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN
 		var global GlobalConstraint[VAR, DOMAIN] = *constraint
 		C.globalConstraints = append(C.globalConstraints, &global)
 	}
+	return nil
 }
 
-func (C *CSPAgent[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) {
-	for _, constraint := range constraints {
-		C.AddConstraint(constraint)
-	}
+func (C *CSPAgent[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *CSPAgent[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
 }
 
 func GetLegalValues[VAR comparable, DOMAIN comparable](
@@ -111,7 +135,10 @@ type CSPNodeHeap[VAR comparable, DOMAIN comparable] []CSPNode[VAR, DOMAIN]
 // Implement heap.Interface (Len, Less, Swap, Push, Pop)
 func (h CSPNodeHeap[VAR, DOMAIN]) Len() int { return len(h) }
 func (h CSPNodeHeap[VAR, DOMAIN]) Less(i, j int) bool {
-	// Custom comparison logic: Min-Heap based on remaining legal values
+	// Min-Heap on the nodes' priority, then on remaining legal values
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority < h[j].Priority
+	}
 	remainingValuesI := len(h[i].LegalValues) // Assume LegalValues is pre-computed/legal values cached
 	remainingValuesJ := len(h[j].LegalValues) // Customize according to `GetLegalValues`
 	return remainingValuesI < remainingValuesJ
@@ -151,60 +178,108 @@ func (C *CSPAgent[VAR, DOMAIN]) generateStateKey(assignment map[VAR]DOMAIN) stri
 	return state
 }
 
+// expand returns the solution completed by the node, or the nodes for the next unassigned variable.
+// currentMap is the node's assignment as kept by a cspTrail; the returned solution is a copy of it.
+func (C *CSPAgent[VAR, DOMAIN]) expand(current *CSPNode[VAR, DOMAIN], currentMap map[VAR]DOMAIN) (map[VAR]DOMAIN, []CSPNode[VAR, DOMAIN]) {
+	// Check local consistency
+	if !IsLocallyConsistent(current.Variable, currentMap, C.GetLocalConstraints(), C.GetGlobalConstraints()) {
+		return nil, nil
+	}
+	// If all variables are assigned, check global consistency and yield solution
+	if len(currentMap) == len(C.GetVariables()) {
+		if IsConsistent(current.Variable, currentMap, C.GetLocalConstraints(), C.GetGlobalConstraints()) {
+			return CloneMap(currentMap), nil
+		}
+		return nil, nil
+	}
+
+	// Get the next variable to process
+	nextVariableIndex := goutils.IndexOf(C.GetVariables(), func(v VAR) bool {
+		_, assigned := currentMap[v]
+		return !assigned
+	})
+	if nextVariableIndex == -1 {
+		return nil, nil
+	}
+	nextVariable := C.GetVariables()[nextVariableIndex]
+	nextDomain, ok := C.GetDomainMap()[nextVariable]
+	if !ok {
+		return nil, nil
+	}
+
+	// Reduce the domain and create the subproblems
+	reduced := ReduceDomain(nextVariable, currentMap, nextDomain, C.GetLocalConstraints(), C.GetGlobalConstraints())
+	if hint, ok := C.Hints[nextVariable]; ok {
+		reduced = hintFirst(reduced, hint)
+	}
+	legalValues := GetLegalValues(nextVariable, currentMap, C.GetDomainForVariable(nextVariable), C.localConstraints, C.globalConstraints)
+	children := make([]CSPNode[VAR, DOMAIN], 0, len(reduced))
+	for _, domain := range reduced {
+		children = append(children, *NewCSPNode(nextVariable, nextVariableIndex, domain, legalValues, current))
+	}
+	return nil, children
+}
+
 func (C *CSPAgent[VAR, DOMAIN]) GenerateSolutionChannel() chan map[VAR]DOMAIN {
+	return C.GenerateSolutionChannelContext(context.Background())
+}
+
+// GenerateSolutionChannelContext stops searching and closes the channel once ctx is done. With a CheckpointPath,
+// the last checkpoint is written before the channel is closed.
+func (C *CSPAgent[VAR, DOMAIN]) GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN {
 	ch := make(chan map[VAR]DOMAIN)
 
 	go func() {
 		defer close(ch)
+		if C.SearchStrategy != CSP_SEARCH_HEAP {
+			if C.CheckpointPath != "" {
+				C.checkpointErr = fmt.Errorf("%w: %v search does not write checkpoints", ErrUnsupported, C.SearchStrategy)
+			}
+			C.searchDepthFirst(ctx, ch)
+			return
+		}
+		interval := C.CheckpointInterval
+		if interval <= 0 {
+			interval = CSP_CHECKPOINT_INTERVAL
+		}
+		lastCheckpoint := time.Now()
 
 		// Create and initialize the heap
 		nodeHeap := &CSPNodeHeap[VAR, DOMAIN]{}
 		heap.Init(nodeHeap)
 
 		// Add initial stack nodes to the heap
-		for _, node := range C.Stack {
+		for _, node := range C.prioritize(append([]CSPNode[VAR, DOMAIN]{}, C.Stack...), nil) {
 			heap.Push(nodeHeap, node)
 		}
 
-		var current CSPNode[VAR, DOMAIN]
-
-		for nodeHeap.Len() > 0 {
+		trail := newCSPTrail[VAR, DOMAIN]()
+		for nodeHeap.Len() > 0 && ctx.Err() == nil {
 			// Pop the node with the least priority (minimum remaining values)
-			current = heap.Pop(nodeHeap).(CSPNode[VAR, DOMAIN])
-			currentMap := current.GetMap()
-
-			// Check local consistency
-			if IsLocallyConsistent(current.Variable, currentMap, C.GetLocalConstraints(), C.GetGlobalConstraints()) {
-				// If all variables are assigned, check global consistency and yield solution
-				if len(currentMap) == len(C.GetVariables()) {
-					if IsConsistent(current.Variable, currentMap, C.GetLocalConstraints(), C.GetGlobalConstraints()) {
-						ch <- currentMap
-					}
-					continue
-				} else {
-					// Get the next variable to process
-					nextVariableIndex := goutils.IndexOf(C.GetVariables(), func(v VAR) bool {
-						_, assigned := current.GetMap()[v]
-						return !assigned
-					})
-					if nextVariableIndex == -1 {
-						continue
-					}
-
-					nextVariable := C.GetVariables()[nextVariableIndex]
-					nextDomain, ok := C.GetDomainMap()[nextVariable]
-					if !ok {
-						continue
-					}
-
-					// Reduce the domain and add subproblems to the heap
-					reduced := ReduceDomain(nextVariable, currentMap, nextDomain, C.GetLocalConstraints(), C.GetGlobalConstraints())
-					for _, domain := range reduced {
-						heap.Push(nodeHeap, *NewCSPNode(nextVariable, nextVariableIndex, domain, GetLegalValues(nextVariable, currentMap, C.GetDomainForVariable(nextVariable), C.localConstraints, C.globalConstraints), &current))
+			current := heap.Pop(nodeHeap).(CSPNode[VAR, DOMAIN])
+			currentMap := trail.moveTo(&current)
+			solution, children := C.expand(&current, currentMap)
+			children = C.prioritize(children, currentMap)
+			if solution != nil {
+				select {
+				case ch <- solution:
+					if C.CheckpointPath != "" {
+						C.solutions = append(C.solutions, solution)
 					}
+				case <-ctx.Done():
+					// The solution wasn't received, so its node stays open
+					heap.Push(nodeHeap, current)
 				}
 			}
+			for _, child := range children {
+				heap.Push(nodeHeap, child)
+			}
+			if C.CheckpointPath != "" && time.Since(lastCheckpoint) >= interval {
+				C.writeCheckpoint(*nodeHeap)
+				lastCheckpoint = time.Now()
+			}
 		}
+		C.writeCheckpoint(*nodeHeap)
 	}()
 
 	return ch
@@ -227,27 +302,21 @@ func (C *CSPAgent[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
 }
 
 func (C *CSPAgent[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	return C.FindOneSolutionContext(context.Background())
+}
 
-	solutionChannel := C.GenerateSolutionChannel()
+// FindOneSolutionContext returns nil when ctx is done before a solution is found.
+func (C *CSPAgent[VAR, DOMAIN]) FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	for {
-		select {
-		case <-ctx.Done(): // Stop processing if the context is canceled
-			return nil
-		case solution, ok := <-solutionChannel:
-			if !ok {
-				// Channel closed, no more solutions
-				return nil
-			}
-			if solution != nil {
-				// Cancel any ongoing solution generation
-				cancel()
-				return solution
-			}
+	// Canceling on return stops the search goroutine behind the channel
+	for solution := range C.GenerateSolutionChannelContext(ctx) {
+		if solution != nil {
+			return solution
 		}
 	}
+	return nil
 }
 
 func FindFirstSolutionFromAgents[VAR comparable, DOMAIN comparable](ctx context.Context, agents []CSPAgent[VAR, DOMAIN]) map[VAR]DOMAIN {
@@ -262,24 +331,13 @@ func FindFirstSolutionFromAgents[VAR comparable, DOMAIN comparable](ctx context.
 		wg.Add(1)
 		go func(a CSPAgent[VAR, DOMAIN]) {
 			defer wg.Done()
-			for {
+			// The search stops with ctx, so there is no need to drain the channel
+			for solution := range a.GenerateSolutionChannelContext(ctx) {
 				select {
+				case results <- solution:
 				case <-ctx.Done():
-					// Stop processing when context is canceled
-					return
-				case solution, ok := <-a.GenerateSolutionChannel():
-					if !ok {
-						return
-					}
-					if solution != nil {
-						select {
-						case results <- solution:
-							return
-						case <-ctx.Done():
-							return
-						}
-					}
 				}
+				return
 			}
 		}(agent)
 	}
@@ -0,0 +1,145 @@
+package gointel
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+)
+
+// CSPCheckpoint is the state of a CSPAgent's search between two expansions: the open nodes of its heap and the
+// solutions it has sent. Resuming it with the same model sends every other solution exactly once.
+type CSPCheckpoint[VAR comparable, DOMAIN comparable] struct {
+	Frontier  []CSPCheckpointNode[VAR, DOMAIN]
+	Solutions []map[VAR]DOMAIN
+}
+
+// CSPCheckpointNode is an open node as the assignments from the root down to it.
+type CSPCheckpointNode[VAR comparable, DOMAIN comparable] struct {
+	Path        []CSPCheckpointStep[VAR, DOMAIN]
+	LegalValues []DOMAIN
+}
+
+type CSPCheckpointStep[VAR comparable, DOMAIN comparable] struct {
+	Variable      VAR
+	VariableIndex int
+	Domain        DOMAIN
+}
+
+// IsDone tells whether the search had no open nodes left, so every solution is in Solutions.
+func (C *CSPCheckpoint[VAR, DOMAIN]) IsDone() bool {
+	return len(C.Frontier) == 0
+}
+
+func newCSPCheckpoint[VAR comparable, DOMAIN comparable](frontier []CSPNode[VAR, DOMAIN], solutions []map[VAR]DOMAIN) *CSPCheckpoint[VAR, DOMAIN] {
+	ret := &CSPCheckpoint[VAR, DOMAIN]{
+		Frontier:  make([]CSPCheckpointNode[VAR, DOMAIN], 0, len(frontier)),
+		Solutions: append([]map[VAR]DOMAIN{}, solutions...),
+	}
+	for _, node := range frontier {
+		path := make([]CSPCheckpointStep[VAR, DOMAIN], node.Depth)
+		for current := &node; current != nil; current = current.Parent {
+			path[current.Depth-1] = CSPCheckpointStep[VAR, DOMAIN]{
+				Variable:      current.Variable,
+				VariableIndex: current.VariableIndex,
+				Domain:        current.Domain,
+			}
+		}
+		ret.Frontier = append(ret.Frontier, CSPCheckpointNode[VAR, DOMAIN]{Path: path, LegalValues: node.LegalValues})
+	}
+	return ret
+}
+
+// nodes rebuilds the open nodes, sharing the parents of nodes with a common path.
+func (C *CSPCheckpoint[VAR, DOMAIN]) nodes() []CSPNode[VAR, DOMAIN] {
+	type key struct {
+		parent *CSPNode[VAR, DOMAIN]
+		step   CSPCheckpointStep[VAR, DOMAIN]
+	}
+	parents := map[key]*CSPNode[VAR, DOMAIN]{}
+	ret := make([]CSPNode[VAR, DOMAIN], 0, len(C.Frontier))
+	for _, open := range C.Frontier {
+		if len(open.Path) == 0 {
+			continue
+		}
+		var parent *CSPNode[VAR, DOMAIN]
+		for _, step := range open.Path[:len(open.Path)-1] {
+			k := key{parent, step}
+			node, ok := parents[k]
+			if !ok {
+				node = NewCSPNode(step.Variable, step.VariableIndex, step.Domain, nil, parent)
+				parents[k] = node
+			}
+			parent = node
+		}
+		last := open.Path[len(open.Path)-1]
+		ret = append(ret, *NewCSPNode(last.Variable, last.VariableIndex, last.Domain, open.LegalValues, parent))
+	}
+	return ret
+}
+
+// WriteCSPCheckpoint gob-encodes the checkpoint to a temporary file next to path and renames it over path, so a
+// crash leaves either the old or the new checkpoint. VAR and DOMAIN must be types gob can encode.
+func WriteCSPCheckpoint[VAR comparable, DOMAIN comparable](path string, checkpoint *CSPCheckpoint[VAR, DOMAIN]) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := gob.NewEncoder(file).Encode(checkpoint); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func ReadCSPCheckpoint[VAR comparable, DOMAIN comparable](path string) (*CSPCheckpoint[VAR, DOMAIN], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	ret := &CSPCheckpoint[VAR, DOMAIN]{}
+	if err := gob.NewDecoder(file).Decode(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Resume replaces the agent's stack with the checkpoint's open nodes, the next search only sends the solutions that
+// aren't in the checkpoint yet.
+func (C *CSPAgent[VAR, DOMAIN]) Resume(checkpoint *CSPCheckpoint[VAR, DOMAIN]) {
+	C.Stack = checkpoint.nodes()
+	C.solutions = append([]map[VAR]DOMAIN{}, checkpoint.Solutions...)
+}
+
+// ResumeFile resumes the checkpoint at path and keeps checkpointing to it.
+func (C *CSPAgent[VAR, DOMAIN]) ResumeFile(path string) error {
+	checkpoint, err := ReadCSPCheckpoint[VAR, DOMAIN](path)
+	if err != nil {
+		return err
+	}
+	C.Resume(checkpoint)
+	C.CheckpointPath = path
+	return nil
+}
+
+// CheckpointError returns the last error writing a checkpoint, once the solution channel is closed.
+func (C *CSPAgent[VAR, DOMAIN]) CheckpointError() error {
+	return C.checkpointErr
+}
+
+func (C *CSPAgent[VAR, DOMAIN]) writeCheckpoint(frontier []CSPNode[VAR, DOMAIN]) {
+	if C.CheckpointPath == "" {
+		return
+	}
+	if err := WriteCSPCheckpoint(C.CheckpointPath, newCSPCheckpoint(frontier, C.solutions)); err != nil {
+		C.checkpointErr = err
+	}
+}
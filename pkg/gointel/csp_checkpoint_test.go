@@ -0,0 +1,60 @@
+package gointel
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCSPAgent_Checkpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queens.checkpoint")
+	agent := newQueensAgent(8)
+	agent.CheckpointPath = path
+	agent.CheckpointInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := []map[int]int{}
+	for solution := range agent.GenerateSolutionChannelContext(ctx) {
+		received = append(received, solution)
+		if len(received) == 30 {
+			cancel()
+		}
+	}
+	cancel()
+	if err := agent.CheckpointError(); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := ReadCSPCheckpoint[int, int](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.IsDone() || len(checkpoint.Solutions) != len(received) {
+		t.Fatalf("expected an open checkpoint with %d solutions, got %d open nodes and %d solutions", len(received), len(checkpoint.Frontier), len(checkpoint.Solutions))
+	}
+
+	resumed := newQueensAgent(8)
+	if err := resumed.ResumeFile(path); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, solution := range append(received, resumed.FindAllSolutions()...) {
+		key := fmt.Sprint(solution)
+		if seen[key] {
+			t.Errorf("solution %v was sent twice", solution)
+		}
+		seen[key] = true
+	}
+	if len(seen) != 92 {
+		t.Errorf("expected 92 solutions, got %d", len(seen))
+	}
+
+	checkpoint, err = ReadCSPCheckpoint[int, int](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checkpoint.IsDone() || len(checkpoint.Solutions) != 92 {
+		t.Errorf("expected a finished checkpoint with 92 solutions, got %d open nodes and %d solutions", len(checkpoint.Frontier), len(checkpoint.Solutions))
+	}
+}
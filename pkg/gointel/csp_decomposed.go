@@ -0,0 +1,290 @@
+package gointel
+
+import (
+	"context"
+	"github.com/mtresnik/goutils/pkg/goutils"
+	"math"
+	"sync"
+)
+
+// DecomposedCSP splits the constraint graph into connected components, solves each component on its own
+// and combines the partial solutions, so independent variables are not multiplied into every branch.
+type DecomposedCSP[VAR comparable, DOMAIN comparable] struct {
+	DomainMap     map[VAR][]DOMAIN
+	Preprocessors []CSPPreprocessor[VAR, DOMAIN]
+	// SubproblemFactory builds the solver for each component, NewCSPDomain when nil.
+	SubproblemFactory func(domainMap map[VAR][]DOMAIN) CSP[VAR, DOMAIN]
+	cspConstraints[VAR, DOMAIN]
+	variables *[]VAR
+}
+
+type cspComponent[VAR comparable, DOMAIN comparable] struct {
+	Variables []VAR
+	csp       CSP[VAR, DOMAIN]
+}
+
+func NewDecomposedCSP[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *DecomposedCSP[VAR, DOMAIN] {
+	return &DecomposedCSP[VAR, DOMAIN]{
+		DomainMap:      domainMap,
+		Preprocessors:  preprocessors,
+		cspConstraints: newCSPConstraints[VAR, DOMAIN](),
+	}
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) GetDomainMap() map[VAR][]DOMAIN {
+	return C.DomainMap
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) SetDomainMap(m map[VAR][]DOMAIN) {
+	C.DomainMap = m
+	C.variables = nil
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) GetVariables() []VAR {
+	if C.variables == nil {
+		variables := goutils.Keys(C.DomainMap)
+		C.variables = &variables
+	}
+	return *C.variables
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) GetDomainForVariable(variable VAR) []DOMAIN {
+	ret, ok := C.DomainMap[variable]
+	if !ok {
+		return []DOMAIN{}
+	}
+	return ret
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) Contains(v VAR) bool {
+	_, ok := C.DomainMap[v]
+	return ok
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) Preprocess() {
+	var csp CSP[VAR, DOMAIN] = C
+	for _, preprocessor := range C.Preprocessors {
+		preprocessor.Preprocess(&csp)
+	}
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
+	return C.add(constraint, C.Contains)
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
+}
+
+// components returns the independent subproblems. Variables that share no constraints at all come back
+// as single-variable components without a solver, since every value of their domain is a solution.
+func (C *DecomposedCSP[VAR, DOMAIN]) components() []cspComponent[VAR, DOMAIN] {
+	factory := C.SubproblemFactory
+	if factory == nil {
+		factory = func(domainMap map[VAR][]DOMAIN) CSP[VAR, DOMAIN] {
+			return NewCSPDomain(domainMap)
+		}
+	}
+	ret := []cspComponent[VAR, DOMAIN]{}
+	for _, variables := range NewConstraintGraph[VAR, DOMAIN](C).ConnectedComponents() {
+		if len(variables) == 1 && len(C.localConstraints[variables[0]]) == 0 && len(C.globalConstraints) == 0 {
+			ret = append(ret, cspComponent[VAR, DOMAIN]{Variables: variables})
+			continue
+		}
+		domainMap := map[VAR][]DOMAIN{}
+		for _, variable := range variables {
+			domainMap[variable] = append([]DOMAIN{}, C.DomainMap[variable]...)
+		}
+		csp := factory(domainMap)
+		copyConstraints[VAR, DOMAIN](C, csp)
+		ret = append(ret, cspComponent[VAR, DOMAIN]{Variables: variables, csp: csp})
+	}
+	return ret
+}
+
+// findAllSolutions returns the solutions found before ctx is done, all of them when the solver can't be cancelled.
+func (c cspComponent[VAR, DOMAIN]) findAllSolutions(ctx context.Context, domainMap map[VAR][]DOMAIN) []map[VAR]DOMAIN {
+	if cancellable, ok := c.csp.(interface {
+		FindAllSolutionsContext(ctx context.Context) []map[VAR]DOMAIN
+	}); ok {
+		return cancellable.FindAllSolutionsContext(ctx)
+	}
+	if c.csp != nil {
+		return c.csp.FindAllSolutions()
+	}
+	ret := []map[VAR]DOMAIN{}
+	for _, value := range domainMap[c.Variables[0]] {
+		ret = append(ret, map[VAR]DOMAIN{c.Variables[0]: value})
+	}
+	return ret
+}
+
+func (c cspComponent[VAR, DOMAIN]) findOneSolution(domainMap map[VAR][]DOMAIN) map[VAR]DOMAIN {
+	if c.csp != nil {
+		return c.csp.FindOneSolution()
+	}
+	domain := domainMap[c.Variables[0]]
+	if len(domain) == 0 {
+		return nil
+	}
+	return map[VAR]DOMAIN{c.Variables[0]: domain[0]}
+}
+
+// countSolutions counts the component's solutions up to limit, or all of them when limit is 0 or less.
+func (c cspComponent[VAR, DOMAIN]) countSolutions(domainMap map[VAR][]DOMAIN, limit int) int {
+	if c.csp != nil {
+		return CountSolutions(c.csp, limit)
+	}
+	count := len(domainMap[c.Variables[0]])
+	if limit > 0 {
+		return min(count, limit)
+	}
+	return count
+}
+
+// solveComponents enumerates every component in parallel, returning nil when any of them has no solution or ctx is
+// done first.
+func (C *DecomposedCSP[VAR, DOMAIN]) solveComponents(ctx context.Context) [][]map[VAR]DOMAIN {
+	C.Preprocess()
+	components := C.components()
+	results := make([][]map[VAR]DOMAIN, len(components))
+	var wg sync.WaitGroup
+	for index, component := range components {
+		wg.Add(1)
+		go func(index int, component cspComponent[VAR, DOMAIN]) {
+			defer wg.Done()
+			results[index] = component.findAllSolutions(ctx, C.DomainMap)
+		}(index, component)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	for _, result := range results {
+		if len(result) == 0 {
+			return nil
+		}
+	}
+	return results
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	C.Preprocess()
+	components := C.components()
+	results := make([]map[VAR]DOMAIN, len(components))
+	var wg sync.WaitGroup
+	for index, component := range components {
+		wg.Add(1)
+		go func(index int, component cspComponent[VAR, DOMAIN]) {
+			defer wg.Done()
+			results[index] = component.findOneSolution(C.DomainMap)
+		}(index, component)
+	}
+	wg.Wait()
+	ret := map[VAR]DOMAIN{}
+	for _, result := range results {
+		if result == nil {
+			return nil
+		}
+		for variable, value := range result {
+			ret[variable] = value
+		}
+	}
+	return ret
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
+	collected := []map[VAR]DOMAIN{}
+	for solution := range C.GenerateSolutionChannel() {
+		collected = append(collected, solution)
+	}
+	return collected
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) GenerateSolutionChannel() chan map[VAR]DOMAIN {
+	return C.GenerateSolutionChannelContext(context.Background())
+}
+
+// GenerateSolutionChannelContext solves the components and then lazily yields their cartesian product. The channel
+// is closed once the product is exhausted or ctx is done.
+func (C *DecomposedCSP[VAR, DOMAIN]) GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN {
+	ch := make(chan map[VAR]DOMAIN)
+	go func() {
+		defer close(ch)
+		parts := C.solveComponents(ctx)
+		if len(parts) == 0 {
+			return
+		}
+		indices := make([]int, len(parts))
+		for {
+			solution := map[VAR]DOMAIN{}
+			for part, index := range indices {
+				for variable, value := range parts[part][index] {
+					solution[variable] = value
+				}
+			}
+			select {
+			case ch <- solution:
+			case <-ctx.Done():
+				return
+			}
+
+			// Advance the indices like an odometer
+			part := len(indices) - 1
+			for ; part >= 0; part-- {
+				indices[part]++
+				if indices[part] < len(parts[part]) {
+					break
+				}
+				indices[part] = 0
+			}
+			if part < 0 {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// CountSolutions returns the number of solutions up to limit, or all of them when limit is 0 or less, as the product
+// of the component counts. Each component is counted up to limit too, since every other factor is at least one.
+func (C *DecomposedCSP[VAR, DOMAIN]) CountSolutions(limit int) int {
+	C.Preprocess()
+	components := C.components()
+	counts := make([]int, len(components))
+	var wg sync.WaitGroup
+	for index, component := range components {
+		wg.Add(1)
+		go func(index int, component cspComponent[VAR, DOMAIN]) {
+			defer wg.Done()
+			counts[index] = component.countSolutions(C.DomainMap, limit)
+		}(index, component)
+	}
+	wg.Wait()
+	if len(counts) == 0 {
+		return 0
+	}
+	count := 1
+	for _, factor := range counts {
+		if factor == 0 {
+			return 0
+		}
+		if count > math.MaxInt/factor {
+			count = math.MaxInt
+		} else {
+			count *= factor
+		}
+	}
+	if limit > 0 {
+		return min(count, limit)
+	}
+	return count
+}
+
+func (C *DecomposedCSP[VAR, DOMAIN]) GetSeeds() *map[VAR]DOMAIN {
+	return nil
+}
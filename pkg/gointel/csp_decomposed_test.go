@@ -0,0 +1,72 @@
+package gointel
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDecomposedCSP_Australia(t *testing.T) {
+	wa := "Western Australia"
+	nt := "Northern Territory"
+	sa := "South Australia"
+	q := "Queensland"
+	nsw := "New South Wales"
+	v := "Victoria"
+	tas := "Tasmania"
+
+	domains := map[string][]string{}
+	for _, variable := range []string{wa, nt, sa, q, nsw, v, tas} {
+		domains[variable] = []string{"red", "green", "blue"}
+	}
+	csp := NewDecomposedCSP(domains)
+	constraints := []Constraint[string, string]{
+		&mapColoringConstraint{From: wa, To: nt},
+		&mapColoringConstraint{From: wa, To: sa},
+		&mapColoringConstraint{From: sa, To: nt},
+		&mapColoringConstraint{From: q, To: nt},
+		&mapColoringConstraint{From: q, To: sa},
+		&mapColoringConstraint{From: q, To: nsw},
+		&mapColoringConstraint{From: nsw, To: sa},
+		&mapColoringConstraint{From: v, To: sa},
+		&mapColoringConstraint{From: v, To: nsw},
+	}
+	for _, constraint := range constraints {
+		csp.AddConstraint(&constraint)
+	}
+
+	components := NewConstraintGraph[string, string](csp).ConnectedComponents()
+	if len(components) != 2 {
+		t.Fatalf("expected mainland and Tasmania as separate components, got %v", components)
+	}
+	if count := csp.CountSolutions(0); count != 18 {
+		t.Errorf("expected 18 solutions, got %d", count)
+	}
+	if count := CountSolutions[string, string](csp, 5); count != 5 {
+		t.Errorf("expected the count to stop at 5, got %d", count)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := csp.GenerateSolutionChannelContext(ctx)
+	<-ch
+	cancel()
+	for range ch {
+		// Drains the solution that may already be on its way, then the channel closes
+	}
+	solutions := csp.FindAllSolutions()
+	if len(solutions) != 18 {
+		t.Fatalf("expected 18 solutions, got %d", len(solutions))
+	}
+	seen := map[string]bool{}
+	for _, solution := range solutions {
+		if len(solution) != 7 {
+			t.Errorf("expected a complete assignment, got %v", solution)
+		}
+		seen[assignmentKey(solution)] = true
+	}
+	if len(seen) != 18 {
+		t.Errorf("expected 18 distinct solutions, got %d", len(seen))
+	}
+	one := csp.FindOneSolution()
+	if len(one) != 7 {
+		t.Errorf("expected a complete assignment, got %v", one)
+	}
+}
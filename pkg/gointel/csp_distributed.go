@@ -0,0 +1,687 @@
+package gointel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mtresnik/goutils/pkg/goutils"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type DistributedAlgorithm int
+
+const (
+	// DISTRIBUTED_ABT is asynchronous backtracking: agents keep a fixed priority order, each one picks a value
+	// consistent with the higher agents it knows of and sends a nogood to the lowest agent of the conflict when there
+	// is none.
+	DISTRIBUTED_ABT DistributedAlgorithm = iota
+	// DISTRIBUTED_AWC is asynchronous weak-commitment: an agent without a consistent value sends the nogood to every
+	// agent in it and raises its priority above its neighbors', so bad early choices are revised instead of searched
+	// exhaustively.
+	DISTRIBUTED_AWC
+)
+
+// DistributedCSP splits the variables among agents that each assign their own and agree on a solution only through
+// messages, sent over Transport. The values of an agent are the assignments of its variables satisfying the
+// constraints among them, enumerated up front, so agents should own few variables. Global constraints are checked by
+// the agents once they know the whole assignment, which links every agent to every other. The search stops when no
+// message is left to deliver, which the CSP counts in process, so the agents of a run share one process even over
+// TCP. The algorithms find a single solution, FindAllSolutions returns at most one.
+type DistributedCSP[VAR comparable, DOMAIN comparable] struct {
+	DomainMap     map[VAR][]DOMAIN
+	Preprocessors []CSPPreprocessor[VAR, DOMAIN]
+	// Owners assigns variables to agents, ordered by owner, variables without one get an agent of their own.
+	Owners    map[VAR]int
+	Algorithm DistributedAlgorithm
+	// Transport delivers the messages, a ChannelTransport when nil.
+	Transport DistributedTransport[VAR, DOMAIN]
+	cspConstraints[VAR, DOMAIN]
+	variables *[]VAR
+}
+
+func NewDistributedCSP[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, owners map[VAR]int, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *DistributedCSP[VAR, DOMAIN] {
+	return &DistributedCSP[VAR, DOMAIN]{
+		DomainMap:      domainMap,
+		Preprocessors:  preprocessors,
+		Owners:         owners,
+		cspConstraints: newCSPConstraints[VAR, DOMAIN](),
+	}
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) GetDomainMap() map[VAR][]DOMAIN {
+	return C.DomainMap
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) SetDomainMap(m map[VAR][]DOMAIN) {
+	C.DomainMap = m
+	C.variables = nil
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) GetVariables() []VAR {
+	if C.variables == nil {
+		variables := goutils.Keys(C.DomainMap)
+		C.variables = &variables
+	}
+	return *C.variables
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) GetDomainForVariable(variable VAR) []DOMAIN {
+	ret, ok := C.DomainMap[variable]
+	if !ok {
+		return []DOMAIN{}
+	}
+	return ret
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) Contains(v VAR) bool {
+	_, ok := C.DomainMap[v]
+	return ok
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) Preprocess() {
+	var csp CSP[VAR, DOMAIN] = C
+	for _, preprocessor := range C.Preprocessors {
+		preprocessor.Preprocess(&csp)
+	}
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
+	return C.add(constraint, C.Contains)
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	return C.FindOneSolutionContext(context.Background())
+}
+
+// FindOneSolutionContext returns nil when there is no solution, ctx is done or the transport fails, Solve tells
+// these apart.
+func (C *DistributedCSP[VAR, DOMAIN]) FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN {
+	solution, _ := C.Solve(ctx)
+	return solution
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
+	solution := C.FindOneSolution()
+	if solution == nil {
+		return []map[VAR]DOMAIN{}
+	}
+	return []map[VAR]DOMAIN{solution}
+}
+
+func (C *DistributedCSP[VAR, DOMAIN]) GetSeeds() *map[VAR]DOMAIN {
+	return nil
+}
+
+// Solve runs the agents until they agree on a solution. It returns ErrInconsistent when an agent derives the empty
+// nogood, ctx's error when it is done first and the transport's errors.
+func (C *DistributedCSP[VAR, DOMAIN]) Solve(ctx context.Context) (map[VAR]DOMAIN, error) {
+	C.Preprocess()
+	agents := C.newAgents()
+	transport := C.Transport
+	if transport == nil {
+		transport = NewChannelTransport[VAR, DOMAIN]()
+	}
+	incoming, err := transport.Open(len(agents))
+	if err != nil {
+		return nil, err
+	}
+	defer transport.Close()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	run := &distributedRun[VAR, DOMAIN]{
+		transport: transport,
+		cancel:    cancel,
+		quiet:     make(chan struct{}),
+	}
+	// Every agent holds a token until it has started, so the run can't look quiet before then
+	run.pending.Store(int64(len(agents)))
+	wg := sync.WaitGroup{}
+	for i, agent := range agents {
+		agent.run = run
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			agent.start()
+			run.processed()
+			for {
+				select {
+				case <-runCtx.Done():
+					return
+				case message := <-incoming[i]:
+					agent.receive(message)
+					run.processed()
+				}
+			}
+		}()
+	}
+	select {
+	case <-run.quiet:
+	case <-runCtx.Done():
+	}
+	cancel()
+	wg.Wait()
+
+	if err := run.failure(); err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	solution := map[VAR]DOMAIN{}
+	for _, agent := range agents {
+		if agent.current < 0 {
+			return nil, errors.New("distributed search ended with an unassigned agent")
+		}
+		for variable, value := range agent.values[agent.current] {
+			solution[variable] = value
+		}
+	}
+	for _, constraint := range uniqueLocalConstraints(C.GetVariables(), C.localConstraints) {
+		if !(*constraint).IsSatisfied(solution) {
+			return nil, errors.New("distributed search ended on a violated constraint")
+		}
+	}
+	for _, constraint := range C.globalConstraints {
+		if !(*constraint).IsSatisfied(solution) {
+			return nil, errors.New("distributed search ended on a violated constraint")
+		}
+	}
+	return solution, nil
+}
+
+// newAgents gives each agent its variables, values, constraints and neighbors.
+func (C *DistributedCSP[VAR, DOMAIN]) newAgents() []*distributedAgent[VAR, DOMAIN] {
+	variables := C.GetVariables()
+	owners := goutils.Unique(goutils.Values(C.Owners))
+	sort.Ints(owners)
+	indices := map[int]int{}
+	for i, owner := range owners {
+		indices[owner] = i
+	}
+	owner := map[VAR]int{}
+	owned := make([][]VAR, len(owners))
+	for _, variable := range variables {
+		if index, ok := C.Owners[variable]; ok {
+			index = indices[index]
+			owner[variable] = index
+			owned[index] = append(owned[index], variable)
+			continue
+		}
+		owner[variable] = len(owned)
+		owned = append(owned, []VAR{variable})
+	}
+
+	agents := make([]*distributedAgent[VAR, DOMAIN], len(owned))
+	for i := range agents {
+		agents[i] = &distributedAgent[VAR, DOMAIN]{
+			id:         i,
+			algorithm:  C.Algorithm,
+			owner:      owner,
+			variables:  len(variables),
+			globals:    C.globalConstraints,
+			neighbors:  map[int]bool{},
+			links:      map[int]bool{},
+			view:       map[int]map[VAR]DOMAIN{},
+			priorities: map[int]int{},
+			current:    -1,
+			sent:       map[string]bool{},
+		}
+	}
+	internal := make([][]*LocalConstraint[VAR, DOMAIN], len(agents))
+	for _, constraint := range uniqueLocalConstraints(variables, C.localConstraints) {
+		scope := []int{}
+		for _, variable := range (*constraint).GetVariables() {
+			if index, ok := owner[variable]; ok && !goutils.Contains(scope, func(other int) bool { return other == index }) {
+				scope = append(scope, index)
+			}
+		}
+		if len(scope) == 1 {
+			internal[scope[0]] = append(internal[scope[0]], constraint)
+			continue
+		}
+		for _, index := range scope {
+			agents[index].constraints = append(agents[index].constraints, constraint)
+			for _, other := range scope {
+				if other != index {
+					agents[index].neighbors[other] = true
+				}
+			}
+		}
+	}
+	for _, agent := range agents {
+		if len(C.globalConstraints) > 0 {
+			for other := range agents {
+				if other != agent.id {
+					agent.neighbors[other] = true
+				}
+			}
+		}
+		for other := range agent.neighbors {
+			if other > agent.id {
+				agent.links[other] = true
+			}
+		}
+		agent.values = C.localValues(owned[agent.id], internal[agent.id])
+	}
+	return agents
+}
+
+// localValues enumerates the assignments of the variables that satisfy the constraints among them.
+func (C *DistributedCSP[VAR, DOMAIN]) localValues(variables []VAR, constraints []*LocalConstraint[VAR, DOMAIN]) []map[VAR]DOMAIN {
+	ret := []map[VAR]DOMAIN{}
+	assignment := map[VAR]DOMAIN{}
+	var assign func(index int)
+	assign = func(index int) {
+		if index == len(variables) {
+			ret = append(ret, copyAssignment(assignment))
+			return
+		}
+		variable := variables[index]
+		for _, value := range C.DomainMap[variable] {
+			assignment[variable] = value
+			consistent := true
+			for _, constraint := range constraints {
+				if !(*constraint).IsPossiblySatisfied(assignment) {
+					consistent = false
+					break
+				}
+			}
+			if consistent {
+				assign(index + 1)
+			}
+		}
+		delete(assignment, variable)
+	}
+	assign(0)
+	return ret
+}
+
+func copyAssignment[VAR comparable, DOMAIN comparable](assignment map[VAR]DOMAIN) map[VAR]DOMAIN {
+	ret := make(map[VAR]DOMAIN, len(assignment))
+	for variable, value := range assignment {
+		ret[variable] = value
+	}
+	return ret
+}
+
+// distributedRun counts the messages sent but not yet processed, the run is over when none are left.
+type distributedRun[VAR comparable, DOMAIN comparable] struct {
+	transport DistributedTransport[VAR, DOMAIN]
+	cancel    context.CancelFunc
+	pending   atomic.Int64
+	quiet     chan struct{}
+	quietOnce sync.Once
+	err       error
+	errOnce   sync.Once
+	errMutex  sync.Mutex
+}
+
+func (R *distributedRun[VAR, DOMAIN]) send(message DistributedMessage[VAR, DOMAIN]) {
+	R.pending.Add(1)
+	if err := R.transport.Send(message); err != nil {
+		R.fail(err)
+	}
+}
+
+func (R *distributedRun[VAR, DOMAIN]) processed() {
+	if R.pending.Add(-1) == 0 {
+		R.quietOnce.Do(func() {
+			close(R.quiet)
+		})
+	}
+}
+
+func (R *distributedRun[VAR, DOMAIN]) fail(err error) {
+	R.errOnce.Do(func() {
+		R.errMutex.Lock()
+		R.err = err
+		R.errMutex.Unlock()
+		R.cancel()
+	})
+}
+
+func (R *distributedRun[VAR, DOMAIN]) failure() error {
+	R.errMutex.Lock()
+	defer R.errMutex.Unlock()
+	return R.err
+}
+
+type distributedAgent[VAR comparable, DOMAIN comparable] struct {
+	id          int
+	algorithm   DistributedAlgorithm
+	run         *distributedRun[VAR, DOMAIN]
+	owner       map[VAR]int
+	variables   int
+	values      []map[VAR]DOMAIN
+	constraints []*LocalConstraint[VAR, DOMAIN]
+	globals     []*GlobalConstraint[VAR, DOMAIN]
+	// neighbors share constraints or nogoods with the agent, links are the ones that get its ok? messages under ABT
+	neighbors  map[int]bool
+	links      map[int]bool
+	view       map[int]map[VAR]DOMAIN
+	priorities map[int]int
+	priority   int
+	current    int
+	nogoods    []map[VAR]DOMAIN
+	sent       map[string]bool
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) start() {
+	if len(A.values) == 0 {
+		A.run.fail(fmt.Errorf("%w: agent %d has no consistent assignment", ErrInconsistent, A.id))
+		return
+	}
+	A.check()
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) receive(message DistributedMessage[VAR, DOMAIN]) {
+	switch message.Kind {
+	case DISTRIBUTED_OK:
+		A.view[message.From] = message.Assignment
+		A.priorities[message.From] = message.Priority
+		A.check()
+	case DISTRIBUTED_NOGOOD:
+		A.receiveNogood(message)
+	case DISTRIBUTED_ADD_LINK:
+		A.links[message.From] = true
+		A.neighbors[message.From] = true
+		A.sendOk(message.From)
+	}
+}
+
+// receiveNogood asks the agents of the nogood it hasn't heard from for their values, and meanwhile assumes the ones
+// in the nogood.
+func (A *distributedAgent[VAR, DOMAIN]) receiveNogood(message DistributedMessage[VAR, DOMAIN]) {
+	nogood := message.Assignment
+	for variable := range nogood {
+		other := A.owner[variable]
+		if other == A.id {
+			continue
+		}
+		if !A.neighbors[other] {
+			A.neighbors[other] = true
+			A.run.send(DistributedMessage[VAR, DOMAIN]{Kind: DISTRIBUTED_ADD_LINK, From: A.id, To: other})
+		}
+		if _, ok := A.view[other]; !ok {
+			A.view[other] = A.part(nogood, other)
+		}
+	}
+	if A.algorithm == DISTRIBUTED_AWC {
+		A.nogoods = append(A.nogoods, nogood)
+		A.check()
+		return
+	}
+	old := A.current
+	if A.coherent(nogood) {
+		A.nogoods = append(A.nogoods, nogood)
+		A.check()
+	}
+	if A.current == old {
+		// The sender may be waiting on a value that didn't change
+		A.sendOk(message.From)
+	}
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) check() {
+	if A.algorithm == DISTRIBUTED_AWC {
+		A.checkWeakCommitment()
+	} else {
+		A.checkBacktracking()
+	}
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) checkBacktracking() {
+	for {
+		if A.current >= 0 && A.conflict(A.current, A.isHigher) == nil {
+			return
+		}
+		for i := range A.values {
+			if A.conflict(i, A.isHigher) == nil {
+				A.current = i
+				for other := range A.links {
+					A.sendOk(other)
+				}
+				return
+			}
+		}
+		nogood := A.resolve()
+		if len(nogood) == 0 {
+			A.run.fail(fmt.Errorf("%w: agent %d derived the empty nogood", ErrInconsistent, A.id))
+			return
+		}
+		target := -1
+		for variable := range nogood {
+			target = max(target, A.owner[variable])
+		}
+		A.run.send(DistributedMessage[VAR, DOMAIN]{Kind: DISTRIBUTED_NOGOOD, From: A.id, To: target, Assignment: nogood})
+		delete(A.view, target)
+	}
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) checkWeakCommitment() {
+	if A.current >= 0 && A.conflict(A.current, A.isHigher) == nil {
+		return
+	}
+	best, bestCount := -1, 0
+	for i := range A.values {
+		if A.conflict(i, A.isHigher) != nil {
+			continue
+		}
+		if count := A.countConflicts(i, A.isLower); best == -1 || count < bestCount {
+			best, bestCount = i, count
+		}
+	}
+	if best >= 0 {
+		A.current = best
+		A.broadcast()
+		return
+	}
+	nogood := A.resolve()
+	if len(nogood) == 0 {
+		A.run.fail(fmt.Errorf("%w: agent %d derived the empty nogood", ErrInconsistent, A.id))
+		return
+	}
+	key := assignmentKey(nogood)
+	if A.sent[key] {
+		return
+	}
+	A.sent[key] = true
+	targets := map[int]bool{}
+	for variable := range nogood {
+		targets[A.owner[variable]] = true
+	}
+	for target := range targets {
+		A.run.send(DistributedMessage[VAR, DOMAIN]{Kind: DISTRIBUTED_NOGOOD, From: A.id, To: target, Assignment: nogood})
+	}
+	for other := range A.view {
+		A.priority = max(A.priority, A.priorities[other]+1)
+	}
+	best, bestCount = 0, -1
+	for i := range A.values {
+		if count := A.countConflicts(i, A.inView); bestCount == -1 || count < bestCount {
+			best, bestCount = i, count
+		}
+	}
+	A.current = best
+	A.broadcast()
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) sendOk(to int) {
+	if A.current < 0 {
+		return
+	}
+	A.run.send(DistributedMessage[VAR, DOMAIN]{
+		Kind:       DISTRIBUTED_OK,
+		From:       A.id,
+		To:         to,
+		Assignment: A.values[A.current],
+		Priority:   A.priority,
+	})
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) broadcast() {
+	for other := range A.neighbors {
+		A.sendOk(other)
+	}
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) isHigher(other int) bool {
+	if _, ok := A.view[other]; !ok {
+		return false
+	}
+	if A.algorithm == DISTRIBUTED_AWC && A.priorities[other] != A.priority {
+		return A.priorities[other] > A.priority
+	}
+	return other < A.id
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) isLower(other int) bool {
+	return A.inView(other) && !A.isHigher(other)
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) inView(other int) bool {
+	_, ok := A.view[other]
+	return ok
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) assignment(value int, considered func(int) bool) map[VAR]DOMAIN {
+	ret := copyAssignment(A.values[value])
+	for other, assignment := range A.view {
+		if considered(other) {
+			for variable, domain := range assignment {
+				ret[variable] = domain
+			}
+		}
+	}
+	return ret
+}
+
+// conflict returns the assignments of the considered agents that rule out the value, nil when none do.
+func (A *distributedAgent[VAR, DOMAIN]) conflict(value int, considered func(int) bool) map[VAR]DOMAIN {
+	assignment := A.assignment(value, considered)
+	for _, constraint := range A.constraints {
+		if !(*constraint).IsPossiblySatisfied(assignment) {
+			return A.others(assignment, (*constraint).GetVariables())
+		}
+	}
+	if len(assignment) == A.variables {
+		for _, constraint := range A.globals {
+			if !(*constraint).IsSatisfied(assignment) {
+				return A.others(assignment, goutils.Keys(assignment))
+			}
+		}
+	}
+	for _, nogood := range A.nogoods {
+		if A.violates(nogood, value, considered) {
+			return A.others(nogood, goutils.Keys(nogood))
+		}
+	}
+	return nil
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) countConflicts(value int, considered func(int) bool) int {
+	assignment := A.assignment(value, considered)
+	count := 0
+	for _, constraint := range A.constraints {
+		if !(*constraint).IsPossiblySatisfied(assignment) {
+			count++
+		}
+	}
+	if len(assignment) == A.variables {
+		for _, constraint := range A.globals {
+			if !(*constraint).IsSatisfied(assignment) {
+				count++
+			}
+		}
+	}
+	for _, nogood := range A.nogoods {
+		if A.violates(nogood, value, considered) {
+			count++
+		}
+	}
+	return count
+}
+
+// resolve joins the conflicts of every value into the nogood the agent sends when it has no value left.
+func (A *distributedAgent[VAR, DOMAIN]) resolve() map[VAR]DOMAIN {
+	ret := map[VAR]DOMAIN{}
+	for i := range A.values {
+		for variable, value := range A.conflict(i, A.isHigher) {
+			ret[variable] = value
+		}
+	}
+	return ret
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) violates(nogood map[VAR]DOMAIN, value int, considered func(int) bool) bool {
+	for variable, domain := range nogood {
+		other := A.owner[variable]
+		if other == A.id {
+			if A.values[value][variable] != domain {
+				return false
+			}
+			continue
+		}
+		if !considered(other) {
+			return false
+		}
+		if assigned, ok := A.view[other][variable]; !ok || assigned != domain {
+			return false
+		}
+	}
+	return true
+}
+
+// coherent tells whether the nogood agrees with the agent's value and view, older ones are obsolete under ABT.
+func (A *distributedAgent[VAR, DOMAIN]) coherent(nogood map[VAR]DOMAIN) bool {
+	if A.current < 0 {
+		return false
+	}
+	for variable, domain := range nogood {
+		other := A.owner[variable]
+		if other == A.id {
+			if A.values[A.current][variable] != domain {
+				return false
+			}
+			continue
+		}
+		if assigned, ok := A.view[other][variable]; ok && assigned != domain {
+			return false
+		}
+	}
+	return true
+}
+
+// others keeps the assigned variables of the scope that belong to other agents.
+func (A *distributedAgent[VAR, DOMAIN]) others(assignment map[VAR]DOMAIN, scope []VAR) map[VAR]DOMAIN {
+	ret := map[VAR]DOMAIN{}
+	for _, variable := range scope {
+		if value, ok := assignment[variable]; ok {
+			if owner, ok := A.owner[variable]; ok && owner != A.id {
+				ret[variable] = value
+			}
+		}
+	}
+	return ret
+}
+
+func (A *distributedAgent[VAR, DOMAIN]) part(assignment map[VAR]DOMAIN, agent int) map[VAR]DOMAIN {
+	ret := map[VAR]DOMAIN{}
+	for variable, value := range assignment {
+		if A.owner[variable] == agent {
+			ret[variable] = value
+		}
+	}
+	return ret
+}
@@ -0,0 +1,63 @@
+package gointel
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDistributedCSP_NQueens(t *testing.T) {
+	n := 8
+	pairs := map[int]int{}
+	for col := 0; col < n; col++ {
+		pairs[col] = col / 2
+	}
+	for _, algorithm := range []DistributedAlgorithm{DISTRIBUTED_ABT, DISTRIBUTED_AWC} {
+		for name, transport := range map[string]DistributedTransport[int, int]{
+			"channel": NewChannelTransport[int, int](),
+			"tcp":     NewTCPTransport[int, int](),
+		} {
+			for _, owners := range []map[int]int{nil, pairs} {
+				csp := newDistributedQueens(n, owners)
+				csp.Algorithm = algorithm
+				csp.Transport = transport
+				solution, err := csp.Solve(context.Background())
+				if err != nil {
+					t.Fatalf("algorithm %d over %s: %v", algorithm, name, err)
+				}
+				if len(solution) != n {
+					t.Fatalf("algorithm %d over %s: expected a complete assignment, got %v", algorithm, name, solution)
+				}
+				for a := 0; a < n; a++ {
+					for b := a + 1; b < n; b++ {
+						constraint := queensConstraint{A: a, B: b}
+						if !constraint.IsSatisfied(solution) {
+							t.Errorf("algorithm %d over %s: queens %d and %d attack each other in %v", algorithm, name, a, b, solution)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDistributedCSP_Inconsistent(t *testing.T) {
+	for _, algorithm := range []DistributedAlgorithm{DISTRIBUTED_ABT, DISTRIBUTED_AWC} {
+		// Three queens can't share a 3×3 board
+		csp := newDistributedQueens(3, nil)
+		csp.Algorithm = algorithm
+		if solution, err := csp.Solve(context.Background()); !errors.Is(err, ErrInconsistent) {
+			t.Errorf("algorithm %d: expected ErrInconsistent, got %v and %v", algorithm, solution, err)
+		}
+
+		domainMap := map[string][]int{"a": {0, 1}, "b": {0, 1}, "c": {0, 1}}
+		global := NewDistributedCSP(domainMap, nil)
+		global.Algorithm = algorithm
+		global.Transport = NewTCPTransport[string, int]()
+		var different Constraint[string, int] = &GlobalAllDifferentConstraint[string, int]{}
+		global.AddConstraint(&different)
+		if solution, err := global.Solve(context.Background()); !errors.Is(err, ErrInconsistent) {
+			t.Errorf("algorithm %d: expected ErrInconsistent for three different values out of two, got %v and %v", algorithm, solution, err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package gointel
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+type DistributedMessageKind int
+
+const (
+	// DISTRIBUTED_OK announces the sender's assignment of its variables.
+	DISTRIBUTED_OK DistributedMessageKind = iota
+	// DISTRIBUTED_NOGOOD tells the receiver that the assignments in the message can't all hold.
+	DISTRIBUTED_NOGOOD
+	// DISTRIBUTED_ADD_LINK asks the receiver to send its assignments to the sender from now on.
+	DISTRIBUTED_ADD_LINK
+)
+
+// DistributedMessage is what the agents of a DistributedCSP exchange. Assignment is the sender's assignment in
+// ok? messages and the nogood in nogood messages. Priority is only used by asynchronous weak-commitment.
+type DistributedMessage[VAR comparable, DOMAIN comparable] struct {
+	Kind       DistributedMessageKind
+	From       int
+	To         int
+	Assignment map[VAR]DOMAIN
+	Priority   int
+}
+
+// DistributedTransport delivers messages between the agents 0..n-1 of a DistributedCSP. Messages from one agent to
+// another have to arrive in the order they were sent.
+type DistributedTransport[VAR comparable, DOMAIN comparable] interface {
+	// Open prepares delivery for n agents and returns the incoming messages of each.
+	Open(n int) ([]<-chan DistributedMessage[VAR, DOMAIN], error)
+	Send(message DistributedMessage[VAR, DOMAIN]) error
+	Close() error
+}
+
+// distributedMailbox queues any number of messages, so that senders never wait for a busy receiver.
+type distributedMailbox[VAR comparable, DOMAIN comparable] struct {
+	in   chan DistributedMessage[VAR, DOMAIN]
+	out  chan DistributedMessage[VAR, DOMAIN]
+	done chan struct{}
+}
+
+func newDistributedMailbox[VAR comparable, DOMAIN comparable]() *distributedMailbox[VAR, DOMAIN] {
+	mailbox := &distributedMailbox[VAR, DOMAIN]{
+		in:   make(chan DistributedMessage[VAR, DOMAIN]),
+		out:  make(chan DistributedMessage[VAR, DOMAIN]),
+		done: make(chan struct{}),
+	}
+	go func() {
+		queue := []DistributedMessage[VAR, DOMAIN]{}
+		for {
+			if len(queue) == 0 {
+				select {
+				case message := <-mailbox.in:
+					queue = append(queue, message)
+				case <-mailbox.done:
+					return
+				}
+				continue
+			}
+			select {
+			case message := <-mailbox.in:
+				queue = append(queue, message)
+			case mailbox.out <- queue[0]:
+				queue = queue[1:]
+			case <-mailbox.done:
+				return
+			}
+		}
+	}()
+	return mailbox
+}
+
+func (M *distributedMailbox[VAR, DOMAIN]) push(message DistributedMessage[VAR, DOMAIN]) bool {
+	select {
+	case M.in <- message:
+		return true
+	case <-M.done:
+		return false
+	}
+}
+
+func (M *distributedMailbox[VAR, DOMAIN]) close() {
+	close(M.done)
+}
+
+func openMailboxes[VAR comparable, DOMAIN comparable](n int) ([]*distributedMailbox[VAR, DOMAIN], []<-chan DistributedMessage[VAR, DOMAIN]) {
+	mailboxes := make([]*distributedMailbox[VAR, DOMAIN], n)
+	incoming := make([]<-chan DistributedMessage[VAR, DOMAIN], n)
+	for i := range mailboxes {
+		mailboxes[i] = newDistributedMailbox[VAR, DOMAIN]()
+		incoming[i] = mailboxes[i].out
+	}
+	return mailboxes, incoming
+}
+
+var errTransportClosed = errors.New("transport closed")
+
+// ChannelTransport delivers messages in process through Go channels.
+type ChannelTransport[VAR comparable, DOMAIN comparable] struct {
+	mailboxes []*distributedMailbox[VAR, DOMAIN]
+}
+
+func NewChannelTransport[VAR comparable, DOMAIN comparable]() *ChannelTransport[VAR, DOMAIN] {
+	return &ChannelTransport[VAR, DOMAIN]{}
+}
+
+func (T *ChannelTransport[VAR, DOMAIN]) Open(n int) ([]<-chan DistributedMessage[VAR, DOMAIN], error) {
+	mailboxes, incoming := openMailboxes[VAR, DOMAIN](n)
+	T.mailboxes = mailboxes
+	return incoming, nil
+}
+
+func (T *ChannelTransport[VAR, DOMAIN]) Send(message DistributedMessage[VAR, DOMAIN]) error {
+	if message.To < 0 || message.To >= len(T.mailboxes) {
+		return fmt.Errorf("no agent %d", message.To)
+	}
+	if !T.mailboxes[message.To].push(message) {
+		return errTransportClosed
+	}
+	return nil
+}
+
+func (T *ChannelTransport[VAR, DOMAIN]) Close() error {
+	for _, mailbox := range T.mailboxes {
+		mailbox.close()
+	}
+	T.mailboxes = nil
+	return nil
+}
+
+// TCPTransport gives every agent a TCP listener and sends each message gob-encoded over a connection per pair of
+// agents, which keeps them in order. VAR and DOMAIN must be types gob can encode.
+type TCPTransport[VAR comparable, DOMAIN comparable] struct {
+	// Host is the address the agents listen on, 127.0.0.1 when empty.
+	Host        string
+	listeners   []net.Listener
+	addresses   []string
+	mailboxes   []*distributedMailbox[VAR, DOMAIN]
+	connections map[[2]int]*tcpConnection
+	accepted    []net.Conn
+	mutex       sync.Mutex
+	// accepting counts the accept goroutines, Close waits for them so none outlives the run that started it.
+	accepting sync.WaitGroup
+}
+
+type tcpConnection struct {
+	conn    net.Conn
+	encoder *gob.Encoder
+	mutex   sync.Mutex
+}
+
+func NewTCPTransport[VAR comparable, DOMAIN comparable]() *TCPTransport[VAR, DOMAIN] {
+	return &TCPTransport[VAR, DOMAIN]{}
+}
+
+func (T *TCPTransport[VAR, DOMAIN]) Open(n int) ([]<-chan DistributedMessage[VAR, DOMAIN], error) {
+	host := T.Host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	mailboxes, incoming := openMailboxes[VAR, DOMAIN](n)
+	T.mutex.Lock()
+	T.mailboxes = mailboxes
+	T.connections = map[[2]int]*tcpConnection{}
+	T.listeners, T.addresses, T.accepted = nil, nil, nil
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+		if err != nil {
+			T.mutex.Unlock()
+			T.Close()
+			return nil, err
+		}
+		T.listeners = append(T.listeners, listener)
+		T.addresses = append(T.addresses, listener.Addr().String())
+		T.accepting.Add(1)
+		go T.accept(listener, mailboxes[i])
+	}
+	T.mutex.Unlock()
+	return incoming, nil
+}
+
+func (T *TCPTransport[VAR, DOMAIN]) accept(listener net.Listener, mailbox *distributedMailbox[VAR, DOMAIN]) {
+	defer T.accepting.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		T.mutex.Lock()
+		T.accepted = append(T.accepted, conn)
+		T.mutex.Unlock()
+		go func() {
+			decoder := gob.NewDecoder(conn)
+			for {
+				var message DistributedMessage[VAR, DOMAIN]
+				if decoder.Decode(&message) != nil || !mailbox.push(message) {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// connection dials the receiver the first time the sender writes to it.
+func (T *TCPTransport[VAR, DOMAIN]) connection(from int, to int) (*tcpConnection, error) {
+	T.mutex.Lock()
+	defer T.mutex.Unlock()
+	if to < 0 || to >= len(T.addresses) {
+		return nil, fmt.Errorf("no agent %d", to)
+	}
+	if connection, ok := T.connections[[2]int{from, to}]; ok {
+		return connection, nil
+	}
+	conn, err := net.Dial("tcp", T.addresses[to])
+	if err != nil {
+		return nil, err
+	}
+	connection := &tcpConnection{conn: conn, encoder: gob.NewEncoder(conn)}
+	T.connections[[2]int{from, to}] = connection
+	return connection, nil
+}
+
+func (T *TCPTransport[VAR, DOMAIN]) Send(message DistributedMessage[VAR, DOMAIN]) error {
+	connection, err := T.connection(message.From, message.To)
+	if err != nil {
+		return err
+	}
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+	return connection.encoder.Encode(message)
+}
+
+func (T *TCPTransport[VAR, DOMAIN]) Close() error {
+	T.mutex.Lock()
+	for _, listener := range T.listeners {
+		listener.Close()
+	}
+	T.listeners = nil
+	T.mutex.Unlock()
+	// Connections accepted before the listeners closed are closed below with the others
+	T.accepting.Wait()
+
+	T.mutex.Lock()
+	defer T.mutex.Unlock()
+	for _, connection := range T.connections {
+		connection.conn.Close()
+	}
+	for _, conn := range T.accepted {
+		conn.Close()
+	}
+	for _, mailbox := range T.mailboxes {
+		mailbox.close()
+	}
+	T.connections, T.accepted, T.mailboxes = nil, nil, nil
+	return nil
+}
@@ -2,6 +2,7 @@ package gointel
 
 import (
 	"context"
+	"fmt"
 	"github.com/mtresnik/goutils/pkg/goutils"
 	"sort"
 	"sync"
@@ -15,6 +16,15 @@ type CSPDomain[VAR comparable, DOMAIN comparable] struct {
 	Seeds             *map[VAR]DOMAIN
 	sortedVariables   *[]VAR
 	sortingFunction   *func(a, b VAR) bool
+	ExpandSymmetries  bool
+	symmetries        []Symmetry[VAR, DOMAIN]
+	// Parallelism bounds the number of search workers, runtime.NumCPU() when zero or negative.
+	Parallelism int
+	// DeterministicOrder sorts FindAllSolutions by the position of each value in its domain.
+	DeterministicOrder bool
+	// SeedMode decides whether Seeds are fixed pre-assignments or only value ordering hints, hints by default.
+	SeedMode SeedMode
+	cspValidator[VAR, DOMAIN]
 }
 
 func NewCSPDomain[VAR comparable, DOMAIN comparable](domain map[VAR][]DOMAIN, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *CSPDomain[VAR, DOMAIN] {
@@ -68,9 +78,14 @@ func (C *CSPDomain[VAR, DOMAIN]) Contains(v VAR) bool {
 }
 
 func (C *CSPDomain[VAR, DOMAIN]) Preprocess() {
-	var csp CSP[VAR, DOMAIN] = C
-	for _, preprocessor := range C.Preprocessors {
-		preprocessor.Preprocess(&csp)
+	_ = C.TryPreprocess()
+}
+
+// TryPreprocess is Preprocess returning ErrInconsistent when the preprocessors or the domain reduction leave a
+// variable without values.
+func (C *CSPDomain[VAR, DOMAIN]) TryPreprocess() error {
+	if err := tryPreprocess[VAR, DOMAIN](C, C.Preprocessors); err != nil {
+		return err
 	}
 	// Reduce domains
 	assignment := map[VAR]DOMAIN{}
@@ -84,6 +99,7 @@ func (C *CSPDomain[VAR, DOMAIN]) Preprocess() {
 		reduced := ReduceDomain(variable, assignment, domains, C.GetLocalConstraints(), C.GetGlobalConstraints())
 		C.DomainMap[variable] = reduced
 	}
+	return emptyDomainError[VAR, DOMAIN](C)
 }
 
 func (C *CSPDomain[VAR, DOMAIN]) GetLocalConstraints() map[VAR][]*LocalConstraint[VAR, DOMAIN] {
@@ -94,9 +110,12 @@ func (C *CSPDomain[VAR, DOMAIN]) GetGlobalConstraints() []*GlobalConstraint[VAR,
 	return C.globalConstraints
 }
 
-func (C *CSPDomain[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) {
+func (C *CSPDomain[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
 	if constraint == nil {
-		return
+		return nil
+	}
+	if err := C.accept(constraint, C.Contains); err != nil {
+		return err
 	}
 	local := (*constraint).AsLocal()
 	if local != nil {
@@ -120,63 +139,204 @@ func (C *CSPDomain[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAI
 		var global GlobalConstraint[VAR, DOMAIN] = *constraint
 		C.globalConstraints = append(C.globalConstraints, &global)
 	}
+	return nil
 }
 
-func (C *CSPDomain[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) {
-	for _, constraint := range constraints {
-		C.AddConstraint(constraint)
-	}
+func (C *CSPDomain[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *CSPDomain[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
 }
 
 func (C *CSPDomain[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
-	C.Preprocess()
-	agents := C.ConstructAgents()
+	return C.FindAllSolutionsContext(context.Background())
+}
+
+// FindAllSolutionsContext returns the solutions found before ctx is done.
+func (C *CSPDomain[VAR, DOMAIN]) FindAllSolutionsContext(ctx context.Context) []map[VAR]DOMAIN {
+	solutions, _ := C.TryFindAllSolutionsContext(ctx)
+	return solutions
+}
+
+// TryFindAllSolutions is FindAllSolutions returning the error of TryPreprocess, so an inconsistent model is told
+// apart from one whose search found nothing.
+func (C *CSPDomain[VAR, DOMAIN]) TryFindAllSolutions() ([]map[VAR]DOMAIN, error) {
+	return C.TryFindAllSolutionsContext(context.Background())
+}
+
+func (C *CSPDomain[VAR, DOMAIN]) TryFindAllSolutionsContext(ctx context.Context) ([]map[VAR]DOMAIN, error) {
+	if err := C.TryPreprocess(); err != nil {
+		return []map[VAR]DOMAIN{}, err
+	}
+	search, err := C.newSearch()
+	if search == nil {
+		return []map[VAR]DOMAIN{}, err
+	}
 	syncList := goutils.NewSyncList[map[VAR]DOMAIN]()
+	search.run(ctx, func(solution map[VAR]DOMAIN) bool {
+		syncList.Add(solution)
+		return true
+	})
 
-	variables := C.GetVariables()
-	if len(variables) == 0 {
-		return []map[VAR]DOMAIN{}
+	solutions := syncList.ToSlice()
+	if C.ExpandSymmetries {
+		solutions = ExpandSolutions(solutions, C.symmetries...)
 	}
-	first := variables[0]
-	_, ok := C.DomainMap[first]
-	if !ok {
-		return []map[VAR]DOMAIN{}
+	if C.DeterministicOrder {
+		sortSolutions(solutions, C.orderedVariables(), C.DomainMap)
 	}
+	return solutions, nil
+}
 
-	var wg sync.WaitGroup
-	for _, agent := range agents {
-		wg.Add(1)
-		go func(agent *CSPAgent[VAR, DOMAIN]) {
-			defer wg.Done()
-			channel := agent.GenerateSolutionChannel()
-			for solution := range channel {
-				syncList.Add(solution)
-			}
-		}(&agent)
+// BreakSymmetries injects lex-leader constraints for the symmetries along the given variable order.
+// With ExpandSymmetries set, FindAllSolutions maps the canonical solutions back onto the full solution set.
+func (C *CSPDomain[VAR, DOMAIN]) BreakSymmetries(order []VAR, compare func(a, b DOMAIN) int, symmetries ...Symmetry[VAR, DOMAIN]) error {
+	if err := BreakSymmetries[VAR, DOMAIN](C, order, compare, symmetries...); err != nil {
+		return err
 	}
-	wg.Wait()
+	C.symmetries = append(C.symmetries, symmetries...)
+	return nil
+}
 
-	return syncList.ToSlice()
+func (C *CSPDomain[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	return C.FindOneSolutionContext(context.Background())
 }
 
-type cspDomainResult[VAR comparable, DOMAIN comparable] struct {
-	solution map[VAR]DOMAIN
-	agent    *CSPAgent[VAR, DOMAIN]
-	err      error
+// FindOneSolutionContext returns nil when ctx is done before a solution is found.
+// With more than one worker the solution found first is not necessarily the first in DeterministicOrder.
+func (C *CSPDomain[VAR, DOMAIN]) FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN {
+	solution, _ := C.TryFindOneSolutionContext(ctx)
+	return solution
 }
 
-func (C *CSPDomain[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	C.Preprocess()
-	agents := C.ConstructAgents()
-	return FindFirstSolutionFromAgents(ctx, agents)
+// TryFindOneSolution is FindOneSolution returning the error of TryPreprocess.
+func (C *CSPDomain[VAR, DOMAIN]) TryFindOneSolution() (map[VAR]DOMAIN, error) {
+	return C.TryFindOneSolutionContext(context.Background())
+}
+
+func (C *CSPDomain[VAR, DOMAIN]) TryFindOneSolutionContext(ctx context.Context) (map[VAR]DOMAIN, error) {
+	if err := C.TryPreprocess(); err != nil {
+		return nil, err
+	}
+	search, err := C.newSearch()
+	if search == nil {
+		return nil, err
+	}
+	var mutex sync.Mutex
+	var ret map[VAR]DOMAIN = nil
+	search.run(ctx, func(solution map[VAR]DOMAIN) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if ret == nil {
+			ret = solution
+		}
+		return false
+	})
+	return ret, nil
 }
 
 func (C *CSPDomain[VAR, DOMAIN]) GenerateSolutionChannel() chan map[VAR]DOMAIN {
-	panic("illegal call")
+	return C.GenerateSolutionChannelContext(context.Background())
+}
+
+// GenerateSolutionChannelContext streams solutions as the workers find them and closes the channel
+// when the search is exhausted or ctx is done.
+func (C *CSPDomain[VAR, DOMAIN]) GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN {
+	ch := make(chan map[VAR]DOMAIN)
+	go func() {
+		defer close(ch)
+		C.Preprocess()
+		search, _ := C.newSearch()
+		if search == nil {
+			return
+		}
+		search.run(ctx, func(solution map[VAR]DOMAIN) bool {
+			select {
+			case ch <- solution:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
+
+// newSearch splits the first variable's values over a bounded pool of workers that steal work from each other.
+// Fixed seeds instead form a single root chain, so the search starts below them with their propagated domains.
+// It returns a nil search when there is nothing to search, with the error of ValidateSeeds or propagateSeeds when
+// the fixed seeds are invalid.
+func (C *CSPDomain[VAR, DOMAIN]) newSearch() (*cspWorkStealingSearch[VAR, DOMAIN], error) {
+	variables := C.GetVariables()
+	if len(variables) == 0 {
+		return nil, nil
+	}
+	seeds := map[VAR]DOMAIN{}
+	if C.Seeds != nil {
+		seeds = *C.Seeds
+	}
+	if C.SeedMode == SEED_FIXED && len(seeds) > 0 {
+		if err := ValidateSeeds[VAR, DOMAIN](C); err != nil {
+			return nil, err
+		}
+		domainMap, err := propagateSeeds[VAR, DOMAIN](C, seeds)
+		if err != nil {
+			return nil, err
+		}
+		var root *CSPNode[VAR, DOMAIN] = nil
+		for index, variable := range variables {
+			if value, ok := seeds[variable]; ok {
+				root = NewCSPNode(variable, index, value, []DOMAIN{value}, root)
+			}
+		}
+		agent := C.newAgent([]CSPNode[VAR, DOMAIN]{*root})
+		agent.DomainMap = &domainMap
+		return newCSPWorkStealingSearch(agent, C.Parallelism), nil
+	}
+	first := variables[0]
+	firstDomain, ok := C.DomainMap[first]
+	if !ok {
+		return nil, nil
+	}
+	if hint, ok := seeds[first]; ok && C.SeedMode == SEED_HINT {
+		firstDomain = hintFirst(firstDomain, hint)
+	}
+	roots := []CSPNode[VAR, DOMAIN]{}
+	for _, domain := range firstDomain {
+		roots = append(roots, *NewCSPNode(first, 0, domain, firstDomain))
+	}
+	agent := C.newAgent(roots)
+	if C.SeedMode == SEED_HINT {
+		agent.Hints = seeds
+	}
+	return newCSPWorkStealingSearch(agent, C.Parallelism), nil
 }
 
+func (C *CSPDomain[VAR, DOMAIN]) newAgent(stack []CSPNode[VAR, DOMAIN]) *CSPAgent[VAR, DOMAIN] {
+	agent := NewCSPAgent(&C.DomainMap, C.GetVariables(), stack)
+	agent.SortingFunction = C.sortingFunction
+	for _, local := range C.localConstraints {
+		toAdd := []*Constraint[VAR, DOMAIN]{}
+		for _, constraint := range local {
+			var c Constraint[VAR, DOMAIN] = *constraint
+			toAdd = append(toAdd, &c)
+		}
+		agent.AddAllConstraints(toAdd...)
+	}
+	toAdd := []*Constraint[VAR, DOMAIN]{}
+	for _, constraint := range C.globalConstraints {
+		var c Constraint[VAR, DOMAIN] = *constraint
+		toAdd = append(toAdd, &c)
+	}
+	agent.AddAllConstraints(toAdd...)
+	return agent
+}
+
+// ConstructAgents returns one agent per value of the first variable.
+//
+// Deprecated: searches no longer use per-value agents, FindAllSolutions splits the work over a bounded worker pool.
 func (C *CSPDomain[VAR, DOMAIN]) ConstructAgents() []CSPAgent[VAR, DOMAIN] {
 	variables := C.GetVariables()
 	if len(variables) == 0 {
@@ -200,28 +360,11 @@ func (C *CSPDomain[VAR, DOMAIN]) ConstructAgents() []CSPAgent[VAR, DOMAIN] {
 			return []CSPAgent[VAR, DOMAIN]{}
 		}
 	}
-	println("Constructing agents for domain:", len(firstDomain))
-
 	retSlice := []CSPAgent[VAR, DOMAIN]{}
 
 	for _, domain := range firstDomain {
 		current := NewCSPNode(first, 0, domain, firstDomain)
-		agent := NewCSPAgent(&C.DomainMap, variables, []CSPNode[VAR, DOMAIN]{*current})
-		agent.SortingFunction = C.sortingFunction
-		for _, local := range C.localConstraints {
-			toAdd := []*Constraint[VAR, DOMAIN]{}
-			for _, constraint := range local {
-				var c Constraint[VAR, DOMAIN] = *constraint
-				toAdd = append(toAdd, &c)
-			}
-			agent.AddAllConstraints(toAdd...)
-		}
-		toAdd := []*Constraint[VAR, DOMAIN]{}
-		for _, constraint := range C.globalConstraints {
-			var c Constraint[VAR, DOMAIN] = *constraint
-			toAdd = append(toAdd, &c)
-		}
-		agent.AddAllConstraints(toAdd...)
+		agent := C.newAgent([]CSPNode[VAR, DOMAIN]{*current})
 		retSlice = append(retSlice, *agent)
 	}
 
@@ -231,3 +374,44 @@ func (C *CSPDomain[VAR, DOMAIN]) ConstructAgents() []CSPAgent[VAR, DOMAIN] {
 func (C *CSPDomain[VAR, DOMAIN]) GetSeeds() *map[VAR]DOMAIN {
 	return C.Seeds
 }
+
+func (C *CSPDomain[VAR, DOMAIN]) SetSeed(variable VAR, value DOMAIN) {
+	if C.Seeds == nil {
+		C.Seeds = &map[VAR]DOMAIN{}
+	}
+	(*C.Seeds)[variable] = value
+}
+
+// orderedVariables is GetVariables when a sorting function is set, otherwise the variables sorted by their printed form,
+// which unlike map iteration order is the same on every run.
+func (C *CSPDomain[VAR, DOMAIN]) orderedVariables() []VAR {
+	if C.sortingFunction != nil {
+		return C.GetVariables()
+	}
+	variables := append([]VAR{}, C.GetVariables()...)
+	sort.Slice(variables, func(i, j int) bool {
+		return fmt.Sprint(variables[i]) < fmt.Sprint(variables[j])
+	})
+	return variables
+}
+
+// sortSolutions orders solutions by the domain position of their values, comparing variables in the given order.
+func sortSolutions[VAR comparable, DOMAIN comparable](solutions []map[VAR]DOMAIN, variables []VAR, domainMap map[VAR][]DOMAIN) {
+	positions := map[VAR]map[DOMAIN]int{}
+	for _, variable := range variables {
+		positions[variable] = map[DOMAIN]int{}
+		for index, value := range domainMap[variable] {
+			positions[variable][value] = index
+		}
+	}
+	sort.SliceStable(solutions, func(i, j int) bool {
+		for _, variable := range variables {
+			a := positions[variable][solutions[i][variable]]
+			b := positions[variable][solutions[j][variable]]
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+}
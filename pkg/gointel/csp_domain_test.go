@@ -92,3 +92,52 @@ func TestCSPDomain_FindAllSolutions(t *testing.T) {
 	}
 
 }
+
+func TestCSPDomain_WorkStealing(t *testing.T) {
+	for _, parallelism := range []int{1, 3, 0} {
+		csp := newQueensCSP(8)
+		csp.Parallelism = parallelism
+		if solutions := csp.FindAllSolutions(); len(solutions) != 92 {
+			t.Errorf("parallelism %d: expected 92 solutions, got %d", parallelism, len(solutions))
+		}
+	}
+
+	csp := newQueensCSP(8)
+	count := 0
+	for range csp.GenerateSolutionChannel() {
+		count++
+	}
+	if count != 92 {
+		t.Errorf("expected 92 streamed solutions, got %d", count)
+	}
+
+	solution := csp.FindOneSolution()
+	if len(solution) != 8 {
+		t.Fatalf("expected a complete assignment, got %v", solution)
+	}
+	for a := 0; a < 8; a++ {
+		for b := a + 1; b < 8; b++ {
+			constraint := queensConstraint{A: a, B: b}
+			if !constraint.IsSatisfied(solution) {
+				t.Errorf("queens %d and %d attack each other in %v", a, b, solution)
+			}
+		}
+	}
+}
+
+func TestCSPDomain_DeterministicOrder(t *testing.T) {
+	first := newQueensCSP(6)
+	first.DeterministicOrder = true
+	second := newQueensCSP(6)
+	second.DeterministicOrder = true
+	second.Parallelism = 2
+	a, b := first.FindAllSolutions(), second.FindAllSolutions()
+	if len(a) != 4 || len(b) != 4 {
+		t.Fatalf("expected 4 solutions, got %d and %d", len(a), len(b))
+	}
+	for i := range a {
+		if assignmentKey(a[i]) != assignmentKey(b[i]) {
+			t.Errorf("solution %d differs: %v vs %v", i, a[i], b[i])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package gointel
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type DOTGraphKind int
+
+const (
+	// DOT_PRIMAL links the variables that share a local constraint.
+	DOT_PRIMAL DOTGraphKind = iota
+	// DOT_HYPERGRAPH draws every local constraint as a box linked to the variables of its scope.
+	DOT_HYPERGRAPH
+)
+
+type DOTOptions[VAR comparable, DOMAIN comparable] struct {
+	Kind DOTGraphKind
+	// Name is the graph's name, csp when empty.
+	Name string
+	// Solution adds each variable's value to its node and gives every value its own color.
+	Solution map[VAR]DOMAIN
+	// Pruned counts the values removed from each variable's domain, nodes are shaded by the removed fraction.
+	// It is ignored when Solution is set.
+	Pruned map[VAR]int
+}
+
+// WriteDOT renders the constraint graph of the csp in the Graphviz DOT format. Nodes show the variable and the size
+// of its domain. Global constraints are always drawn as boxes linked to every variable, in dashed lines.
+func WriteDOT[VAR comparable, DOMAIN comparable](w io.Writer, csp CSP[VAR, DOMAIN], options DOTOptions[VAR, DOMAIN]) error {
+	variables := append([]VAR{}, csp.GetVariables()...)
+	// Sort by name so the output is reproducible
+	sort.SliceStable(variables, func(i, j int) bool {
+		return fmt.Sprint(variables[i]) < fmt.Sprint(variables[j])
+	})
+	ids := map[VAR]string{}
+	for i, variable := range variables {
+		ids[variable] = fmt.Sprintf("v%d", i)
+	}
+	name := options.Name
+	if name == "" {
+		name = "csp"
+	}
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "graph %s {\n  node [shape=ellipse, style=filled, fillcolor=white];\n", dotQuote(name))
+	colors := dotValueColors(options.Solution)
+	for _, variable := range variables {
+		label := fmt.Sprintf("%v\n|D|=%d", variable, len(csp.GetDomainForVariable(variable)))
+		attributes := ""
+		if value, ok := options.Solution[variable]; ok {
+			label += fmt.Sprintf("\n= %v", value)
+			attributes = fmt.Sprintf(", fillcolor=%s", dotQuote(colors[fmt.Sprint(value)]))
+		} else if pruned, ok := options.Pruned[variable]; ok && options.Solution == nil && pruned > 0 {
+			fraction := float64(pruned) / float64(pruned+len(csp.GetDomainForVariable(variable)))
+			label += fmt.Sprintf("\npruned %d", pruned)
+			attributes = fmt.Sprintf(", fillcolor=\"0.000 %.3f 1.000\"", fraction)
+		}
+		fmt.Fprintf(builder, "  %s [label=%s%s];\n", ids[variable], dotQuote(label), attributes)
+	}
+
+	if options.Kind == DOT_PRIMAL {
+		graph := NewConstraintGraph(csp)
+		for _, variable := range variables {
+			for _, neighbor := range graph.Neighbors[variable] {
+				if ids[variable] < ids[neighbor] {
+					fmt.Fprintf(builder, "  %s -- %s;\n", ids[variable], ids[neighbor])
+				}
+			}
+		}
+	} else {
+		for i, local := range uniqueLocalConstraints(variables, csp.GetLocalConstraints()) {
+			fmt.Fprintf(builder, "  c%d [shape=box, label=%s];\n", i, dotQuote(dotConstraintName(*local)))
+			linked := map[VAR]bool{}
+			for _, variable := range (*local).GetVariables() {
+				if id, ok := ids[variable]; ok && !linked[variable] {
+					linked[variable] = true
+					fmt.Fprintf(builder, "  c%d -- %s;\n", i, id)
+				}
+			}
+		}
+	}
+	for i, global := range csp.GetGlobalConstraints() {
+		fmt.Fprintf(builder, "  g%d [shape=box, style=dashed, label=%s];\n", i, dotQuote(dotConstraintName[VAR, DOMAIN](*global)))
+		for _, variable := range variables {
+			fmt.Fprintf(builder, "  g%d -- %s [style=dashed];\n", i, ids[variable])
+		}
+	}
+	builder.WriteString("}\n")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s) + "\""
+}
+
+// dotConstraintName returns the constraint's label, or its type without the package and type parameters.
+func dotConstraintName[VAR comparable, DOMAIN comparable](constraint Constraint[VAR, DOMAIN]) string {
+	if labeled, ok := constraint.(Labeled); ok {
+		return labeled.GetLabel()
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", constraint), "*")
+	if index := strings.Index(name, "["); index >= 0 {
+		name = name[:index]
+	}
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// dotValueColors spreads the hues of the solution's values evenly around the color wheel.
+func dotValueColors[VAR comparable, DOMAIN comparable](solution map[VAR]DOMAIN) map[string]string {
+	values := []string{}
+	seen := map[string]bool{}
+	for _, value := range solution {
+		if name := fmt.Sprint(value); !seen[name] {
+			seen[name] = true
+			values = append(values, name)
+		}
+	}
+	sort.Strings(values)
+	ret := map[string]string{}
+	for i, value := range values {
+		ret[value] = fmt.Sprintf("%.3f 0.400 1.000", float64(i)/float64(len(values)))
+	}
+	return ret
+}
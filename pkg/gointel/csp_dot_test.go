@@ -0,0 +1,40 @@
+package gointel
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	domainMap := map[string][]int{"a": {1, 2}, "b": {1, 2}, "c": {1, 2, 3}}
+	csp := NewCSPDomain(domainMap)
+	var different Constraint[string, int] = NewLabeledConstraint[string, int]("a != b", &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"a", "b"}})
+	var count Constraint[string, int] = NewAtMostConstraint([]string{"b", "c"}, 1, 2)
+	csp.AddAllConstraints(&different, &count)
+
+	buffer := &bytes.Buffer{}
+	if err := WriteDOT(buffer, CSP[string, int](csp), DOTOptions[string, int]{Solution: map[string]int{"a": 1, "b": 2, "c": 3}}); err != nil {
+		t.Fatal(err)
+	}
+	primal := buffer.String()
+	for _, expected := range []string{"graph \"csp\" {", "v0 [label=\"a\\n|D|=2\\n= 1\", fillcolor=\"0.000 0.400 1.000\"];", "v0 -- v1;", "v1 -- v2;"} {
+		if !strings.Contains(primal, expected) {
+			t.Errorf("expected %q in\n%s", expected, primal)
+		}
+	}
+	if strings.Contains(primal, "v0 -- v2") {
+		t.Errorf("a and c share no constraint\n%s", primal)
+	}
+
+	buffer.Reset()
+	if err := WriteDOT(buffer, CSP[string, int](csp), DOTOptions[string, int]{Kind: DOT_HYPERGRAPH, Pruned: map[string]int{"c": 1}}); err != nil {
+		t.Fatal(err)
+	}
+	hypergraph := buffer.String()
+	for _, expected := range []string{"[shape=box, label=\"a != b\"];", "[shape=box, label=\"CountConstraint\"];", "v2 [label=\"c\\n|D|=3\\npruned 1\", fillcolor=\"0.000 0.250 1.000\"];"} {
+		if !strings.Contains(hypergraph, expected) {
+			t.Errorf("expected %q in\n%s", expected, hypergraph)
+		}
+	}
+}
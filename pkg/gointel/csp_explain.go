@@ -0,0 +1,159 @@
+package gointel
+
+import (
+	"fmt"
+	"github.com/mtresnik/goutils/pkg/goutils"
+)
+
+// Labeled is implemented by constraints that carry a human-readable name for explanations.
+type Labeled interface {
+	GetLabel() string
+}
+
+// LabeledConstraint names a constraint. Local constraints stay labeled after a CSP stores them through AsLocal.
+type LabeledConstraint[VAR comparable, DOMAIN comparable] struct {
+	Constraint[VAR, DOMAIN]
+	Label string
+}
+
+func NewLabeledConstraint[VAR comparable, DOMAIN comparable](label string, constraint Constraint[VAR, DOMAIN]) *LabeledConstraint[VAR, DOMAIN] {
+	return &LabeledConstraint[VAR, DOMAIN]{Constraint: constraint, Label: label}
+}
+
+func (c *LabeledConstraint[VAR, DOMAIN]) GetLabel() string {
+	return c.Label
+}
+
+func (c *LabeledConstraint[VAR, DOMAIN]) AsLocal() *LocalConstraint[VAR, DOMAIN] {
+	local := c.Constraint.AsLocal()
+	if local == nil {
+		return nil
+	}
+	var ret LocalConstraint[VAR, DOMAIN] = &labeledLocalConstraint[VAR, DOMAIN]{LocalConstraint: *local, Label: c.Label}
+	return &ret
+}
+
+type labeledLocalConstraint[VAR comparable, DOMAIN comparable] struct {
+	LocalConstraint[VAR, DOMAIN]
+	Label string
+}
+
+func (c *labeledLocalConstraint[VAR, DOMAIN]) GetLabel() string {
+	return c.Label
+}
+
+func (c *labeledLocalConstraint[VAR, DOMAIN]) AsLocal() *LocalConstraint[VAR, DOMAIN] {
+	var ret LocalConstraint[VAR, DOMAIN] = c
+	return &ret
+}
+
+// ConflictItem is one member of an infeasibility explanation: a constraint, or the domain of a variable when
+// IsDomain is set.
+type ConflictItem[VAR comparable, DOMAIN comparable] struct {
+	Label      string
+	Constraint *Constraint[VAR, DOMAIN]
+	IsDomain   bool
+	Variable   VAR
+	Domain     []DOMAIN
+}
+
+func (c ConflictItem[VAR, DOMAIN]) String() string {
+	return c.Label
+}
+
+// ExplainInfeasibility returns a minimal set of constraints and domain restrictions that cannot be satisfied
+// together, or nil when the csp has a solution. Removing any one item of the set makes the rest satisfiable.
+// A domain restriction is relaxed by widening it to every value used in the csp, so only domains narrower than
+// that are candidates, including domains an earlier Preprocess already reduced. The set is found with QuickXplain,
+// which needs O(k log(n/k)) solves for k items out of n.
+func ExplainInfeasibility[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN]) []ConflictItem[VAR, DOMAIN] {
+	variables := csp.GetVariables()
+	universe := []DOMAIN{}
+	for _, variable := range variables {
+		for _, value := range csp.GetDomainForVariable(variable) {
+			if !goutils.Contains(universe, func(other DOMAIN) bool { return other == value }) {
+				universe = append(universe, value)
+			}
+		}
+	}
+	items := []ConflictItem[VAR, DOMAIN]{}
+	for _, variable := range variables {
+		if domain := csp.GetDomainForVariable(variable); len(domain) < len(universe) {
+			items = append(items, ConflictItem[VAR, DOMAIN]{
+				Label:    fmt.Sprintf("domain of %v is %v", variable, domain),
+				IsDomain: true,
+				Variable: variable,
+				Domain:   domain,
+			})
+		}
+	}
+	for _, local := range uniqueLocalConstraints(variables, csp.GetLocalConstraints()) {
+		var c Constraint[VAR, DOMAIN] = *local
+		items = append(items, ConflictItem[VAR, DOMAIN]{Label: constraintLabel(c, (*local).GetVariables()), Constraint: &c})
+	}
+	for _, global := range csp.GetGlobalConstraints() {
+		var c Constraint[VAR, DOMAIN] = *global
+		items = append(items, ConflictItem[VAR, DOMAIN]{Label: constraintLabel[VAR, DOMAIN](c, nil), Constraint: &c})
+	}
+
+	explainer := &infeasibilityExplainer[VAR, DOMAIN]{variables: variables, universe: universe}
+	if explainer.isFeasible(items) {
+		return nil
+	}
+	return explainer.quickXplain([]ConflictItem[VAR, DOMAIN]{}, false, items)
+}
+
+func (C *CSPDomain[VAR, DOMAIN]) ExplainInfeasibility() []ConflictItem[VAR, DOMAIN] {
+	return ExplainInfeasibility[VAR, DOMAIN](C)
+}
+
+func constraintLabel[VAR comparable, DOMAIN comparable](constraint Constraint[VAR, DOMAIN], variables []VAR) string {
+	if labeled, ok := constraint.(Labeled); ok {
+		return labeled.GetLabel()
+	}
+	if variables == nil {
+		return fmt.Sprintf("%T", constraint)
+	}
+	return fmt.Sprintf("%T on %v", constraint, variables)
+}
+
+type infeasibilityExplainer[VAR comparable, DOMAIN comparable] struct {
+	variables []VAR
+	universe  []DOMAIN
+}
+
+// quickXplain returns the minimal subset of candidates that conflicts with background, changed tells whether
+// background grew since the last feasibility check.
+func (E *infeasibilityExplainer[VAR, DOMAIN]) quickXplain(background []ConflictItem[VAR, DOMAIN], changed bool, candidates []ConflictItem[VAR, DOMAIN]) []ConflictItem[VAR, DOMAIN] {
+	if changed && !E.isFeasible(background) {
+		return []ConflictItem[VAR, DOMAIN]{}
+	}
+	if len(candidates) <= 1 {
+		return candidates
+	}
+	half := len(candidates) / 2
+	first, second := candidates[:half], candidates[half:]
+	secondConflict := E.quickXplain(append(append([]ConflictItem[VAR, DOMAIN]{}, background...), first...), len(first) > 0, second)
+	firstConflict := E.quickXplain(append(append([]ConflictItem[VAR, DOMAIN]{}, background...), secondConflict...), len(secondConflict) > 0, first)
+	return append(firstConflict, secondConflict...)
+}
+
+// isFeasible solves the model made of only the given items, other domains widened to the universe.
+func (E *infeasibilityExplainer[VAR, DOMAIN]) isFeasible(items []ConflictItem[VAR, DOMAIN]) bool {
+	domainMap := map[VAR][]DOMAIN{}
+	for _, variable := range E.variables {
+		domainMap[variable] = E.universe
+	}
+	for _, item := range items {
+		if item.IsDomain {
+			domainMap[item.Variable] = item.Domain
+		}
+	}
+	csp := NewDecomposedCSP(domainMap)
+	for _, item := range items {
+		if !item.IsDomain {
+			csp.AddConstraint(item.Constraint)
+		}
+	}
+	return csp.FindOneSolution() != nil
+}
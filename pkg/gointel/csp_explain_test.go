@@ -0,0 +1,60 @@
+package gointel
+
+import (
+	"sort"
+	"testing"
+)
+
+func notEqual(label string, a, b string) *Constraint[string, string] {
+	var c Constraint[string, string] = NewLabeledConstraint[string, string](label, &LocalAllDifferentConstraint[string, string]{Variables: &[]string{a, b}})
+	return &c
+}
+
+func TestExplainInfeasibility(t *testing.T) {
+	colors := []string{"red", "green", "blue"}
+	csp := NewCSPDomain(map[string][]string{
+		"A": {"red"},
+		"B": {"green"},
+		"C": colors,
+		"D": colors,
+	})
+	var notBlue Constraint[string, string] = NewLabeledConstraint[string, string]("C is not blue", NewAtMostConstraint([]string{"C"}, 0, "blue"))
+	csp.AddAllConstraints(
+		notEqual("A differs from C", "A", "C"),
+		notEqual("B differs from C", "B", "C"),
+		notEqual("C differs from D", "C", "D"),
+		notEqual("A differs from D", "A", "D"),
+		&notBlue,
+	)
+	labels := []string{}
+	for _, item := range csp.ExplainInfeasibility() {
+		labels = append(labels, item.Label)
+	}
+	sort.Strings(labels)
+	expected := []string{
+		"A differs from C",
+		"B differs from C",
+		"C is not blue",
+		"domain of A is [red]",
+		"domain of B is [green]",
+	}
+	if len(labels) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, labels)
+	}
+	for i := range expected {
+		if labels[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, labels)
+			break
+		}
+	}
+
+	if solution := csp.FindOneSolution(); solution != nil {
+		t.Errorf("expected the model to be infeasible, got %v", solution)
+	}
+
+	feasible := NewCSPDomain(map[string][]string{"A": colors, "B": colors})
+	feasible.AddConstraint(notEqual("A differs from B", "A", "B"))
+	if explanation := feasible.ExplainInfeasibility(); explanation != nil {
+		t.Errorf("expected no explanation for a feasible model, got %v", explanation)
+	}
+}
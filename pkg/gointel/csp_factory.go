@@ -1,36 +1,365 @@
 package gointel
 
 import (
+	"context"
+	"fmt"
 	"github.com/mtresnik/goutils/pkg/goutils"
 	"math"
+	"sync"
+	"time"
 )
 
+// CSP_REPEAT_THRESHOLD is the largest search space a repeatable request is solved on a single worker.
+// Above it the search stays parallel and only the order of FindAllSolutions is made reproducible.
 const CSP_REPEAT_THRESHOLD = 3.0287511e+14
 
 type CSPFactoryRequest[VAR comparable, DOMAIN comparable] struct {
-	DomainMap     map[VAR][]DOMAIN
+	DomainMap map[VAR][]DOMAIN
+	// MaxTime limits each search in milliseconds, unlimited when zero or negative.
 	MaxTime       int64
 	Preprocessors []CSPPreprocessor[VAR, DOMAIN]
-	IsRepeatable  bool
+	// IsRepeatable asks for a solver that returns the same solutions in the same order on every run. It limits
+	// automatic selection to deterministic solvers and makes the "domain" solver sort its results.
+	IsRepeatable bool
+	// Constraints are added to the created CSP and are used to select a solver.
+	Constraints []*Constraint[VAR, DOMAIN]
+	// Solver names a registered or built-in factory, the solver is selected from the problem's features when empty.
+	Solver string
 }
 
 type CSPFactory[VAR comparable, DOMAIN comparable] func(request CSPFactoryRequest[VAR, DOMAIN]) *CSP[VAR, DOMAIN]
 
-func DefaultCSPFactory[VAR comparable, DOMAIN comparable](request CSPFactoryRequest[VAR, DOMAIN]) *CSP[VAR, DOMAIN] {
-	numVariables := len(request.DomainMap)
-	domainValues := [][]DOMAIN{}
-	for _, domain := range request.DomainMap {
-		domainValues = append(domainValues, domain)
-	}
-	maxDomainInt := len(goutils.MaxBy(domainValues, func(domains []DOMAIN) float64 {
-		return float64(len(domains))
-	}))
-	maxDomain := float64(maxDomainInt)
-	maxChildren := math.Pow(maxDomain, float64(numVariables))
-	if maxChildren > float64(CSP_MAX_CHILDREN) || maxDomainInt > numVariables {
-		var ret CSP[VAR, DOMAIN] = NewCSPDomain(request.DomainMap)
+const (
+	CSP_SOLVER_TREE            = "tree"
+	CSP_SOLVER_DOMAIN          = "domain"
+	CSP_SOLVER_DECOMPOSED      = "decomposed"
+	CSP_SOLVER_TREE_STRUCTURED = "tree-structured"
+	CSP_SOLVER_MIN_CONFLICTS   = "min-conflicts"
+	CSP_SOLVER_INT             = "int"
+	CSP_SOLVER_SAT             = "sat"
+	CSP_SOLVER_DISTRIBUTED     = "distributed"
+)
+
+var cspFactories = map[string]any{}
+var cspFactoriesMutex sync.RWMutex
+
+// RegisterCSPFactory makes a factory available to CSPFactoryRequest.Solver, replacing built-in and earlier factories of the same name.
+func RegisterCSPFactory[VAR comparable, DOMAIN comparable](name string, factory CSPFactory[VAR, DOMAIN]) {
+	cspFactoriesMutex.Lock()
+	defer cspFactoriesMutex.Unlock()
+	cspFactories[name] = factory
+}
+
+// GetCSPFactory returns the factory registered under name for these types, or the built-in solver of that name.
+// Built-in factories panic when the solver rejects a request constraint, see TryDefaultCSPFactory.
+func GetCSPFactory[VAR comparable, DOMAIN comparable](name string) (CSPFactory[VAR, DOMAIN], bool) {
+	if factory, ok := registeredCSPFactory[VAR, DOMAIN](name); ok {
+		return factory, true
+	}
+	if !isBuiltinCSPSolver[VAR, DOMAIN](name) {
+		return nil, false
+	}
+	return func(request CSPFactoryRequest[VAR, DOMAIN]) *CSP[VAR, DOMAIN] {
+		ret, err := finishCSP(newBuiltinCSP(name, request), request)
+		if err != nil {
+			panic(err)
+		}
 		return &ret
+	}, true
+}
+
+func registeredCSPFactory[VAR comparable, DOMAIN comparable](name string) (CSPFactory[VAR, DOMAIN], bool) {
+	cspFactoriesMutex.RLock()
+	registered, ok := cspFactories[name]
+	cspFactoriesMutex.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	factory, ok := registered.(CSPFactory[VAR, DOMAIN])
+	return factory, ok
+}
+
+func isBuiltinCSPSolver[VAR comparable, DOMAIN comparable](name string) bool {
+	switch name {
+	case CSP_SOLVER_TREE, CSP_SOLVER_DOMAIN, CSP_SOLVER_DECOMPOSED, CSP_SOLVER_TREE_STRUCTURED, CSP_SOLVER_MIN_CONFLICTS, CSP_SOLVER_SAT, CSP_SOLVER_DISTRIBUTED:
+		return true
+	case CSP_SOLVER_INT:
+		var domainMap any = map[VAR][]DOMAIN{}
+		_, ok := domainMap.(map[int][]int)
+		return ok
+	}
+	return false
+}
+
+// DefaultCSPFactory builds the CSP named by request.Solver, or the one SelectCSPSolver picks, then adds the
+// request's constraints and applies its preprocessors, time limit and repeatability. Unknown solver names and
+// rejected constraints panic, see TryDefaultCSPFactory.
+func DefaultCSPFactory[VAR comparable, DOMAIN comparable](request CSPFactoryRequest[VAR, DOMAIN]) *CSP[VAR, DOMAIN] {
+	ret, err := TryDefaultCSPFactory(request)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// TryDefaultCSPFactory is DefaultCSPFactory returning ErrUnknownSolver for unknown solver names and the error of a
+// built-in solver rejecting one of the request's constraints.
+func TryDefaultCSPFactory[VAR comparable, DOMAIN comparable](request CSPFactoryRequest[VAR, DOMAIN]) (*CSP[VAR, DOMAIN], error) {
+	name := request.Solver
+	if name == "" {
+		name = SelectCSPSolver(request)
+	}
+	if factory, ok := registeredCSPFactory[VAR, DOMAIN](name); ok {
+		return factory(request), nil
+	}
+	if !isBuiltinCSPSolver[VAR, DOMAIN](name) {
+		return nil, fmt.Errorf("%w: no CSP factory named %q", ErrUnknownSolver, name)
+	}
+	ret, err := finishCSP(newBuiltinCSP(name, request), request)
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// CSPFeatures are the measurements SelectCSPSolver bases its choice on.
+type CSPFeatures struct {
+	NumVariables  int
+	MaxDomainSize int
+	// SearchSpace is the product of the domain sizes.
+	SearchSpace float64
+	// Density is the fraction of variable pairs that share a local constraint.
+	Density           float64
+	MaxArity          int
+	GlobalConstraints int
+	Components        int
+	// CutsetSize is the size of the cycle cutset, -1 when the model is not binary.
+	CutsetSize int
+	// DenseInts is true for int models whose values span at most CSP_MAX_CHILDREN.
+	DenseInts bool
+}
+
+func MeasureCSPFeatures[VAR comparable, DOMAIN comparable](request CSPFactoryRequest[VAR, DOMAIN]) CSPFeatures {
+	probe := NewCSPDomain(request.DomainMap)
+	probe.AddAllConstraints(request.Constraints...)
+	variables := probe.GetVariables()
+	graph := NewConstraintGraph[VAR, DOMAIN](probe)
+
+	features := CSPFeatures{
+		NumVariables:      len(variables),
+		SearchSpace:       1,
+		GlobalConstraints: len(probe.GetGlobalConstraints()),
+		Components:        len(graph.ConnectedComponents()),
+		CutsetSize:        -1,
+	}
+	for _, variable := range variables {
+		size := len(request.DomainMap[variable])
+		features.MaxDomainSize = max(features.MaxDomainSize, size)
+		features.SearchSpace *= float64(size)
+	}
+	if pairs := features.NumVariables * (features.NumVariables - 1) / 2; pairs > 0 {
+		features.Density = float64(graph.NumEdges()) / float64(pairs)
+	}
+	for _, constraint := range uniqueLocalConstraints(variables, probe.GetLocalConstraints()) {
+		scope := goutils.Unique(goutils.Filter((*constraint).GetVariables(), probe.Contains))
+		features.MaxArity = max(features.MaxArity, len(scope))
+	}
+	if features.GlobalConstraints > 0 {
+		features.MaxArity = max(features.MaxArity, features.NumVariables)
+	}
+	if solver := newTreeSolver[VAR, DOMAIN](probe); solver != nil {
+		features.CutsetSize = len(solver.cutset)
+	}
+	var domainMap any = request.DomainMap
+	if ints, ok := domainMap.(map[int][]int); ok {
+		minValue, maxValue := math.MaxInt, math.MinInt
+		for _, domain := range ints {
+			for _, value := range domain {
+				minValue = min(minValue, value)
+				maxValue = max(maxValue, value)
+			}
+		}
+		features.DenseInts = maxValue >= minValue && maxValue-minValue < CSP_MAX_CHILDREN
+	}
+	return features
+}
+
+// SelectCSPSolver names the built-in solver suited to the request. Without constraints only the domain sizes are
+// known, so the choice is between a CSPTree for small search spaces and a CSPDomain otherwise.
+func SelectCSPSolver[VAR comparable, DOMAIN comparable](request CSPFactoryRequest[VAR, DOMAIN]) string {
+	features := MeasureCSPFeatures(request)
+	if len(request.Constraints) > 0 {
+		switch {
+		case request.IsRepeatable:
+			return CSP_SOLVER_DOMAIN
+		case features.Components > 1:
+			return CSP_SOLVER_DECOMPOSED
+		case features.CutsetSize >= 0 && features.CutsetSize <= CSP_MAX_CUTSET_SIZE && 2*features.CutsetSize <= features.NumVariables:
+			// Conditioning pays off while the cutset is a small part of the model
+			return CSP_SOLVER_TREE_STRUCTURED
+		case features.MaxArity > 2 || features.Density > 0.5:
+			// Dense and wide constraints prune well once domains are reduced, fastest on bitsets when values allow
+			if features.DenseInts {
+				return CSP_SOLVER_INT
+			}
+			return CSP_SOLVER_DOMAIN
+		}
+	}
+	if request.IsRepeatable || features.SearchSpace > float64(CSP_MAX_CHILDREN) || features.MaxDomainSize > features.NumVariables {
+		return CSP_SOLVER_DOMAIN
+	}
+	return CSP_SOLVER_TREE
+}
+
+func newBuiltinCSP[VAR comparable, DOMAIN comparable](name string, request CSPFactoryRequest[VAR, DOMAIN]) CSP[VAR, DOMAIN] {
+	switch name {
+	case CSP_SOLVER_TREE:
+		return NewCSPTree(request.DomainMap, request.Preprocessors...)
+	case CSP_SOLVER_DECOMPOSED:
+		return NewDecomposedCSP(request.DomainMap, request.Preprocessors...)
+	case CSP_SOLVER_TREE_STRUCTURED:
+		return NewTreeStructuredCSP(request.DomainMap, request.Preprocessors...)
+	case CSP_SOLVER_MIN_CONFLICTS:
+		return NewMinConflictsCSP(request.DomainMap, request.Preprocessors...)
+	case CSP_SOLVER_SAT:
+		return NewSATCSP(request.DomainMap, request.Preprocessors...)
+	case CSP_SOLVER_DISTRIBUTED:
+		return NewDistributedCSP(request.DomainMap, nil, request.Preprocessors...)
+	case CSP_SOLVER_INT:
+		var domainMap any = request.DomainMap
+		var preprocessors any = request.Preprocessors
+		var ret any = NewIntCSP(domainMap.(map[int][]int), preprocessors.([]CSPPreprocessor[int, int])...)
+		return ret.(CSP[VAR, DOMAIN])
+	}
+	ret := NewCSPDomain(request.DomainMap, request.Preprocessors...)
+	if request.IsRepeatable {
+		ret.DeterministicOrder = true
+		searchSpace := 1.0
+		for _, domain := range request.DomainMap {
+			searchSpace *= float64(len(domain))
+		}
+		if searchSpace <= CSP_REPEAT_THRESHOLD {
+			ret.Parallelism = 1
+		}
+	}
+	return ret
+}
+
+// finishCSP returns the solver itself when the request has no time limit, so callers can reach the methods of its
+// concrete type.
+func finishCSP[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN], request CSPFactoryRequest[VAR, DOMAIN]) (CSP[VAR, DOMAIN], error) {
+	if err := csp.AddAllConstraints(request.Constraints...); err != nil {
+		return nil, err
+	}
+	if request.MaxTime > 0 {
+		return &timeLimitedCSP[VAR, DOMAIN]{CSP: csp, MaxTime: time.Duration(request.MaxTime) * time.Millisecond}, nil
+	}
+	return csp, nil
+}
+
+// timeLimitedCSP stops each search after MaxTime. Solvers that cannot be cancelled keep running in the background
+// and their late results are dropped. Unwrap returns the solver it limits.
+type timeLimitedCSP[VAR comparable, DOMAIN comparable] struct {
+	CSP[VAR, DOMAIN]
+	MaxTime time.Duration
+}
+
+func (C *timeLimitedCSP[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	ctx, cancel := context.WithTimeout(context.Background(), C.MaxTime)
+	defer cancel()
+	return C.FindOneSolutionContext(ctx)
+}
+
+func (C *timeLimitedCSP[VAR, DOMAIN]) FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN {
+	if cancellable, ok := C.CSP.(CancellableCSP[VAR, DOMAIN]); ok {
+		return cancellable.FindOneSolutionContext(ctx)
+	}
+	return awaitResult(ctx, C.CSP.FindOneSolution)
+}
+
+// FindAllSolutions returns the solutions found before the deadline when the solver supports cancellation, none otherwise.
+func (C *timeLimitedCSP[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
+	ctx, cancel := context.WithTimeout(context.Background(), C.MaxTime)
+	defer cancel()
+	return C.FindAllSolutionsContext(ctx)
+}
+
+func (C *timeLimitedCSP[VAR, DOMAIN]) FindAllSolutionsContext(ctx context.Context) []map[VAR]DOMAIN {
+	if cancellable, ok := C.CSP.(interface {
+		FindAllSolutionsContext(ctx context.Context) []map[VAR]DOMAIN
+	}); ok {
+		return cancellable.FindAllSolutionsContext(ctx)
+	}
+	if solutions := awaitResult(ctx, C.CSP.FindAllSolutions); solutions != nil {
+		return solutions
+	}
+	return []map[VAR]DOMAIN{}
+}
+
+// GenerateSolutionChannel closes the channel at the deadline.
+func (C *timeLimitedCSP[VAR, DOMAIN]) GenerateSolutionChannel() chan map[VAR]DOMAIN {
+	ctx, cancel := context.WithTimeout(context.Background(), C.MaxTime)
+	ret := make(chan map[VAR]DOMAIN)
+	go func() {
+		defer cancel()
+		defer close(ret)
+		for solution := range C.GenerateSolutionChannelContext(ctx) {
+			select {
+			case ret <- solution:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ret
+}
+
+// GenerateSolutionChannelContext streams the solver's solutions when it can, or sends those FindAllSolutionsContext
+// returns otherwise.
+func (C *timeLimitedCSP[VAR, DOMAIN]) GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN {
+	if generator, ok := C.CSP.(interface {
+		GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN
+	}); ok {
+		return generator.GenerateSolutionChannelContext(ctx)
+	}
+	ret := make(chan map[VAR]DOMAIN)
+	go func() {
+		defer close(ret)
+		for _, solution := range C.FindAllSolutionsContext(ctx) {
+			select {
+			case ret <- solution:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ret
+}
+
+// Validate reports the issues of the limited solver, none when it cannot validate.
+func (C *timeLimitedCSP[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	if validator, ok := C.CSP.(interface {
+		Validate() []ValidationIssue[VAR, DOMAIN]
+	}); ok {
+		return validator.Validate()
+	}
+	return []ValidationIssue[VAR, DOMAIN]{}
+}
+
+func (C *timeLimitedCSP[VAR, DOMAIN]) Unwrap() CSP[VAR, DOMAIN] {
+	return C.CSP
+}
+
+func awaitResult[T any](ctx context.Context, find func() T) T {
+	results := make(chan T, 1)
+	go func() {
+		results <- find()
+	}()
+	select {
+	case result := <-results:
+		return result
+	case <-ctx.Done():
+		var zero T
+		return zero
 	}
-	var ret CSP[VAR, DOMAIN] = NewCSPTree(request.DomainMap)
-	return &ret
 }
@@ -0,0 +1,130 @@
+package gointel
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSelectCSPSolver(t *testing.T) {
+	domainMap := map[string][]string{"A": {"r", "g"}, "B": {"r", "g"}, "C": {"r", "g"}}
+	request := CSPFactoryRequest[string, string]{DomainMap: domainMap}
+	if name := SelectCSPSolver(request); name != CSP_SOLVER_TREE {
+		t.Errorf("expected %q without constraints, got %q", CSP_SOLVER_TREE, name)
+	}
+
+	var ab Constraint[string, string] = &mapColoringConstraint{From: "A", To: "B"}
+	request.Constraints = []*Constraint[string, string]{&ab}
+	if name := SelectCSPSolver(request); name != CSP_SOLVER_DECOMPOSED {
+		t.Errorf("expected %q for disconnected variables, got %q", CSP_SOLVER_DECOMPOSED, name)
+	}
+
+	var bc Constraint[string, string] = &mapColoringConstraint{From: "B", To: "C"}
+	request.Constraints = append(request.Constraints, &bc)
+	if name := SelectCSPSolver(request); name != CSP_SOLVER_TREE_STRUCTURED {
+		t.Errorf("expected %q for a path, got %q", CSP_SOLVER_TREE_STRUCTURED, name)
+	}
+	request.IsRepeatable = true
+	if name := SelectCSPSolver(request); name != CSP_SOLVER_DOMAIN {
+		t.Errorf("expected %q for a repeatable request, got %q", CSP_SOLVER_DOMAIN, name)
+	}
+
+	queens := newQueensCSP(6)
+	intRequest := CSPFactoryRequest[int, int]{DomainMap: queens.DomainMap}
+	for _, constraint := range uniqueLocalConstraints(queens.GetVariables(), queens.GetLocalConstraints()) {
+		var c Constraint[int, int] = *constraint
+		intRequest.Constraints = append(intRequest.Constraints, &c)
+	}
+	if name := SelectCSPSolver(intRequest); name != CSP_SOLVER_INT {
+		t.Errorf("expected %q for dense ints, got %q", CSP_SOLVER_INT, name)
+	}
+	csp := *DefaultCSPFactory(intRequest)
+	if _, ok := csp.(*IntCSP); !ok {
+		t.Errorf("expected an IntCSP, got %T", csp)
+	}
+	if solutions := csp.FindAllSolutions(); len(solutions) != 4 {
+		t.Errorf("expected 4 solutions, got %d", len(solutions))
+	}
+	intRequest.IsRepeatable = true
+	if name := SelectCSPSolver(intRequest); name != CSP_SOLVER_DOMAIN {
+		t.Errorf("expected %q for a repeatable int request, got %q", CSP_SOLVER_DOMAIN, name)
+	}
+
+	// Dense ints alone do not decide, a sparse chain is still tree-structured
+	chain := CSPFactoryRequest[int, int]{DomainMap: map[int][]int{0: {0, 1}, 1: {0, 1}, 2: {0, 1}}}
+	for a := 0; a < 2; a++ {
+		pair := []int{a, a + 1}
+		var c Constraint[int, int] = &LocalAllDifferentConstraint[int, int]{Variables: &pair}
+		chain.Constraints = append(chain.Constraints, &c)
+	}
+	if name := SelectCSPSolver(chain); name != CSP_SOLVER_TREE_STRUCTURED {
+		t.Errorf("expected %q for an int path, got %q", CSP_SOLVER_TREE_STRUCTURED, name)
+	}
+}
+
+func TestDefaultCSPFactory_Request(t *testing.T) {
+	domainMap := map[string][]int{"X": {1, 2, 3}, "Y": {1, 2, 3}, "Z": {1, 2, 3}}
+	variables := []string{"X", "Y", "Z"}
+	var c Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &variables}
+	request := CSPFactoryRequest[string, int]{
+		DomainMap:     domainMap,
+		Constraints:   []*Constraint[string, int]{&c},
+		Preprocessors: []CSPPreprocessor[string, int]{&AC3Preprocessor[string, int]{}},
+		IsRepeatable:  true,
+	}
+	first := (*DefaultCSPFactory(request)).FindAllSolutions()
+	second := (*DefaultCSPFactory(request)).FindAllSolutions()
+	if len(first) != 6 || !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same 6 solutions twice, got %v and %v", first, second)
+	}
+
+	request.Solver = CSP_SOLVER_TREE
+	if csp := *DefaultCSPFactory(request); len(csp.FindAllSolutions()) != 6 {
+		t.Errorf("expected 6 solutions from the tree solver")
+	}
+}
+
+func TestDefaultCSPFactory_MaxTime(t *testing.T) {
+	queens := newQueensCSP(14)
+	request := CSPFactoryRequest[int, int]{DomainMap: queens.DomainMap, Solver: CSP_SOLVER_DOMAIN, MaxTime: 50}
+	for _, constraint := range uniqueLocalConstraints(queens.GetVariables(), queens.GetLocalConstraints()) {
+		var c Constraint[int, int] = *constraint
+		request.Constraints = append(request.Constraints, &c)
+	}
+	csp := *DefaultCSPFactory(request)
+	start := time.Now()
+	csp.FindAllSolutions()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the search to stop near 50ms, took %v", elapsed)
+	}
+	if _, ok := csp.(MultiCSP[int, int]); !ok {
+		t.Errorf("expected the time limited CSP to stream solutions")
+	}
+	if _, ok := csp.(interface{ Unwrap() CSP[int, int] }).Unwrap().(*CSPDomain[int, int]); !ok {
+		t.Errorf("expected the time limited CSP to unwrap to a CSPDomain")
+	}
+
+	request.MaxTime = 0
+	if _, ok := (*DefaultCSPFactory(request)).(*CSPDomain[int, int]); !ok {
+		t.Errorf("expected the concrete solver without a time limit")
+	}
+}
+
+func TestRegisterCSPFactory(t *testing.T) {
+	called := false
+	RegisterCSPFactory("test-custom", func(request CSPFactoryRequest[string, int]) *CSP[string, int] {
+		called = true
+		var ret CSP[string, int] = NewCSPTree(request.DomainMap)
+		return &ret
+	})
+	DefaultCSPFactory(CSPFactoryRequest[string, int]{DomainMap: map[string][]int{"A": {1}}, Solver: "test-custom"})
+	if !called {
+		t.Errorf("expected the registered factory to be used")
+	}
+	if _, ok := GetCSPFactory[int, string]("test-custom"); ok {
+		t.Errorf("expected no factory for other types")
+	}
+	if _, ok := GetCSPFactory[string, int](CSP_SOLVER_INT); ok {
+		t.Errorf("expected the int solver to require int variables and values")
+	}
+}
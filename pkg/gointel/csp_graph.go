@@ -0,0 +1,89 @@
+package gointel
+
+// ConstraintGraph is the primal graph of a CSP: variables are adjacent when they share a local constraint.
+// Global constraints relate every variable, so a graph with globals is treated as fully connected.
+type ConstraintGraph[VAR comparable] struct {
+	Variables []VAR
+	Neighbors map[VAR][]VAR
+	HasGlobal bool
+}
+
+func NewConstraintGraph[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN]) *ConstraintGraph[VAR] {
+	variables := csp.GetVariables()
+	neighborSets := map[VAR]map[VAR]bool{}
+	for _, variable := range variables {
+		neighborSets[variable] = map[VAR]bool{}
+	}
+	for _, constraint := range uniqueLocalConstraints(variables, csp.GetLocalConstraints()) {
+		scope := []VAR{}
+		for _, variable := range (*constraint).GetVariables() {
+			if _, ok := neighborSets[variable]; ok {
+				scope = append(scope, variable)
+			}
+		}
+		for i, first := range scope {
+			for _, second := range scope[i+1:] {
+				if first != second {
+					neighborSets[first][second] = true
+					neighborSets[second][first] = true
+				}
+			}
+		}
+	}
+	neighbors := map[VAR][]VAR{}
+	for _, variable := range variables {
+		neighbors[variable] = []VAR{}
+		// Keep the CSP's variable order so traversals are reproducible
+		for _, other := range variables {
+			if neighborSets[variable][other] {
+				neighbors[variable] = append(neighbors[variable], other)
+			}
+		}
+	}
+	return &ConstraintGraph[VAR]{
+		Variables: variables,
+		Neighbors: neighbors,
+		HasGlobal: len(csp.GetGlobalConstraints()) > 0,
+	}
+}
+
+func (G *ConstraintGraph[VAR]) Degree(variable VAR) int {
+	return len(G.Neighbors[variable])
+}
+
+func (G *ConstraintGraph[VAR]) NumEdges() int {
+	sum := 0
+	for _, variable := range G.Variables {
+		sum += G.Degree(variable)
+	}
+	return sum / 2
+}
+
+// ConnectedComponents groups the variables into sets that share no constraints with each other.
+func (G *ConstraintGraph[VAR]) ConnectedComponents() [][]VAR {
+	if G.HasGlobal {
+		if len(G.Variables) == 0 {
+			return [][]VAR{}
+		}
+		return [][]VAR{G.Variables}
+	}
+	visited := map[VAR]bool{}
+	ret := [][]VAR{}
+	for _, start := range G.Variables {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		component := []VAR{start}
+		for i := 0; i < len(component); i++ {
+			for _, neighbor := range G.Neighbors[component[i]] {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					component = append(component, neighbor)
+				}
+			}
+		}
+		ret = append(ret, component)
+	}
+	return ret
+}
@@ -0,0 +1,769 @@
+package gointel
+
+import (
+	"context"
+	"sort"
+)
+
+// IntCSP is a CSP[int, int] specialization for dense integer models. Variables are indexed densely, domains are
+// bitsets over the range of values, and constraints implementing IntBitsetConstraint prune those bitsets directly
+// during a forward checking search. The built-in all-different and count constraints are converted automatically,
+// any other constraint is checked through the generic interfaces.
+type IntCSP struct {
+	Preprocessors []CSPPreprocessor[int, int]
+	cspConstraints[int, int]
+	variables   []int
+	index       map[int]int
+	offset      int
+	width       int
+	domains     []Bitset
+	propagators map[int][]IntBitsetConstraint
+	generic     map[int][]LocalConstraint[int, int]
+	globals     []GlobalConstraint[int, int]
+	compiled    *intCSPCompiled
+}
+
+// IntBitsetConstraint is implemented by constraints that prune IntCSP's bitset domains directly.
+type IntBitsetConstraint interface {
+	LocalConstraint[int, int]
+	// Propagate is called once the variable is fixed and returns false when a domain is wiped out.
+	Propagate(state *IntDomainState, variable int) bool
+}
+
+// intCSPCompiled holds the constraint lookups indexed densely for the search.
+type intCSPCompiled struct {
+	propagators    [][]IntBitsetConstraint
+	generic        [][]LocalConstraint[int, int]
+	genericPartner [][]int
+	hasGeneric     bool
+}
+
+func NewIntCSP(domainMap map[int][]int, preprocessors ...CSPPreprocessor[int, int]) *IntCSP {
+	ret := &IntCSP{
+		Preprocessors:  preprocessors,
+		cspConstraints: newCSPConstraints[int, int](),
+		propagators:    map[int][]IntBitsetConstraint{},
+		generic:        map[int][]LocalConstraint[int, int]{},
+		globals:        []GlobalConstraint[int, int]{},
+	}
+	ret.SetDomainMap(domainMap)
+	return ret
+}
+
+func (C *IntCSP) GetDomainMap() map[int][]int {
+	ret := make(map[int][]int, len(C.variables))
+	for i, variable := range C.variables {
+		ret[variable] = C.valuesOf(C.domains[i])
+	}
+	return ret
+}
+
+// SetDomainMap re-indexes the variables and rebuilds the bitsets over the range of all values.
+func (C *IntCSP) SetDomainMap(m map[int][]int) {
+	C.variables = make([]int, 0, len(m))
+	for variable := range m {
+		C.variables = append(C.variables, variable)
+	}
+	sort.Ints(C.variables)
+	C.index = make(map[int]int, len(C.variables))
+	minValue, maxValue := 0, -1
+	for i, variable := range C.variables {
+		C.index[variable] = i
+		for _, value := range m[variable] {
+			if maxValue < minValue {
+				minValue, maxValue = value, value
+			}
+			minValue = min(minValue, value)
+			maxValue = max(maxValue, value)
+		}
+	}
+	C.offset = minValue
+	C.width = maxValue - minValue + 1
+	C.domains = make([]Bitset, len(C.variables))
+	for i, variable := range C.variables {
+		C.domains[i] = NewBitset(C.width)
+		for _, value := range m[variable] {
+			C.domains[i].Set(value - C.offset)
+		}
+	}
+	C.compiled = nil
+}
+
+func (C *IntCSP) valuesOf(domain Bitset) []int {
+	members := domain.Members()
+	for i := range members {
+		members[i] += C.offset
+	}
+	return members
+}
+
+func (C *IntCSP) GetVariables() []int {
+	return C.variables
+}
+
+func (C *IntCSP) GetDomainForVariable(variable int) []int {
+	i, ok := C.index[variable]
+	if !ok {
+		return []int{}
+	}
+	return C.valuesOf(C.domains[i])
+}
+
+func (C *IntCSP) Contains(v int) bool {
+	_, ok := C.index[v]
+	return ok
+}
+
+func (C *IntCSP) Preprocess() {
+	_ = C.TryPreprocess()
+}
+
+// TryPreprocess is Preprocess returning ErrInconsistent when the preprocessors leave a variable without values.
+func (C *IntCSP) TryPreprocess() error {
+	return tryPreprocess[int, int](C, C.Preprocessors)
+}
+
+// AddConstraint keeps the constraint for the generic accessors and registers its bitset-aware form for the search.
+func (C *IntCSP) AddConstraint(constraint *Constraint[int, int]) error {
+	if constraint == nil {
+		return nil
+	}
+	if err := C.add(constraint, C.Contains); err != nil {
+		return err
+	}
+	C.compiled = nil
+	switch typed := (*constraint).(type) {
+	case IntBitsetConstraint:
+		C.addPropagator(typed)
+	case *LocalAllDifferentConstraint[int, int]:
+		C.addPropagator(NewIntAllDifferentConstraint(*typed.Variables))
+	case *GlobalAllDifferentConstraint[int, int]:
+		C.addPropagator(NewIntAllDifferentConstraint(C.variables))
+	case *CountConstraint[int, int]:
+		C.addPropagator(&IntCountConstraint{Variables: typed.Variables, Value: typed.Domain, MinCount: typed.MinCount, MaxCount: typed.MaxCount})
+	case *CardinalityConstraint[int, int]:
+		C.addPropagator(&IntCountConstraint{Variables: typed.Variables, Value: typed.Domain, MinCount: 0, MaxCount: typed.MaxCount})
+	default:
+		local := (*constraint).AsLocal()
+		if local == nil {
+			C.globals = append(C.globals, *constraint)
+			return nil
+		}
+		for _, variable := range (*local).GetVariables() {
+			if C.Contains(variable) {
+				C.generic[variable] = append(C.generic[variable], *local)
+			}
+		}
+	}
+	return nil
+}
+
+func (C *IntCSP) addPropagator(constraint IntBitsetConstraint) {
+	for _, variable := range constraint.GetVariables() {
+		if C.Contains(variable) {
+			C.propagators[variable] = append(C.propagators[variable], constraint)
+		}
+	}
+}
+
+func (C *IntCSP) AddAllConstraints(constraints ...*Constraint[int, int]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *IntCSP) Validate() []ValidationIssue[int, int] {
+	return C.validate(C)
+}
+
+func (C *IntCSP) compile() *intCSPCompiled {
+	if C.compiled != nil {
+		return C.compiled
+	}
+	compiled := &intCSPCompiled{
+		propagators:    make([][]IntBitsetConstraint, len(C.variables)),
+		generic:        make([][]LocalConstraint[int, int], len(C.variables)),
+		genericPartner: make([][]int, len(C.variables)),
+		hasGeneric:     len(C.globals) > 0,
+	}
+	for i, variable := range C.variables {
+		compiled.propagators[i] = C.propagators[variable]
+		compiled.generic[i] = C.generic[variable]
+		for _, constraint := range compiled.generic[i] {
+			compiled.hasGeneric = true
+			// Binary constraints are forward checked against the other variable
+			partner := -1
+			scope := []int{}
+			for _, other := range constraint.GetVariables() {
+				if C.Contains(other) && !containsInt(scope, other) {
+					scope = append(scope, other)
+				}
+			}
+			if len(scope) == 2 {
+				if scope[0] == variable {
+					partner = C.index[scope[1]]
+				} else {
+					partner = C.index[scope[0]]
+				}
+			}
+			compiled.genericPartner[i] = append(compiled.genericPartner[i], partner)
+		}
+	}
+	C.compiled = compiled
+	return compiled
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// IntDomainState is the search state bitset constraints prune: one bitset per variable and which variables are fixed.
+type IntDomainState struct {
+	csp        *IntCSP
+	domains    []Bitset
+	assigned   []bool
+	assignment map[int]int
+	queue      []int
+}
+
+func (C *IntCSP) newDomainState() *IntDomainState {
+	state := &IntDomainState{
+		csp:      C,
+		domains:  make([]Bitset, len(C.domains)),
+		assigned: make([]bool, len(C.domains)),
+	}
+	if C.compile().hasGeneric {
+		state.assignment = map[int]int{}
+	}
+	for i, domain := range C.domains {
+		state.domains[i] = domain.Clone()
+		if domain.Count() == 1 {
+			state.queue = append(state.queue, i)
+		}
+	}
+	return state
+}
+
+func (s *IntDomainState) clone() *IntDomainState {
+	ret := &IntDomainState{
+		csp:      s.csp,
+		domains:  make([]Bitset, len(s.domains)),
+		assigned: append([]bool{}, s.assigned...),
+	}
+	for i, domain := range s.domains {
+		ret.domains[i] = domain.Clone()
+	}
+	if s.assignment != nil {
+		ret.assignment = CloneMap(s.assignment)
+	}
+	return ret
+}
+
+func (s *IntDomainState) Contains(variable int, value int) bool {
+	i, ok := s.csp.index[variable]
+	return ok && s.domains[i].Has(value-s.csp.offset)
+}
+
+func (s *IntDomainState) Size(variable int) int {
+	i, ok := s.csp.index[variable]
+	if !ok {
+		return 0
+	}
+	return s.domains[i].Count()
+}
+
+// Value returns the variable's value once its domain is a single value.
+func (s *IntDomainState) Value(variable int) (int, bool) {
+	i, ok := s.csp.index[variable]
+	if !ok || s.domains[i].Count() != 1 {
+		return 0, false
+	}
+	return s.domains[i].First() + s.csp.offset, true
+}
+
+func (s *IntDomainState) Values(variable int) []int {
+	i, ok := s.csp.index[variable]
+	if !ok {
+		return []int{}
+	}
+	return s.csp.valuesOf(s.domains[i])
+}
+
+// Domain returns the variable's bitset, indexed by value minus the smallest value of the model.
+func (s *IntDomainState) Domain(variable int) Bitset {
+	i, ok := s.csp.index[variable]
+	if !ok {
+		return nil
+	}
+	return s.domains[i]
+}
+
+// Remove deletes the value from the domain, returning false when the domain becomes empty.
+func (s *IntDomainState) Remove(variable int, value int) bool {
+	i, ok := s.csp.index[variable]
+	if !ok {
+		return true
+	}
+	bit := value - s.csp.offset
+	if !s.domains[i].Has(bit) {
+		return true
+	}
+	s.domains[i].Clear(bit)
+	switch s.domains[i].Count() {
+	case 0:
+		return false
+	case 1:
+		s.queue = append(s.queue, i)
+	}
+	return true
+}
+
+// Fix reduces the domain to the value, returning false when the value is not in the domain.
+func (s *IntDomainState) Fix(variable int, value int) bool {
+	i, ok := s.csp.index[variable]
+	bit := value - s.csp.offset
+	if !ok || !s.domains[i].Has(bit) {
+		return false
+	}
+	if s.domains[i].Count() == 1 {
+		return true
+	}
+	s.domains[i] = NewBitset(s.csp.width)
+	s.domains[i].Set(bit)
+	s.queue = append(s.queue, i)
+	return true
+}
+
+// propagate runs the constraints of every newly fixed variable until nothing changes.
+func (C *IntCSP) propagate(state *IntDomainState) bool {
+	compiled := C.compile()
+	for len(state.queue) > 0 {
+		i := state.queue[len(state.queue)-1]
+		state.queue = state.queue[:len(state.queue)-1]
+		if state.assigned[i] {
+			continue
+		}
+		state.assigned[i] = true
+		variable := C.variables[i]
+		if state.assignment != nil {
+			state.assignment[variable] = state.domains[i].First() + C.offset
+		}
+		for _, propagator := range compiled.propagators[i] {
+			if !propagator.Propagate(state, variable) {
+				return false
+			}
+		}
+		for index, constraint := range compiled.generic[i] {
+			if !constraint.IsPossiblySatisfied(state.assignment) {
+				return false
+			}
+			partner := compiled.genericPartner[i][index]
+			if partner == -1 || state.assigned[partner] {
+				continue
+			}
+			other := C.variables[partner]
+			for _, value := range C.valuesOf(state.domains[partner]) {
+				state.assignment[other] = value
+				supported := constraint.IsPossiblySatisfied(state.assignment)
+				delete(state.assignment, other)
+				if !supported && !state.Remove(other, value) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (C *IntCSP) search(ctx context.Context, state *IntDomainState, yield func(map[int]int) bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	// Branch on the unfixed variable with the fewest values left
+	best, bestCount := -1, 0
+	for i, domain := range state.domains {
+		if state.assigned[i] {
+			continue
+		}
+		if count := domain.Count(); best == -1 || count < bestCount {
+			best, bestCount = i, count
+		}
+	}
+	if best == -1 {
+		solution := make(map[int]int, len(C.variables))
+		for i, variable := range C.variables {
+			solution[variable] = state.domains[i].First() + C.offset
+		}
+		for _, constraint := range C.globals {
+			if !constraint.IsSatisfied(solution) {
+				return true
+			}
+		}
+		return yield(solution)
+	}
+	for _, value := range C.valuesOf(state.domains[best]) {
+		child := state.clone()
+		if child.Fix(C.variables[best], value) && C.propagate(child) {
+			if !C.search(ctx, child, yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// solve passes every solution to yield until it returns false or ctx is done.
+// solve returns the error of TryPreprocess without searching.
+func (C *IntCSP) solve(ctx context.Context, yield func(map[int]int) bool) error {
+	if err := C.TryPreprocess(); err != nil {
+		return err
+	}
+	state := C.newDomainState()
+	if C.propagate(state) {
+		C.search(ctx, state, yield)
+	}
+	return nil
+}
+
+func (C *IntCSP) FindAllSolutions() []map[int]int {
+	solutions, _ := C.TryFindAllSolutions()
+	return solutions
+}
+
+// TryFindAllSolutions is FindAllSolutions returning the error of TryPreprocess, so an inconsistent model is told
+// apart from one whose search found nothing.
+func (C *IntCSP) TryFindAllSolutions() ([]map[int]int, error) {
+	collected := []map[int]int{}
+	err := C.solve(context.Background(), func(solution map[int]int) bool {
+		collected = append(collected, solution)
+		return true
+	})
+	return collected, err
+}
+
+func (C *IntCSP) FindOneSolution() map[int]int {
+	return C.FindOneSolutionContext(context.Background())
+}
+
+func (C *IntCSP) FindOneSolutionContext(ctx context.Context) map[int]int {
+	solution, _ := C.TryFindOneSolutionContext(ctx)
+	return solution
+}
+
+// TryFindOneSolution is FindOneSolution returning the error of TryPreprocess.
+func (C *IntCSP) TryFindOneSolution() (map[int]int, error) {
+	return C.TryFindOneSolutionContext(context.Background())
+}
+
+func (C *IntCSP) TryFindOneSolutionContext(ctx context.Context) (map[int]int, error) {
+	var ret map[int]int = nil
+	err := C.solve(ctx, func(solution map[int]int) bool {
+		ret = solution
+		return false
+	})
+	return ret, err
+}
+
+func (C *IntCSP) GenerateSolutionChannel() chan map[int]int {
+	return C.GenerateSolutionChannelContext(context.Background())
+}
+
+// GenerateSolutionChannelContext stops the search and closes the channel once ctx is done.
+func (C *IntCSP) GenerateSolutionChannelContext(ctx context.Context) chan map[int]int {
+	ch := make(chan map[int]int)
+	go func() {
+		defer close(ch)
+		C.solve(ctx, func(solution map[int]int) bool {
+			select {
+			case ch <- solution:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
+
+func (C *IntCSP) GetSeeds() *map[int]int {
+	return nil
+}
+
+// IntAllDifferentConstraint <editor-fold>
+
+// IntAllDifferentConstraint is the bitset-aware form of LocalAllDifferentConstraint.
+type IntAllDifferentConstraint struct {
+	Variables []int
+}
+
+func NewIntAllDifferentConstraint(variables []int) *IntAllDifferentConstraint {
+	return &IntAllDifferentConstraint{Variables: variables}
+}
+
+func (c *IntAllDifferentConstraint) IsPossiblySatisfied(assignment map[int]int) bool {
+	seen := map[int]bool{}
+	for _, variable := range c.Variables {
+		if value, ok := assignment[variable]; ok {
+			if seen[value] {
+				return false
+			}
+			seen[value] = true
+		}
+	}
+	return true
+}
+
+func (c *IntAllDifferentConstraint) GetVariables() []int {
+	return c.Variables
+}
+
+func (c *IntAllDifferentConstraint) IsSatisfied(assignment map[int]int) bool {
+	return c.IsPossiblySatisfied(assignment)
+}
+
+func (c *IntAllDifferentConstraint) AsLocal() *LocalConstraint[int, int] {
+	var localConstraint LocalConstraint[int, int] = c
+	return &localConstraint
+}
+
+func (c *IntAllDifferentConstraint) IsReusable() bool {
+	return false
+}
+
+func (c *IntAllDifferentConstraint) ReduceDomain(variable int, assignment map[int]int, domain []int) []int {
+	if !containsInt(c.Variables, variable) {
+		return domain
+	}
+	used := map[int]bool{}
+	for _, other := range c.Variables {
+		if value, ok := assignment[other]; ok && other != variable {
+			used[value] = true
+		}
+	}
+	ret := []int{}
+	for _, value := range domain {
+		if !used[value] {
+			ret = append(ret, value)
+		}
+	}
+	return ret
+}
+
+// Propagate removes the fixed value from the other variables and fails when they have fewer values left than variables.
+func (c *IntAllDifferentConstraint) Propagate(state *IntDomainState, variable int) bool {
+	value, _ := state.Value(variable)
+	for _, other := range c.Variables {
+		if other != variable && !state.Remove(other, value) {
+			return false
+		}
+	}
+	// Only the variables of the CSP need values of their own
+	union := NewBitset(state.csp.width)
+	contained := 0
+	for _, other := range c.Variables {
+		if domain := state.Domain(other); domain != nil {
+			union.Union(domain)
+			contained++
+		}
+	}
+	return union.Count() >= contained
+}
+
+// </editor-fold>
+
+// IntCountConstraint <editor-fold>
+
+// IntCountConstraint is the bitset-aware form of CountConstraint.
+type IntCountConstraint struct {
+	Variables []int
+	Value     int
+	MinCount  int
+	MaxCount  int
+}
+
+func (c *IntCountConstraint) asCount() *CountConstraint[int, int] {
+	return &CountConstraint[int, int]{Variables: c.Variables, Domain: c.Value, MinCount: c.MinCount, MaxCount: c.MaxCount}
+}
+
+func (c *IntCountConstraint) IsPossiblySatisfied(assignment map[int]int) bool {
+	return c.asCount().IsPossiblySatisfied(assignment)
+}
+
+func (c *IntCountConstraint) GetVariables() []int {
+	return c.Variables
+}
+
+func (c *IntCountConstraint) IsSatisfied(assignment map[int]int) bool {
+	return c.IsPossiblySatisfied(assignment)
+}
+
+func (c *IntCountConstraint) AsLocal() *LocalConstraint[int, int] {
+	var localConstraint LocalConstraint[int, int] = c
+	return &localConstraint
+}
+
+func (c *IntCountConstraint) IsReusable() bool {
+	return false
+}
+
+func (c *IntCountConstraint) ReduceDomain(variable int, assignment map[int]int, domain []int) []int {
+	return c.asCount().ReduceDomain(variable, assignment, domain)
+}
+
+// Propagate compares the fixed and possible occurrences of the value against the bounds.
+func (c *IntCountConstraint) Propagate(state *IntDomainState, variable int) bool {
+	fixed, possible := 0, 0
+	for _, other := range c.Variables {
+		if state.Contains(other, c.Value) {
+			possible++
+			if state.Size(other) == 1 {
+				fixed++
+			}
+		}
+	}
+	if fixed > c.MaxCount || possible < c.MinCount {
+		return false
+	}
+	for _, other := range c.Variables {
+		if !state.Contains(other, c.Value) || state.Size(other) == 1 {
+			continue
+		}
+		if fixed == c.MaxCount && !state.Remove(other, c.Value) {
+			return false
+		}
+		if possible == c.MinCount && !state.Fix(other, c.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// </editor-fold>
+
+// IntAC3Preprocessor is AC-3 over IntCSP's bitsets. Binary constraints and the pairs of every all-different constraint
+// are revised until no domain changes; other CSPs are handed to AC3Preprocessor.
+type IntAC3Preprocessor struct {
+}
+
+type intArc struct {
+	x, y       int
+	notEqual   bool
+	constraint LocalConstraint[int, int]
+}
+
+func (A *IntAC3Preprocessor) Preprocess(cspPtr *CSP[int, int]) {
+	_ = A.TryPreprocess(cspPtr)
+}
+
+// TryPreprocess returns ErrInconsistent when a domain is wiped out.
+func (A *IntAC3Preprocessor) TryPreprocess(cspPtr *CSP[int, int]) error {
+	if cspPtr == nil {
+		return nil
+	}
+	csp, ok := (*cspPtr).(*IntCSP)
+	if !ok {
+		return (&AC3Preprocessor[int, int]{}).TryPreprocess(cspPtr)
+	}
+	if !csp.arcConsistency() {
+		return ErrInconsistent
+	}
+	return nil
+}
+
+// arcConsistency returns false when a domain is wiped out.
+func (C *IntCSP) arcConsistency() bool {
+	compiled := C.compile()
+	arcs := []intArc{}
+	seen := map[IntBitsetConstraint]bool{}
+	for _, propagators := range compiled.propagators {
+		for _, propagator := range propagators {
+			allDifferent, ok := propagator.(*IntAllDifferentConstraint)
+			if !ok || seen[propagator] {
+				continue
+			}
+			seen[propagator] = true
+			for _, x := range allDifferent.Variables {
+				for _, y := range allDifferent.Variables {
+					if x != y && C.Contains(x) && C.Contains(y) {
+						arcs = append(arcs, intArc{x: C.index[x], y: C.index[y], notEqual: true})
+					}
+				}
+			}
+		}
+	}
+	for i, constraints := range compiled.generic {
+		for index, constraint := range constraints {
+			partner := compiled.genericPartner[i][index]
+			if partner != -1 {
+				arcs = append(arcs, intArc{x: i, y: partner, constraint: constraint})
+			} else if len(constraint.GetVariables()) == 1 {
+				variable := C.variables[i]
+				for _, value := range C.valuesOf(C.domains[i]) {
+					if !constraint.IsPossiblySatisfied(map[int]int{variable: value}) {
+						C.domains[i].Clear(value - C.offset)
+					}
+				}
+			}
+		}
+	}
+	incoming := make([][]int, len(C.variables))
+	for index, arc := range arcs {
+		incoming[arc.y] = append(incoming[arc.y], index)
+	}
+
+	queue := make([]int, len(arcs))
+	queued := make([]bool, len(arcs))
+	for index := range arcs {
+		queue[index] = index
+		queued[index] = true
+	}
+	for len(queue) > 0 {
+		index := queue[0]
+		queue = queue[1:]
+		queued[index] = false
+		arc := arcs[index]
+		if !C.revise(arc) {
+			continue
+		}
+		if C.domains[arc.x].IsEmpty() {
+			C.compiled = nil
+			return false
+		}
+		// Arcs pointing at x may have lost their support
+		for _, other := range incoming[arc.x] {
+			if !queued[other] && arcs[other].x != arc.y {
+				queue = append(queue, other)
+				queued[other] = true
+			}
+		}
+	}
+	return true
+}
+
+func (C *IntCSP) revise(arc intArc) bool {
+	changed := false
+	domainY := C.domains[arc.y]
+	for _, bitX := range C.domains[arc.x].Members() {
+		supported := false
+		if arc.notEqual {
+			supported = domainY.Count() > 1 || !domainY.Has(bitX)
+		} else {
+			assignment := map[int]int{C.variables[arc.x]: bitX + C.offset}
+			for _, bitY := range domainY.Members() {
+				assignment[C.variables[arc.y]] = bitY + C.offset
+				if arc.constraint.IsPossiblySatisfied(assignment) {
+					supported = true
+					break
+				}
+			}
+		}
+		if !supported {
+			C.domains[arc.x].Clear(bitX)
+			changed = true
+		}
+	}
+	return changed
+}
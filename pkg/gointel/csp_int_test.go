@@ -0,0 +1,94 @@
+package gointel
+
+import "testing"
+
+func TestIntCSP_NQueens(t *testing.T) {
+	var csp CSP[int, int] = newIntQueensCSP(8)
+	if solutions := csp.FindAllSolutions(); len(solutions) != 92 {
+		t.Errorf("expected 92 solutions, got %d", len(solutions))
+	}
+	solution := csp.FindOneSolution()
+	if solution == nil || len(solution) != 8 {
+		t.Fatalf("expected a full solution, got %v", solution)
+	}
+	for _, constraint := range csp.GetLocalConstraints()[0] {
+		if !(*constraint).IsSatisfied(solution) {
+			t.Errorf("solution %v violates a constraint", solution)
+		}
+	}
+}
+
+func TestIntCSP_LatinSquare(t *testing.T) {
+	n := 4
+	domainMap := map[int][]int{}
+	for cell := 0; cell < n*n; cell++ {
+		domainMap[cell] = []int{1, 2, 3, 4}
+	}
+	csp := NewIntCSP(domainMap)
+	for i := 0; i < n; i++ {
+		row, col := []int{}, []int{}
+		for j := 0; j < n; j++ {
+			row = append(row, i*n+j)
+			col = append(col, j*n+i)
+		}
+		var r Constraint[int, int] = &LocalAllDifferentConstraint[int, int]{Variables: &row}
+		var c Constraint[int, int] = &LocalAllDifferentConstraint[int, int]{Variables: &col}
+		csp.AddAllConstraints(&r, &c)
+	}
+	if solutions := csp.FindAllSolutions(); len(solutions) != 576 {
+		t.Errorf("expected 576 latin squares, got %d", len(solutions))
+	}
+}
+
+func TestIntCSP_AllDifferentOutsideScope(t *testing.T) {
+	// Variable 2 is not in the CSP, so two values are enough for the other two
+	csp := NewIntCSP(map[int][]int{0: {1, 2}, 1: {1, 2}})
+	var c Constraint[int, int] = &LocalAllDifferentConstraint[int, int]{Variables: &[]int{0, 1, 2}}
+	csp.AddConstraint(&c)
+	if solutions := csp.FindAllSolutions(); len(solutions) != 2 {
+		t.Errorf("expected 2 solutions, got %d", len(solutions))
+	}
+
+	bitset := NewBitset(64)
+	bitset.Set(64)
+	bitset.Set(-1)
+	if bitset.Count() != 0 {
+		t.Errorf("expected out of range bits to be ignored, got %d set", bitset.Count())
+	}
+}
+
+func TestIntCSP_Count(t *testing.T) {
+	variables := []int{0, 1, 2, 3}
+	domainMap := map[int][]int{}
+	for _, variable := range variables {
+		domainMap[variable] = []int{0, 1}
+	}
+	csp := NewIntCSP(domainMap)
+	var c Constraint[int, int] = NewExactlyConstraint(variables, 2, 1)
+	csp.AddConstraint(&c)
+	if solutions := csp.FindAllSolutions(); len(solutions) != 6 {
+		t.Errorf("expected 6 solutions, got %d", len(solutions))
+	}
+	if count := CountSolutions[int, int](csp, 4); count != 4 {
+		t.Errorf("expected the count to stop at 4, got %d", count)
+	}
+}
+
+func TestIntAC3Preprocessor(t *testing.T) {
+	domainMap := map[int][]int{0: {1}, 1: {1, 2}, 2: {1, 2, 3}}
+	csp := NewIntCSP(domainMap, &IntAC3Preprocessor{})
+	var c Constraint[int, int] = &LocalAllDifferentConstraint[int, int]{Variables: &[]int{0, 1, 2}}
+	csp.AddConstraint(&c)
+	csp.Preprocess()
+	for variable, expected := range map[int]int{0: 1, 1: 2, 2: 3} {
+		domain := csp.GetDomainForVariable(variable)
+		if len(domain) != 1 || domain[0] != expected {
+			t.Errorf("expected %d to be reduced to [%d], got %v", variable, expected, domain)
+		}
+	}
+
+	queens := newIntQueensCSP(6, &IntAC3Preprocessor{})
+	if solutions := queens.FindAllSolutions(); len(solutions) != 4 {
+		t.Errorf("expected 4 solutions for 6 queens, got %d", len(solutions))
+	}
+}
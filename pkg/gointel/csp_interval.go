@@ -0,0 +1,233 @@
+package gointel
+
+import (
+	"context"
+	"github.com/mtresnik/goutils/pkg/goutils"
+	"sort"
+)
+
+const (
+	// CSP_INTERVAL_PRECISION is the default width below which IntervalCSP stops bisecting a variable.
+	CSP_INTERVAL_PRECISION = 1e-6
+	// CSP_INTERVAL_MAX_BOXES is the default number of boxes after which FindAllSolutions stops.
+	CSP_INTERVAL_MAX_BOXES = 10000
+	// intervalContraction is the fraction of its width a variable has to lose for propagation to go on.
+	intervalContraction  = 0.01
+	intervalMaxRevisions = 1000
+)
+
+// IntervalCSP solves real-valued problems by branch and prune: the domain of a variable is a union of intervals,
+// IntervalConstraints narrow the boxes of the search with HC4 until they stop shrinking, and boxes are bisected on
+// their widest variable until every variable is narrower than Precision. Solutions are these boxes, every real
+// solution lies in one of them, though a box isn't proven to contain one. Other local constraints discard the
+// boxes they can't be satisfied in. Global constraints are only checked with IsSatisfied on the boxes narrower than
+// Precision, before they are returned, so they never discard a wider box that still holds solutions.
+type IntervalCSP struct {
+	DomainMap     map[string][]Interval
+	Preprocessors []CSPPreprocessor[string, Interval]
+	Precision     float64
+	// MaxBoxes limits the boxes FindAllSolutions returns, unlimited when 0 or less.
+	MaxBoxes int
+	cspConstraints[string, Interval]
+	variables *[]string
+}
+
+func NewIntervalCSP(domainMap map[string][]Interval, preprocessors ...CSPPreprocessor[string, Interval]) *IntervalCSP {
+	return &IntervalCSP{
+		DomainMap:      domainMap,
+		Preprocessors:  preprocessors,
+		Precision:      CSP_INTERVAL_PRECISION,
+		MaxBoxes:       CSP_INTERVAL_MAX_BOXES,
+		cspConstraints: newCSPConstraints[string, Interval](),
+	}
+}
+
+func (C *IntervalCSP) GetDomainMap() map[string][]Interval {
+	return C.DomainMap
+}
+
+func (C *IntervalCSP) SetDomainMap(m map[string][]Interval) {
+	C.DomainMap = m
+	C.variables = nil
+}
+
+// GetVariables returns the variables sorted by name, the order in which boxes are bisected on ties.
+func (C *IntervalCSP) GetVariables() []string {
+	if C.variables == nil {
+		variables := goutils.Keys(C.DomainMap)
+		sort.Strings(variables)
+		C.variables = &variables
+	}
+	return *C.variables
+}
+
+func (C *IntervalCSP) GetDomainForVariable(variable string) []Interval {
+	ret, ok := C.DomainMap[variable]
+	if !ok {
+		return []Interval{}
+	}
+	return ret
+}
+
+func (C *IntervalCSP) Contains(v string) bool {
+	_, ok := C.DomainMap[v]
+	return ok
+}
+
+func (C *IntervalCSP) Preprocess() {
+	var csp CSP[string, Interval] = C
+	for _, preprocessor := range C.Preprocessors {
+		preprocessor.Preprocess(&csp)
+	}
+}
+
+func (C *IntervalCSP) AddConstraint(constraint *Constraint[string, Interval]) error {
+	return C.add(constraint, C.Contains)
+}
+
+func (C *IntervalCSP) AddAllConstraints(constraints ...*Constraint[string, Interval]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *IntervalCSP) Validate() []ValidationIssue[string, Interval] {
+	return C.validate(C)
+}
+
+// initialBoxes returns a box per combination of the intervals of the domains.
+func (C *IntervalCSP) initialBoxes() []map[string]Interval {
+	boxes := []map[string]Interval{{}}
+	for _, variable := range C.GetVariables() {
+		next := []map[string]Interval{}
+		for _, box := range boxes {
+			for _, interval := range C.DomainMap[variable] {
+				if interval.IsEmpty() {
+					continue
+				}
+				child := copyBox(box)
+				child[variable] = interval
+				next = append(next, child)
+			}
+		}
+		boxes = next
+	}
+	return boxes
+}
+
+func copyBox(box map[string]Interval) map[string]Interval {
+	ret := make(map[string]Interval, len(box))
+	for variable, interval := range box {
+		ret[variable] = interval
+	}
+	return ret
+}
+
+// prune narrows the box with every constraint until no variable loses more than intervalContraction of its width,
+// returning false when the box holds no solution.
+func (C *IntervalCSP) prune(box map[string]Interval, constraints []*LocalConstraint[string, Interval]) bool {
+	for revision := 0; revision < intervalMaxRevisions; revision++ {
+		before := copyBox(box)
+		for _, constraint := range constraints {
+			revisable, ok := (*constraint).(*IntervalConstraint)
+			if ok && !revisable.Revise(box) {
+				return false
+			}
+			if !ok && !(*constraint).IsPossiblySatisfied(box) {
+				return false
+			}
+		}
+		contracted := false
+		for variable, interval := range box {
+			if interval.Width() < (1-intervalContraction)*before[variable].Width() {
+				contracted = true
+				break
+			}
+		}
+		if !contracted {
+			return true
+		}
+	}
+	return true
+}
+
+func (C *IntervalCSP) FindOneSolution() map[string]Interval {
+	return C.FindOneSolutionContext(context.Background())
+}
+
+// FindOneSolutionContext returns the first box narrower than Precision, or nil when ctx is done first.
+func (C *IntervalCSP) FindOneSolutionContext(ctx context.Context) map[string]Interval {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if box, ok := <-C.GenerateSolutionChannelContext(ctx); ok {
+		return box
+	}
+	return nil
+}
+
+func (C *IntervalCSP) FindAllSolutions() []map[string]Interval {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ret := []map[string]Interval{}
+	for box := range C.GenerateSolutionChannelContext(ctx) {
+		ret = append(ret, box)
+		if C.MaxBoxes > 0 && len(ret) >= C.MaxBoxes {
+			break
+		}
+	}
+	return ret
+}
+
+func (C *IntervalCSP) GenerateSolutionChannel() chan map[string]Interval {
+	return C.GenerateSolutionChannelContext(context.Background())
+}
+
+// GenerateSolutionChannelContext sends the boxes depth first, lower halves first, and closes the channel when the
+// search ends or ctx is done.
+func (C *IntervalCSP) GenerateSolutionChannelContext(ctx context.Context) chan map[string]Interval {
+	ch := make(chan map[string]Interval)
+	go func() {
+		defer close(ch)
+		C.Preprocess()
+		variables := C.GetVariables()
+		constraints := uniqueLocalConstraints(variables, C.localConstraints)
+		boxes := C.initialBoxes()
+		// Reverse so the first box is searched first
+		for i, j := 0, len(boxes)-1; i < j; i, j = i+1, j-1 {
+			boxes[i], boxes[j] = boxes[j], boxes[i]
+		}
+		for len(boxes) > 0 && ctx.Err() == nil {
+			box := boxes[len(boxes)-1]
+			boxes = boxes[:len(boxes)-1]
+			if !C.prune(box, constraints) {
+				continue
+			}
+			widest, width := "", C.Precision
+			for _, variable := range variables {
+				if box[variable].Width() > width {
+					widest, width = variable, box[variable].Width()
+				}
+			}
+			mid := box[widest].Mid()
+			if widest == "" || mid <= box[widest].Low || mid >= box[widest].High {
+				// Narrow enough, or too narrow for floats to split
+				if !IsGloballyConsistent(box, C.globalConstraints) {
+					continue
+				}
+				select {
+				case ch <- box:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			lower, upper := copyBox(box), box
+			lower[widest] = Interval{Low: box[widest].Low, High: mid}
+			upper[widest] = Interval{Low: mid, High: box[widest].High}
+			boxes = append(boxes, upper, lower)
+		}
+	}()
+	return ch
+}
+
+func (C *IntervalCSP) GetSeeds() *map[string]Interval {
+	return nil
+}
@@ -0,0 +1,116 @@
+package gointel
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestIntervalConstraint_Revise(t *testing.T) {
+	x, y := IntervalVariable("x"), IntervalVariable("y")
+	sum := NewIntervalConstraint(x.Add(y), SUM_EQ, IntervalConstant(10))
+	box := map[string]Interval{"x": NewInterval(0, 3), "y": NewInterval(0, 8)}
+	if !sum.Revise(box) {
+		t.Fatal("expected x + y = 10 to be feasible")
+	}
+	if math.Abs(box["x"].Low-2) > 1e-9 || math.Abs(box["y"].Low-7) > 1e-9 {
+		t.Errorf("expected x in [2, 3] and y in [7, 8], got %v", box)
+	}
+	square := NewIntervalConstraint(x.Sqr(), SUM_EQ, IntervalConstant(-1))
+	if square.Revise(map[string]Interval{"x": EntireInterval()}) {
+		t.Error("expected x^2 = -1 to be infeasible")
+	}
+	if reduced := square.ReduceDomain("x", map[string]Interval{}, []Interval{NewInterval(-5, 5)}); len(reduced) != 0 {
+		t.Errorf("expected an empty domain, got %v", reduced)
+	}
+}
+
+func TestIntervalCSP(t *testing.T) {
+	x, y := IntervalVariable("x"), IntervalVariable("y")
+	csp := NewIntervalCSP(map[string][]Interval{"x": {NewInterval(-2, 2)}, "y": {NewInterval(-2, 2)}})
+	var circle Constraint[string, Interval] = NewIntervalConstraint(x.Sqr().Add(y.Sqr()), SUM_EQ, IntervalConstant(1))
+	var diagonal Constraint[string, Interval] = NewIntervalConstraint(y, SUM_EQ, x)
+	csp.AddAllConstraints(&circle, &diagonal)
+
+	boxes := csp.FindAllSolutions()
+	if len(boxes) == 0 {
+		t.Fatal("expected boxes around the two intersections")
+	}
+	root := math.Sqrt(0.5)
+	signs := map[bool]bool{}
+	for _, box := range boxes {
+		if box["x"].Width() > csp.Precision || box["y"].Width() > csp.Precision {
+			t.Errorf("box %v is wider than the precision", box)
+		}
+		if math.Abs(math.Abs(box["x"].Mid())-root) > 1e-5 || math.Abs(math.Abs(box["y"].Mid())-root) > 1e-5 {
+			t.Errorf("box %v is far from the intersections", box)
+		}
+		signs[box["x"].Mid() > 0] = true
+	}
+	if len(signs) != 2 {
+		t.Errorf("expected boxes around both intersections, got %v", boxes)
+	}
+
+	var outside Constraint[string, Interval] = NewIntervalConstraint(x, SUM_GE, IntervalConstant(1))
+	csp.AddConstraint(&outside)
+	if box := csp.FindOneSolution(); box != nil {
+		t.Errorf("expected no solution with x >= 1, got %v", box)
+	}
+}
+
+func TestInterval_DivEnclosesQuotient(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for n := 0; n < 10000; n++ {
+		a, b := random.Float64()*1000+1e-3, random.Float64()*1000+1e-3
+		quotient := NewInterval(a, a).div(NewInterval(b, b))
+		exact := new(big.Rat).Quo(new(big.Rat).SetFloat64(a), new(big.Rat).SetFloat64(b))
+		if exact.Cmp(new(big.Rat).SetFloat64(quotient.Low)) < 0 || exact.Cmp(new(big.Rat).SetFloat64(quotient.High)) > 0 {
+			t.Fatalf("expected %v / %v in %v", a, b, quotient)
+		}
+	}
+}
+
+// positiveMidConstraint accepts the boxes whose x is centered above 0, which wide boxes around 0 are not.
+type positiveMidConstraint struct{}
+
+func (c *positiveMidConstraint) IsPossiblySatisfied(box map[string]Interval) bool {
+	return true
+}
+
+func (c *positiveMidConstraint) GetVariables() []string {
+	return []string{}
+}
+
+func (c *positiveMidConstraint) IsSatisfied(box map[string]Interval) bool {
+	return box["x"].Mid() > 0
+}
+
+func (c *positiveMidConstraint) AsLocal() *LocalConstraint[string, Interval] {
+	return nil
+}
+
+func (c *positiveMidConstraint) IsReusable() bool {
+	return false
+}
+
+func (c *positiveMidConstraint) ReduceDomain(variable string, box map[string]Interval, domain []Interval) []Interval {
+	return domain
+}
+
+func TestIntervalCSP_GlobalConstraint(t *testing.T) {
+	x := IntervalVariable("x")
+	csp := NewIntervalCSP(map[string][]Interval{"x": {NewInterval(-2, 2)}})
+	var square Constraint[string, Interval] = NewIntervalConstraint(x.Sqr(), SUM_EQ, IntervalConstant(1))
+	var positive Constraint[string, Interval] = &positiveMidConstraint{}
+	csp.AddAllConstraints(&square, &positive)
+	boxes := csp.FindAllSolutions()
+	if len(boxes) == 0 {
+		t.Fatal("expected the global constraint to keep the boxes around x = 1")
+	}
+	for _, box := range boxes {
+		if math.Abs(box["x"].Mid()-1) > 1e-5 {
+			t.Errorf("expected only boxes around x = 1, got %v", box)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package gointel
+
+import (
+	"context"
+	"github.com/mtresnik/goutils/pkg/goutils"
+	"math/rand"
+)
+
+const CSP_MIN_CONFLICTS_MAX_STEPS = 100000
+
+// MinConflictsCSP is a local search solver: it starts from a random complete assignment and repeatedly moves a
+// conflicted variable to the value that violates the fewest constraints. It is incomplete, so a nil result does not
+// prove that the problem has no solution, and FindAllSolutions returns at most the one solution it finds.
+type MinConflictsCSP[VAR comparable, DOMAIN comparable] struct {
+	DomainMap     map[VAR][]DOMAIN
+	Preprocessors []CSPPreprocessor[VAR, DOMAIN]
+	MaxSteps      int
+	// Noise is the probability of moving to a random value instead of the best one.
+	Noise float64
+	cspConstraints[VAR, DOMAIN]
+	variables *[]VAR
+}
+
+func NewMinConflictsCSP[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *MinConflictsCSP[VAR, DOMAIN] {
+	return &MinConflictsCSP[VAR, DOMAIN]{
+		DomainMap:      domainMap,
+		Preprocessors:  preprocessors,
+		MaxSteps:       CSP_MIN_CONFLICTS_MAX_STEPS,
+		Noise:          0.05,
+		cspConstraints: newCSPConstraints[VAR, DOMAIN](),
+	}
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) GetDomainMap() map[VAR][]DOMAIN {
+	return C.DomainMap
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) SetDomainMap(m map[VAR][]DOMAIN) {
+	C.DomainMap = m
+	C.variables = nil
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) GetVariables() []VAR {
+	if C.variables == nil {
+		variables := goutils.Keys(C.DomainMap)
+		C.variables = &variables
+	}
+	return *C.variables
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) GetDomainForVariable(variable VAR) []DOMAIN {
+	ret, ok := C.DomainMap[variable]
+	if !ok {
+		return []DOMAIN{}
+	}
+	return ret
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) Contains(v VAR) bool {
+	_, ok := C.DomainMap[v]
+	return ok
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) Preprocess() {
+	var csp CSP[VAR, DOMAIN] = C
+	for _, preprocessor := range C.Preprocessors {
+		preprocessor.Preprocess(&csp)
+	}
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
+	return C.add(constraint, C.Contains)
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
+}
+
+// conflicts counts the constraints on the variable that the complete assignment violates.
+func (C *MinConflictsCSP[VAR, DOMAIN]) conflicts(variable VAR, assignment map[VAR]DOMAIN) int {
+	count := 0
+	for _, constraint := range C.localConstraints[variable] {
+		if !(*constraint).IsPossiblySatisfied(assignment) {
+			count++
+		}
+	}
+	for _, constraint := range C.globalConstraints {
+		if !(*constraint).IsSatisfied(assignment) {
+			count++
+		}
+	}
+	return count
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) conflictedVariables(variables []VAR, assignment map[VAR]DOMAIN) []VAR {
+	for _, constraint := range C.globalConstraints {
+		if !(*constraint).IsSatisfied(assignment) {
+			return variables
+		}
+	}
+	conflicted := map[VAR]bool{}
+	for _, constraint := range uniqueLocalConstraints(variables, C.localConstraints) {
+		if !(*constraint).IsPossiblySatisfied(assignment) {
+			for _, variable := range (*constraint).GetVariables() {
+				if C.Contains(variable) {
+					conflicted[variable] = true
+				}
+			}
+		}
+	}
+	return goutils.Filter(variables, func(variable VAR) bool {
+		return conflicted[variable]
+	})
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	return C.FindOneSolutionContext(context.Background())
+}
+
+// FindOneSolutionContext returns nil when ctx is done or MaxSteps moves did not remove every conflict.
+func (C *MinConflictsCSP[VAR, DOMAIN]) FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN {
+	C.Preprocess()
+	variables := C.GetVariables()
+	assignment := map[VAR]DOMAIN{}
+	for _, variable := range variables {
+		domain := C.DomainMap[variable]
+		if len(domain) == 0 {
+			return nil
+		}
+		assignment[variable] = domain[rand.Intn(len(domain))]
+	}
+
+	for step := 0; step < C.MaxSteps && ctx.Err() == nil; step++ {
+		conflicted := C.conflictedVariables(variables, assignment)
+		if len(conflicted) == 0 {
+			return assignment
+		}
+		variable := conflicted[rand.Intn(len(conflicted))]
+		domain := C.DomainMap[variable]
+		if rand.Float64() < C.Noise {
+			assignment[variable] = domain[rand.Intn(len(domain))]
+			continue
+		}
+		best := []DOMAIN{}
+		bestConflicts := -1
+		for _, value := range domain {
+			assignment[variable] = value
+			count := C.conflicts(variable, assignment)
+			if bestConflicts == -1 || count < bestConflicts {
+				best = []DOMAIN{value}
+				bestConflicts = count
+			} else if count == bestConflicts {
+				best = append(best, value)
+			}
+		}
+		assignment[variable] = best[rand.Intn(len(best))]
+	}
+	return nil
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
+	solution := C.FindOneSolution()
+	if solution == nil {
+		return []map[VAR]DOMAIN{}
+	}
+	return []map[VAR]DOMAIN{solution}
+}
+
+func (C *MinConflictsCSP[VAR, DOMAIN]) GetSeeds() *map[VAR]DOMAIN {
+	return nil
+}
@@ -0,0 +1,19 @@
+package gointel
+
+import "testing"
+
+func TestMinConflictsCSP_NQueens(t *testing.T) {
+	n := 12
+	domainMap, constraints := queensModel(n)
+	csp := NewMinConflictsCSP(domainMap)
+	csp.AddAllConstraints(constraints...)
+	solution := csp.FindOneSolution()
+	if len(solution) != n {
+		t.Fatalf("expected a complete assignment, got %v", solution)
+	}
+	for _, constraint := range constraints {
+		if !(*constraint).IsSatisfied(solution) {
+			t.Errorf("queens %v attack each other in %v", *constraint, solution)
+		}
+	}
+}
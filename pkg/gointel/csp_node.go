@@ -1,39 +1,80 @@
 package gointel
 
+// CSPNode is one assignment in the search tree. Nodes only store their own variable and a pointer to their parent,
+// so creating one is O(1); the full assignment is the chain of nodes up to the root.
 type CSPNode[VAR comparable, DOMAIN comparable] struct {
 	Variable      VAR
 	VariableIndex int
 	Domain        DOMAIN
 	Parent        *CSPNode[VAR, DOMAIN]
-	cachedMap     *map[VAR]DOMAIN
+	Depth         int
 	LegalValues   []DOMAIN
+	// Priority is the score of the agent's NodePriority, set when the node is queued.
+	Priority float64
 }
 
 func NewCSPNode[VAR comparable, DOMAIN comparable](variable VAR, variableIndex int, domain DOMAIN, legalValues []DOMAIN, optionalParent ...*CSPNode[VAR, DOMAIN]) *CSPNode[VAR, DOMAIN] {
 	var parent *CSPNode[VAR, DOMAIN] = nil
-	if len(optionalParent) > 0 {
+	depth := 1
+	if len(optionalParent) > 0 && optionalParent[0] != nil {
 		parent = optionalParent[0]
+		depth = parent.Depth + 1
 	}
-	newMap := make(map[VAR]DOMAIN)
-	if parent != nil {
-		// Copy assignment from parent map
-		for k, v := range parent.GetMap() {
-			newMap[k] = v
-		}
-	}
-	// Add the current variable assignment
-	newMap[variable] = domain
 	return &CSPNode[VAR, DOMAIN]{
 		Variable:      variable,
 		VariableIndex: variableIndex,
 		Domain:        domain,
 		Parent:        parent,
-		cachedMap:     &newMap,
+		Depth:         depth,
 		LegalValues:   legalValues,
 	}
-
 }
 
+// GetMap materializes the node's assignment by walking up to the root. Searches should use a cspTrail instead.
 func (node *CSPNode[VAR, DOMAIN]) GetMap() map[VAR]DOMAIN {
-	return *node.cachedMap
+	ret := make(map[VAR]DOMAIN, node.Depth)
+	for current := node; current != nil; current = current.Parent {
+		ret[current.Variable] = current.Domain
+	}
+	return ret
+}
+
+// cspTrail keeps the assignment of the node being expanded in a single map. Moving to another node undoes the
+// assignments below their common ancestor and replays the rest, instead of copying a map for every node.
+type cspTrail[VAR comparable, DOMAIN comparable] struct {
+	assignment map[VAR]DOMAIN
+	path       []*CSPNode[VAR, DOMAIN]
+}
+
+func newCSPTrail[VAR comparable, DOMAIN comparable]() *cspTrail[VAR, DOMAIN] {
+	return &cspTrail[VAR, DOMAIN]{
+		assignment: map[VAR]DOMAIN{},
+		path:       []*CSPNode[VAR, DOMAIN]{},
+	}
+}
+
+// moveTo returns the trail's assignment for the node. The map is reused by the next call, so callers must copy it to keep it.
+func (t *cspTrail[VAR, DOMAIN]) moveTo(node *CSPNode[VAR, DOMAIN]) map[VAR]DOMAIN {
+	chain := []*CSPNode[VAR, DOMAIN]{}
+	current := node
+	for current != nil {
+		if current.Depth <= len(t.path) && t.path[current.Depth-1] == current {
+			break
+		}
+		chain = append(chain, current)
+		current = current.Parent
+	}
+	keep := 0
+	if current != nil {
+		keep = current.Depth
+	}
+	for i := len(t.path) - 1; i >= keep; i-- {
+		delete(t.assignment, t.path[i].Variable)
+	}
+	t.path = t.path[:keep]
+	for i := len(chain) - 1; i >= 0; i-- {
+		t.assignment[chain[i].Variable] = chain[i].Domain
+		t.path = append(t.path, chain[i])
+	}
+	return t.assignment
 }
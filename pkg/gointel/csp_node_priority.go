@@ -0,0 +1,96 @@
+package gointel
+
+import "sort"
+
+// CSPNodePriority scores a queued node given its assignment, nodes with lower scores are expanded first. The
+// assignment is only valid during the call.
+type CSPNodePriority[VAR comparable, DOMAIN comparable] func(node *CSPNode[VAR, DOMAIN], assignment map[VAR]DOMAIN) float64
+
+// LegalValuesPriority prefers the nodes whose variable had the fewest legal values, the heap's default order.
+func LegalValuesPriority[VAR comparable, DOMAIN comparable]() CSPNodePriority[VAR, DOMAIN] {
+	return func(node *CSPNode[VAR, DOMAIN], _ map[VAR]DOMAIN) float64 {
+		return float64(len(node.LegalValues))
+	}
+}
+
+// DepthPriority prefers the deepest nodes, which makes the heap search depth first.
+func DepthPriority[VAR comparable, DOMAIN comparable]() CSPNodePriority[VAR, DOMAIN] {
+	return func(node *CSPNode[VAR, DOMAIN], _ map[VAR]DOMAIN) float64 {
+		return -float64(node.Depth)
+	}
+}
+
+// HeuristicPriority sums the scores the constraints' Evaluate gives the node's partial assignment, so the heap runs a
+// greedy best-first search.
+func HeuristicPriority[VAR comparable, DOMAIN comparable](constraints ...LocalHeuristicConstraint[VAR, DOMAIN]) CSPNodePriority[VAR, DOMAIN] {
+	return func(_ *CSPNode[VAR, DOMAIN], assignment map[VAR]DOMAIN) float64 {
+		score := 0.0
+		for _, constraint := range constraints {
+			score += constraint.Evaluate(assignment)
+		}
+		return score
+	}
+}
+
+// SumPriorities adds the weighted scores of the priorities, e.g. a cost of the assignment so far and a heuristic of
+// the remaining cost for an A*-like search.
+func SumPriorities[VAR comparable, DOMAIN comparable](weights []float64, priorities ...CSPNodePriority[VAR, DOMAIN]) CSPNodePriority[VAR, DOMAIN] {
+	return func(node *CSPNode[VAR, DOMAIN], assignment map[VAR]DOMAIN) float64 {
+		score := 0.0
+		for i, priority := range priorities {
+			weight := 1.0
+			if i < len(weights) {
+				weight = weights[i]
+			}
+			score += weight * priority(node, assignment)
+		}
+		return score
+	}
+}
+
+// HeuristicConstraints returns the agent's local constraints that implement LocalHeuristicConstraint.
+func (C *CSPAgent[VAR, DOMAIN]) HeuristicConstraints() []LocalHeuristicConstraint[VAR, DOMAIN] {
+	ret := []LocalHeuristicConstraint[VAR, DOMAIN]{}
+	for _, constraint := range uniqueLocalConstraints(C.SortedVariables, C.localConstraints) {
+		if heuristic, ok := (*constraint).(LocalHeuristicConstraint[VAR, DOMAIN]); ok {
+			ret = append(ret, heuristic)
+		}
+	}
+	return ret
+}
+
+// prioritize scores the nodes with NodePriority. The assignment is their parent's, nil for nodes to score from
+// their own chain.
+func (C *CSPAgent[VAR, DOMAIN]) prioritize(nodes []CSPNode[VAR, DOMAIN], assignment map[VAR]DOMAIN) []CSPNode[VAR, DOMAIN] {
+	if C.NodePriority == nil {
+		return nodes
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		if assignment == nil {
+			node.Priority = C.NodePriority(node, node.GetMap())
+			continue
+		}
+		previous, assigned := assignment[node.Variable]
+		assignment[node.Variable] = node.Domain
+		node.Priority = C.NodePriority(node, assignment)
+		if assigned {
+			assignment[node.Variable] = previous
+		} else {
+			delete(assignment, node.Variable)
+		}
+	}
+	return nodes
+}
+
+// order sorts siblings by priority, keeping their heuristic order on ties.
+func (C *CSPAgent[VAR, DOMAIN]) order(nodes []CSPNode[VAR, DOMAIN], assignment map[VAR]DOMAIN) []CSPNode[VAR, DOMAIN] {
+	if C.NodePriority == nil {
+		return nodes
+	}
+	nodes = C.prioritize(nodes, assignment)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Priority < nodes[j].Priority
+	})
+	return nodes
+}
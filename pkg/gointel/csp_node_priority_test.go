@@ -0,0 +1,64 @@
+package gointel
+
+import (
+	"fmt"
+	"testing"
+)
+
+// distanceConstraint scores an assignment by its distance to Target and accepts every assignment.
+type distanceConstraint struct {
+	Target map[string]int
+}
+
+func (d *distanceConstraint) Evaluate(assignment map[string]int) float64 {
+	score := 0.0
+	for variable, value := range assignment {
+		score += float64(intAbs(value - d.Target[variable]))
+	}
+	return score
+}
+
+func (d *distanceConstraint) IsPossiblySatisfied(map[string]int) bool { return true }
+func (d *distanceConstraint) IsSatisfied(map[string]int) bool         { return true }
+func (d *distanceConstraint) IsReusable() bool                        { return false }
+func (d *distanceConstraint) GetVariables() []string                  { return []string{"a", "b", "c", "d"} }
+
+func (d *distanceConstraint) AsLocal() *LocalConstraint[string, int] {
+	var local LocalConstraint[string, int] = d
+	return &local
+}
+
+func (d *distanceConstraint) ReduceDomain(_ string, _ map[string]int, domain []int) []int {
+	return domain
+}
+
+func TestCSPAgent_NodePriority(t *testing.T) {
+	agent := newQueensAgent(8)
+	agent.NodePriority = DepthPriority[int, int]()
+	if solutions := agent.FindAllSolutions(); len(solutions) != 92 {
+		t.Errorf("expected 92 solutions depth first, got %d", len(solutions))
+	}
+
+	target := map[string]int{"a": 7, "b": 2, "c": 9, "d": 4}
+	domainMap := map[string][]int{}
+	for variable := range target {
+		domainMap[variable] = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	}
+	for _, strategy := range []SearchStrategy{CSP_SEARCH_HEAP, CSP_SEARCH_DFS, CSP_SEARCH_LDS} {
+		tree := NewCSPTree(domainMap)
+		var c Constraint[string, int] = &distanceConstraint{Target: target}
+		tree.AddConstraint(&c)
+		tree.SearchStrategy = strategy
+		agent := tree.constructAgent()
+		agent.NodePriority = HeuristicPriority(agent.HeuristicConstraints()...)
+		if solution := agent.FindOneSolution(); fmt.Sprint(solution) != fmt.Sprint(target) {
+			t.Errorf("%v: expected the best-first search to reach %v first, got %v", strategy, target, solution)
+		}
+	}
+
+	weighted := SumPriorities([]float64{2}, DepthPriority[int, int](), LegalValuesPriority[int, int]())
+	node := NewCSPNode(0, 0, 0, []int{1, 2, 3})
+	if score := weighted(node, map[int]int{0: 0}); score != 1 {
+		t.Errorf("expected 2×-1 + 3 = 1, got %v", score)
+	}
+}
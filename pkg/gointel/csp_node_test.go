@@ -0,0 +1,53 @@
+package gointel
+
+import "testing"
+
+func TestCSPNode_ParentChain(t *testing.T) {
+	root := NewCSPNode("A", 0, 1, nil)
+	child := NewCSPNode("B", 1, 2, nil, root)
+	grandchild := NewCSPNode("C", 2, 3, nil, child)
+	if grandchild.Depth != 3 {
+		t.Errorf("expected depth 3, got %d", grandchild.Depth)
+	}
+	assignment := grandchild.GetMap()
+	if len(assignment) != 3 || assignment["A"] != 1 || assignment["B"] != 2 || assignment["C"] != 3 {
+		t.Errorf("unexpected assignment %v", assignment)
+	}
+}
+
+func TestCSPTrail_MoveTo(t *testing.T) {
+	root := NewCSPNode("A", 0, 1, nil)
+	left := NewCSPNode("B", 1, 2, nil, root)
+	leftLeaf := NewCSPNode("C", 2, 3, nil, left)
+	right := NewCSPNode("B", 1, 5, nil, root)
+	rightLeaf := NewCSPNode("D", 2, 6, nil, right)
+
+	trail := newCSPTrail[string, int]()
+	for _, node := range []*CSPNode[string, int]{leftLeaf, rightLeaf, left, root, leftLeaf} {
+		got := trail.moveTo(node)
+		want := node.GetMap()
+		if assignmentKey(got) != assignmentKey(want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestNewCSPNode_Allocations(t *testing.T) {
+	var deep *CSPNode[int, int]
+	for depth := 0; depth < 1000; depth++ {
+		deep = NewCSPNode(depth, depth, depth, nil, deep)
+	}
+	// A child costs the same single allocation at any depth
+	if allocations := testing.AllocsPerRun(100, func() { NewCSPNode(1000, 1000, 0, nil, deep) }); allocations > 1 {
+		t.Errorf("expected one allocation per node, got %v", allocations)
+	}
+}
+
+func BenchmarkCSPAgent_NQueens(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if solutions := newQueensAgent(8).FindAllSolutions(); len(solutions) != 92 {
+			b.Fatalf("expected 92 solutions, got %d", len(solutions))
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package gointel
+
+import (
+	"context"
+)
+
+type PortfolioStrategy[VAR comparable, DOMAIN comparable] struct {
+	Name string
+	// Complete strategies prove unsatisfiability by finishing without a solution.
+	Complete bool
+	New      func(domainMap map[VAR][]DOMAIN) CSP[VAR, DOMAIN]
+}
+
+type PortfolioResult[VAR comparable, DOMAIN comparable] struct {
+	Solution      map[VAR]DOMAIN
+	Unsatisfiable bool
+	// Strategy is the name of the strategy that decided the result, empty when none did.
+	Strategy string
+}
+
+// PortfolioSolver races several solver configurations on the same model and keeps the first decisive answer.
+type PortfolioSolver[VAR comparable, DOMAIN comparable] struct {
+	DomainMap   map[VAR][]DOMAIN
+	Constraints []*Constraint[VAR, DOMAIN]
+	Strategies  []PortfolioStrategy[VAR, DOMAIN]
+}
+
+// DefaultPortfolioStrategies races a CSPTree, a CSPDomain with AC-3 and min-conflicts local search.
+func DefaultPortfolioStrategies[VAR comparable, DOMAIN comparable]() []PortfolioStrategy[VAR, DOMAIN] {
+	return []PortfolioStrategy[VAR, DOMAIN]{
+		{
+			Name:     "tree",
+			Complete: true,
+			New: func(domainMap map[VAR][]DOMAIN) CSP[VAR, DOMAIN] {
+				return NewCSPTree(domainMap)
+			},
+		},
+		{
+			Name:     "domain-ac3",
+			Complete: true,
+			New: func(domainMap map[VAR][]DOMAIN) CSP[VAR, DOMAIN] {
+				return NewCSPDomain[VAR, DOMAIN](domainMap, &AC3Preprocessor[VAR, DOMAIN]{})
+			},
+		},
+		{
+			Name:     "min-conflicts",
+			Complete: false,
+			New: func(domainMap map[VAR][]DOMAIN) CSP[VAR, DOMAIN] {
+				return NewMinConflictsCSP(domainMap)
+			},
+		},
+	}
+}
+
+func NewPortfolioSolver[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, constraints []*Constraint[VAR, DOMAIN], strategies ...PortfolioStrategy[VAR, DOMAIN]) *PortfolioSolver[VAR, DOMAIN] {
+	if len(strategies) == 0 {
+		strategies = DefaultPortfolioStrategies[VAR, DOMAIN]()
+	}
+	return &PortfolioSolver[VAR, DOMAIN]{
+		DomainMap:   domainMap,
+		Constraints: constraints,
+		Strategies:  strategies,
+	}
+}
+
+type portfolioOutcome[VAR comparable, DOMAIN comparable] struct {
+	solution  map[VAR]DOMAIN
+	strategy  PortfolioStrategy[VAR, DOMAIN]
+	cancelled bool
+	err       error
+}
+
+// Solve returns the first solution or proof of unsatisfiability and cancels the remaining strategies.
+// Strategies that do not implement CancellableCSP are abandoned rather than stopped, and strategies whose CSP
+// rejects a constraint decide nothing.
+func (P *PortfolioSolver[VAR, DOMAIN]) Solve(ctx context.Context) PortfolioResult[VAR, DOMAIN] {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make(chan portfolioOutcome[VAR, DOMAIN], len(P.Strategies))
+	for _, strategy := range P.Strategies {
+		csp := strategy.New(CloneMapWithSlices(P.DomainMap))
+		if err := csp.AddAllConstraints(P.Constraints...); err != nil {
+			outcomes <- portfolioOutcome[VAR, DOMAIN]{strategy: strategy, err: err}
+			continue
+		}
+		go func(strategy PortfolioStrategy[VAR, DOMAIN], csp CSP[VAR, DOMAIN]) {
+			var solution map[VAR]DOMAIN
+			if cancellable, ok := csp.(CancellableCSP[VAR, DOMAIN]); ok {
+				solution = cancellable.FindOneSolutionContext(ctx)
+			} else {
+				solution = csp.FindOneSolution()
+			}
+			outcomes <- portfolioOutcome[VAR, DOMAIN]{
+				solution:  solution,
+				strategy:  strategy,
+				cancelled: ctx.Err() != nil,
+			}
+		}(strategy, csp)
+	}
+
+	for range P.Strategies {
+		select {
+		case <-ctx.Done():
+			return PortfolioResult[VAR, DOMAIN]{}
+		case outcome := <-outcomes:
+			if outcome.solution != nil {
+				return PortfolioResult[VAR, DOMAIN]{Solution: outcome.solution, Strategy: outcome.strategy.Name}
+			}
+			if outcome.strategy.Complete && !outcome.cancelled && outcome.err == nil {
+				return PortfolioResult[VAR, DOMAIN]{Unsatisfiable: true, Strategy: outcome.strategy.Name}
+			}
+		}
+	}
+	return PortfolioResult[VAR, DOMAIN]{}
+}
+
+func (P *PortfolioSolver[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	return P.Solve(context.Background()).Solution
+}
@@ -0,0 +1,46 @@
+package gointel
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPortfolioSolver_Solution(t *testing.T) {
+	domainMap, constraints := queensModel(8)
+	result := NewPortfolioSolver(domainMap, constraints).Solve(context.Background())
+	if result.Unsatisfiable || len(result.Solution) != 8 {
+		t.Fatalf("expected a solution, got %+v", result)
+	}
+	if result.Strategy == "" {
+		t.Errorf("expected the winning strategy to be reported")
+	}
+	for _, constraint := range constraints {
+		if !(*constraint).IsSatisfied(result.Solution) {
+			t.Errorf("solution %v violates a constraint", result.Solution)
+		}
+	}
+}
+
+func TestPortfolioSolver_Unsatisfiable(t *testing.T) {
+	domainMap, constraints := queensModel(3)
+	result := NewPortfolioSolver(domainMap, constraints).Solve(context.Background())
+	if !result.Unsatisfiable || result.Solution != nil {
+		t.Fatalf("expected a proof of unsatisfiability, got %+v", result)
+	}
+}
+
+func TestPortfolioSolver_IncompleteOnly(t *testing.T) {
+	domainMap, constraints := queensModel(3)
+	localSearch := PortfolioStrategy[int, int]{
+		Name: "min-conflicts",
+		New: func(domainMap map[int][]int) CSP[int, int] {
+			csp := NewMinConflictsCSP(domainMap)
+			csp.MaxSteps = 1000
+			return csp
+		},
+	}
+	result := NewPortfolioSolver(domainMap, constraints, localSearch).Solve(context.Background())
+	if result.Unsatisfiable {
+		t.Errorf("local search alone cannot prove unsatisfiability")
+	}
+}
@@ -0,0 +1,84 @@
+package gointel
+
+// queensConstraint keeps the queens of columns A and B off each other's row and diagonals.
+type queensConstraint struct {
+	A int
+	B int
+}
+
+func (q *queensConstraint) IsPossiblySatisfied(assignment map[int]int) bool {
+	rowA, okA := assignment[q.A]
+	rowB, okB := assignment[q.B]
+	if !okA || !okB {
+		return true
+	}
+	return rowA != rowB && intAbs(rowA-rowB) != intAbs(q.A-q.B)
+}
+
+func (q *queensConstraint) IsSatisfied(assignment map[int]int) bool {
+	return q.IsPossiblySatisfied(assignment)
+}
+
+func (q *queensConstraint) AsLocal() *LocalConstraint[int, int] {
+	var local LocalConstraint[int, int] = q
+	return &local
+}
+
+func (q *queensConstraint) IsReusable() bool {
+	return false
+}
+
+func (q *queensConstraint) GetVariables() []int {
+	return []int{q.A, q.B}
+}
+
+func (q *queensConstraint) ReduceDomain(variable int, assignment map[int]int, domain []int) []int {
+	return domain
+}
+
+// queensModel is the N-Queens model the solver tests share: one variable per column whose value is the queen's row.
+func queensModel(n int) (map[int][]int, []*Constraint[int, int]) {
+	domainMap := map[int][]int{}
+	for col := 0; col < n; col++ {
+		domainMap[col] = []int{}
+		for row := 0; row < n; row++ {
+			domainMap[col] = append(domainMap[col], row)
+		}
+	}
+	constraints := []*Constraint[int, int]{}
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			var c Constraint[int, int] = &queensConstraint{A: a, B: b}
+			constraints = append(constraints, &c)
+		}
+	}
+	return domainMap, constraints
+}
+
+func newQueensCSP(n int) *CSPDomain[int, int] {
+	domainMap, constraints := queensModel(n)
+	csp := NewCSPDomain(domainMap)
+	csp.AddAllConstraints(constraints...)
+	return csp
+}
+
+func newQueensAgent(n int) *CSPAgent[int, int] {
+	domainMap, constraints := queensModel(n)
+	tree := NewCSPTree(domainMap)
+	tree.AddAllConstraints(constraints...)
+	return tree.constructAgent()
+}
+
+func newIntQueensCSP(n int, preprocessors ...CSPPreprocessor[int, int]) *IntCSP {
+	domainMap, constraints := queensModel(n)
+	csp := NewIntCSP(domainMap, preprocessors...)
+	csp.AddAllConstraints(constraints...)
+	return csp
+}
+
+func newDistributedQueens(n int, owners map[int]int) *DistributedCSP[int, int] {
+	domainMap, constraints := queensModel(n)
+	csp := NewDistributedCSP(domainMap, owners)
+	csp.AddAllConstraints(constraints...)
+	return csp
+}
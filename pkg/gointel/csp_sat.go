@@ -0,0 +1,209 @@
+package gointel
+
+import (
+	"context"
+	"github.com/mtresnik/goutils/pkg/goutils"
+)
+
+// SATCSP encodes the problem into CNF with SATEncoder and solves it with the built-in CDCL solver.
+// FindAllSolutions adds a clause blocking each solution found until the formula becomes unsatisfiable.
+// AddConstraint rejects the constraints the encoder doesn't support with ErrUnsupported.
+type SATCSP[VAR comparable, DOMAIN comparable] struct {
+	DomainMap     map[VAR][]DOMAIN
+	Preprocessors []CSPPreprocessor[VAR, DOMAIN]
+	Encoding      SATEncoding
+	// Compare orders the domains of the order encoding, nil keeps them as listed.
+	Compare func(a, b DOMAIN) int
+	cspConstraints[VAR, DOMAIN]
+	variables *[]VAR
+}
+
+func NewSATCSP[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *SATCSP[VAR, DOMAIN] {
+	return &SATCSP[VAR, DOMAIN]{
+		DomainMap:      domainMap,
+		Preprocessors:  preprocessors,
+		Encoding:       SAT_DIRECT,
+		cspConstraints: newCSPConstraints[VAR, DOMAIN](),
+	}
+}
+
+func (C *SATCSP[VAR, DOMAIN]) GetDomainMap() map[VAR][]DOMAIN {
+	return C.DomainMap
+}
+
+func (C *SATCSP[VAR, DOMAIN]) SetDomainMap(m map[VAR][]DOMAIN) {
+	C.DomainMap = m
+	C.variables = nil
+}
+
+func (C *SATCSP[VAR, DOMAIN]) GetVariables() []VAR {
+	if C.variables == nil {
+		variables := goutils.Keys(C.DomainMap)
+		C.variables = &variables
+	}
+	return *C.variables
+}
+
+func (C *SATCSP[VAR, DOMAIN]) GetDomainForVariable(variable VAR) []DOMAIN {
+	ret, ok := C.DomainMap[variable]
+	if !ok {
+		return []DOMAIN{}
+	}
+	return ret
+}
+
+func (C *SATCSP[VAR, DOMAIN]) Contains(v VAR) bool {
+	_, ok := C.DomainMap[v]
+	return ok
+}
+
+func (C *SATCSP[VAR, DOMAIN]) Preprocess() {
+	_ = C.TryPreprocess()
+}
+
+// TryPreprocess is Preprocess returning ErrInconsistent when the preprocessors leave a variable without values.
+func (C *SATCSP[VAR, DOMAIN]) TryPreprocess() error {
+	return tryPreprocess[VAR, DOMAIN](C, C.Preprocessors)
+}
+
+// AddConstraint returns ErrUnsupported for constraints the encoder cannot translate over the current domains,
+// see SATEncoder.Supports.
+func (C *SATCSP[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
+	if err := NewSATEncoder(C.DomainMap, C.Encoding, C.Compare).Supports(*constraint); err != nil {
+		return err
+	}
+	return C.add(constraint, C.Contains)
+}
+
+func (C *SATCSP[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *SATCSP[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
+}
+
+// Encode translates the domains and constraints into CNF, without preprocessing.
+func (C *SATCSP[VAR, DOMAIN]) Encode() (*SATEncoder[VAR, DOMAIN], error) {
+	encoder := NewSATEncoder(C.DomainMap, C.Encoding, C.Compare)
+	for _, local := range uniqueLocalConstraints(C.GetVariables(), C.localConstraints) {
+		if err := encoder.Encode(*local); err != nil {
+			return nil, err
+		}
+	}
+	for _, global := range C.globalConstraints {
+		if err := encoder.Encode(*global); err != nil {
+			return nil, err
+		}
+	}
+	return encoder, nil
+}
+
+func (C *SATCSP[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	return C.FindOneSolutionContext(context.Background())
+}
+
+// FindOneSolutionContext returns nil when ctx is done before the solver decides the formula.
+func (C *SATCSP[VAR, DOMAIN]) FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN {
+	solution, _ := C.TryFindOneSolutionContext(ctx)
+	return solution
+}
+
+// TryFindOneSolution is FindOneSolution returning the error of TryPreprocess or Encode, so a model the solver
+// cannot encode is told apart from an unsatisfiable one.
+func (C *SATCSP[VAR, DOMAIN]) TryFindOneSolution() (map[VAR]DOMAIN, error) {
+	return C.TryFindOneSolutionContext(context.Background())
+}
+
+func (C *SATCSP[VAR, DOMAIN]) TryFindOneSolutionContext(ctx context.Context) (map[VAR]DOMAIN, error) {
+	solutions, err := C.findSolutions(ctx, 1)
+	if len(solutions) == 0 {
+		return nil, err
+	}
+	return solutions[0], nil
+}
+
+func (C *SATCSP[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
+	return C.FindAllSolutionsContext(context.Background())
+}
+
+// FindAllSolutionsContext returns the solutions found before ctx is done.
+func (C *SATCSP[VAR, DOMAIN]) FindAllSolutionsContext(ctx context.Context) []map[VAR]DOMAIN {
+	solutions, _ := C.TryFindAllSolutionsContext(ctx)
+	return solutions
+}
+
+// TryFindAllSolutions is FindAllSolutions returning the error of TryPreprocess or Encode.
+func (C *SATCSP[VAR, DOMAIN]) TryFindAllSolutions() ([]map[VAR]DOMAIN, error) {
+	return C.TryFindAllSolutionsContext(context.Background())
+}
+
+func (C *SATCSP[VAR, DOMAIN]) TryFindAllSolutionsContext(ctx context.Context) ([]map[VAR]DOMAIN, error) {
+	return C.findSolutions(ctx, 0)
+}
+
+// findSolutions stops after limit solutions when limit is positive, or with the solutions found so far when ctx is
+// done.
+func (C *SATCSP[VAR, DOMAIN]) findSolutions(ctx context.Context, limit int) ([]map[VAR]DOMAIN, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ret := []map[VAR]DOMAIN{}
+	solutions, err := C.generate(ctx)
+	if err != nil {
+		return ret, err
+	}
+	for solution := range solutions {
+		ret = append(ret, solution)
+		if limit > 0 && len(ret) >= limit {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func (C *SATCSP[VAR, DOMAIN]) GenerateSolutionChannel() chan map[VAR]DOMAIN {
+	return C.GenerateSolutionChannelContext(context.Background())
+}
+
+// GenerateSolutionChannelContext sends the solutions as the solver finds them, and closes the channel when there are
+// no more or ctx is done. The channel is closed empty when the model cannot be preprocessed or encoded, see
+// TryFindAllSolutions.
+func (C *SATCSP[VAR, DOMAIN]) GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN {
+	ret, err := C.generate(ctx)
+	if err != nil {
+		ret = make(chan map[VAR]DOMAIN)
+		close(ret)
+	}
+	return ret
+}
+
+func (C *SATCSP[VAR, DOMAIN]) generate(ctx context.Context) (chan map[VAR]DOMAIN, error) {
+	if err := C.TryPreprocess(); err != nil {
+		return nil, err
+	}
+	encoder, err := C.Encode()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(chan map[VAR]DOMAIN)
+	go func() {
+		defer close(ret)
+		solver := NewSATSolver(encoder.CNF)
+		for solver.SolveContext(ctx) == SAT_SATISFIABLE {
+			solution := encoder.Decode(solver.Model())
+			select {
+			case ret <- solution:
+			case <-ctx.Done():
+				return
+			}
+			if !solver.AddClause(encoder.BlockingClause(solution)...) {
+				return
+			}
+		}
+	}()
+	return ret, nil
+}
+
+func (C *SATCSP[VAR, DOMAIN]) GetSeeds() *map[VAR]DOMAIN {
+	return nil
+}
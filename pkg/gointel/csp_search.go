@@ -0,0 +1,165 @@
+package gointel
+
+import (
+	"context"
+	"fmt"
+)
+
+type SearchStrategy int
+
+const (
+	// CSP_SEARCH_HEAP expands the open node with the fewest legal values first. It keeps every open node in memory.
+	CSP_SEARCH_HEAP SearchStrategy = iota
+	// CSP_SEARCH_DFS expands the children of a node in their heuristic order, hinted values first, before its
+	// siblings, keeping only the current branch.
+	CSP_SEARCH_DFS
+	// CSP_SEARCH_LDS is limited discrepancy search: iteration k only follows branches that leave the heuristic order
+	// at most k times, and sends the solutions reached with exactly k discrepancies.
+	CSP_SEARCH_LDS
+	// CSP_SEARCH_DDS is depth-bounded discrepancy search: iteration k may leave the heuristic order anywhere above
+	// depth k, has to at depth k and follows it below, so each leaf is reached once.
+	CSP_SEARCH_DDS
+	// CSP_SEARCH_ITERATIVE_DEEPENING repeats depth first searches limited to 1, 2, ... variables, which stops early
+	// when a shallow iteration finds the whole tree failing. Solutions are sent by the iteration that reaches every
+	// variable.
+	CSP_SEARCH_ITERATIVE_DEEPENING
+)
+
+func (s SearchStrategy) String() string {
+	switch s {
+	case CSP_SEARCH_HEAP:
+		return "heap"
+	case CSP_SEARCH_DFS:
+		return "dfs"
+	case CSP_SEARCH_LDS:
+		return "lds"
+	case CSP_SEARCH_DDS:
+		return "dds"
+	case CSP_SEARCH_ITERATIVE_DEEPENING:
+		return "iterative-deepening"
+	}
+	return fmt.Sprintf("SearchStrategy(%d)", int(s))
+}
+
+// cspProbe is one depth first pass over the agent's tree, allow decides which children are searched from their
+// depth, their rank among their siblings and the discrepancies taken to reach them, emit which solutions are sent.
+// limited records whether a pass left part of the tree out, so a later iteration is needed.
+type cspProbe[VAR comparable, DOMAIN comparable] struct {
+	agent   *CSPAgent[VAR, DOMAIN]
+	ctx     context.Context
+	ch      chan map[VAR]DOMAIN
+	trail   *cspTrail[VAR, DOMAIN]
+	allow   func(depth int, rank int, discrepancies int) bool
+	emit    func(depth int, discrepancies int) bool
+	limited bool
+}
+
+// visit returns false once ctx is done.
+func (P *cspProbe[VAR, DOMAIN]) visit(nodes []CSPNode[VAR, DOMAIN], discrepancies int) bool {
+	for rank := range nodes {
+		if P.ctx.Err() != nil {
+			return false
+		}
+		node := &nodes[rank]
+		taken := discrepancies
+		if rank > 0 {
+			taken++
+		}
+		if !P.allow(node.Depth, rank, taken) {
+			continue
+		}
+		assignment := P.trail.moveTo(node)
+		solution, children := P.agent.expand(node, assignment)
+		if solution != nil {
+			if P.emit(node.Depth, taken) {
+				select {
+				case P.ch <- solution:
+				case <-P.ctx.Done():
+					return false
+				}
+			}
+			continue
+		}
+		if !P.visit(P.agent.order(children, assignment), taken) {
+			return false
+		}
+	}
+	return true
+}
+
+// probe runs passes from the agent's stack, iteration 0, 1, ..., until one is not limited or ctx is done.
+func (C *CSPAgent[VAR, DOMAIN]) probe(ctx context.Context, ch chan map[VAR]DOMAIN, iteration func(i int, probe *cspProbe[VAR, DOMAIN])) {
+	// Hinted roots come first, like the hinted children of expand
+	roots := make([]CSPNode[VAR, DOMAIN], 0, len(C.Stack))
+	for _, hinted := range []bool{true, false} {
+		for _, node := range C.Stack {
+			hint, ok := C.Hints[node.Variable]
+			if (ok && hint == node.Domain) == hinted {
+				roots = append(roots, node)
+			}
+		}
+	}
+	roots = C.order(roots, nil)
+	for i := 0; ctx.Err() == nil; i++ {
+		probe := &cspProbe[VAR, DOMAIN]{agent: C, ctx: ctx, ch: ch, trail: newCSPTrail[VAR, DOMAIN]()}
+		iteration(i, probe)
+		if !probe.visit(roots, 0) || !probe.limited {
+			return
+		}
+	}
+}
+
+func (C *CSPAgent[VAR, DOMAIN]) searchDepthFirst(ctx context.Context, ch chan map[VAR]DOMAIN) {
+	variables := len(C.GetVariables())
+	switch C.SearchStrategy {
+	default:
+		C.probe(ctx, ch, func(_ int, probe *cspProbe[VAR, DOMAIN]) {
+			probe.allow = func(int, int, int) bool { return true }
+			probe.emit = func(int, int) bool { return true }
+		})
+	case CSP_SEARCH_LDS:
+		C.probe(ctx, ch, func(k int, probe *cspProbe[VAR, DOMAIN]) {
+			probe.allow = func(depth int, _ int, discrepancies int) bool {
+				if discrepancies > k {
+					probe.limited = true
+					return false
+				}
+				// Each remaining variable can add at most one discrepancy
+				return k-discrepancies <= variables-depth
+			}
+			probe.emit = func(_ int, discrepancies int) bool { return discrepancies == k }
+		})
+	case CSP_SEARCH_DDS:
+		C.probe(ctx, ch, func(k int, probe *cspProbe[VAR, DOMAIN]) {
+			probe.allow = func(depth int, rank int, _ int) bool {
+				switch {
+				case depth < k:
+					return true
+				case depth == k:
+					// Discrepancies below a skipped node are left to the next iterations
+					if rank == 0 && depth < variables {
+						probe.limited = true
+					}
+					return rank > 0
+				}
+				if rank > 0 {
+					probe.limited = true
+				}
+				return rank == 0
+			}
+			probe.emit = func(int, int) bool { return true }
+		})
+	case CSP_SEARCH_ITERATIVE_DEEPENING:
+		C.probe(ctx, ch, func(i int, probe *cspProbe[VAR, DOMAIN]) {
+			limit := i + 1
+			probe.allow = func(depth int, _ int, _ int) bool {
+				if depth > limit {
+					probe.limited = true
+					return false
+				}
+				return true
+			}
+			probe.emit = func(depth int, _ int) bool { return depth == limit }
+		})
+	}
+}
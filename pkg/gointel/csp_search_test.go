@@ -0,0 +1,124 @@
+package gointel
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestCSPAgent_SearchStrategy(t *testing.T) {
+	strategies := []SearchStrategy{CSP_SEARCH_HEAP, CSP_SEARCH_DFS, CSP_SEARCH_LDS, CSP_SEARCH_DDS, CSP_SEARCH_ITERATIVE_DEEPENING}
+	for _, strategy := range strategies {
+		agent := newQueensAgent(8)
+		agent.SearchStrategy = strategy
+		seen := map[string]bool{}
+		for _, solution := range agent.FindAllSolutions() {
+			key := fmt.Sprint(solution)
+			if seen[key] {
+				t.Errorf("%v: solution %v was sent twice", strategy, solution)
+			}
+			seen[key] = true
+		}
+		if len(seen) != 92 {
+			t.Errorf("%v: expected 92 solutions, got %d", strategy, len(seen))
+		}
+	}
+
+	// Hints make the first branch a solution, which every strategy but the heap tries first
+	hints := map[int]int{0: 0, 1: 4, 2: 7, 3: 5, 4: 2, 5: 6, 6: 1, 7: 3}
+	for _, strategy := range strategies[1:] {
+		agent := newQueensAgent(8)
+		agent.SearchStrategy = strategy
+		agent.Hints = hints
+		if solution := agent.FindOneSolution(); fmt.Sprint(solution) != fmt.Sprint(hints) {
+			t.Errorf("%v: expected the hinted solution first, got %v", strategy, solution)
+		}
+	}
+
+	agent := newQueensAgent(3)
+	agent.SearchStrategy = CSP_SEARCH_LDS
+	if solutions := agent.FindAllSolutions(); len(solutions) != 0 {
+		t.Errorf("expected no solution for 3 queens, got %v", solutions)
+	}
+}
+
+// TestCSPAgent_SearchStrategy_Differential compares every strategy with brute force on small random models whose
+// narrow domains leave nodes without siblings.
+func TestCSPAgent_SearchStrategy_Differential(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for model := 0; model < 300; model++ {
+		variables := 2 + random.Intn(5)
+		domainMap := map[int][]int{}
+		for variable := 0; variable < variables; variable++ {
+			for value := 0; value <= random.Intn(3); value++ {
+				domainMap[variable] = append(domainMap[variable], value)
+			}
+		}
+		// A chain keeps every variable constrained, the other pairs are added at random
+		constraints := []*ExtensionConstraint[int, int]{}
+		for a := 0; a < variables; a++ {
+			for b := a + 1; b < variables; b++ {
+				if b != a+1 && random.Intn(2) == 0 {
+					continue
+				}
+				tuples := [][]int{}
+				for _, x := range domainMap[a] {
+					for _, y := range domainMap[b] {
+						if random.Intn(3) > 0 {
+							tuples = append(tuples, []int{x, y})
+						}
+					}
+				}
+				constraints = append(constraints, NewSupportsConstraint([]int{a, b}, tuples))
+			}
+		}
+
+		expected := 0
+		assignment := map[int]int{}
+		var enumerate func(variable int)
+		enumerate = func(variable int) {
+			if variable == variables {
+				for _, constraint := range constraints {
+					if !constraint.IsSatisfied(assignment) {
+						return
+					}
+				}
+				expected++
+				return
+			}
+			for _, value := range domainMap[variable] {
+				assignment[variable] = value
+				enumerate(variable + 1)
+			}
+			delete(assignment, variable)
+		}
+		enumerate(0)
+
+		for _, strategy := range []SearchStrategy{CSP_SEARCH_HEAP, CSP_SEARCH_DFS, CSP_SEARCH_LDS, CSP_SEARCH_DDS, CSP_SEARCH_ITERATIVE_DEEPENING} {
+			tree := NewCSPTree(domainMap)
+			for _, constraint := range constraints {
+				var c Constraint[int, int] = constraint
+				tree.AddConstraint(&c)
+			}
+			agent := tree.constructAgent()
+			agent.SearchStrategy = strategy
+			if found := len(agent.FindAllSolutions()); found != expected {
+				t.Errorf("model %d, %v: expected %d solutions, got %d", model, strategy, expected, found)
+			}
+		}
+	}
+}
+
+func TestCSPAgent_SearchStrategy_Checkpoint(t *testing.T) {
+	agent := newQueensAgent(6)
+	agent.SearchStrategy = CSP_SEARCH_DFS
+	agent.CheckpointPath = filepath.Join(t.TempDir(), "queens.gob")
+	if solutions := agent.FindAllSolutions(); len(solutions) != 4 {
+		t.Errorf("expected 4 solutions, got %d", len(solutions))
+	}
+	if err := agent.CheckpointError(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for a depth first checkpoint, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package gointel
+
+import (
+	"fmt"
+	"github.com/mtresnik/goutils/pkg/goutils"
+)
+
+// SeedMode decides how a CSP treats its seeds.
+type SeedMode int
+
+const (
+	// SEED_HINT, the default, only tries the seeded value first, so search prefers solutions close to the seeds.
+	SEED_HINT SeedMode = iota
+	// SEED_FIXED pre-assigns the seeds: they are validated, propagated before search and never branched on.
+	SEED_FIXED
+)
+
+// ValidateSeeds returns an error when a seed is not a variable of the csp, is outside its domain or
+// the seeds together violate a constraint.
+func ValidateSeeds[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN]) error {
+	if csp.GetSeeds() == nil {
+		return nil
+	}
+	seeds := *csp.GetSeeds()
+	for variable, value := range seeds {
+		if !csp.Contains(variable) {
+			return fmt.Errorf("seed %v is not a variable", variable)
+		}
+		if !goutils.Contains(csp.GetDomainForVariable(variable), func(d DOMAIN) bool { return d == value }) {
+			return fmt.Errorf("seed %v=%v is not in the domain", variable, value)
+		}
+		for _, constraint := range csp.GetLocalConstraints()[variable] {
+			if !(*constraint).IsPossiblySatisfied(seeds) {
+				return fmt.Errorf("seed %v=%v violates a constraint", variable, value)
+			}
+		}
+	}
+	// Global constraints are checked against partial assignments during search as well
+	for _, constraint := range csp.GetGlobalConstraints() {
+		if !(*constraint).IsSatisfied(seeds) {
+			return fmt.Errorf("seeds violate a global constraint")
+		}
+	}
+	return nil
+}
+
+// propagateSeeds returns a copy of the domains with the seeds fixed and the other domains reduced by them,
+// or ErrInconsistent when a domain is wiped out.
+func propagateSeeds[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN], seeds map[VAR]DOMAIN) (map[VAR][]DOMAIN, error) {
+	ret := CloneMapWithSlices(csp.GetDomainMap())
+	for variable, value := range seeds {
+		ret[variable] = []DOMAIN{value}
+	}
+	for _, variable := range csp.GetVariables() {
+		if _, seeded := seeds[variable]; seeded {
+			continue
+		}
+		ret[variable] = ReduceDomain(variable, seeds, ret[variable], csp.GetLocalConstraints(), csp.GetGlobalConstraints())
+		if len(ret[variable]) == 0 {
+			return nil, fmt.Errorf("%w: the seeds leave %v without values", ErrInconsistent, variable)
+		}
+	}
+	return ret, nil
+}
+
+// hintFirst moves the hinted value to the front of the values, copying them when they are reordered.
+func hintFirst[DOMAIN comparable](values []DOMAIN, hint DOMAIN) []DOMAIN {
+	index := goutils.IndexOf(values, func(d DOMAIN) bool { return d == hint })
+	if index <= 0 {
+		return values
+	}
+	ret := make([]DOMAIN, 0, len(values))
+	ret = append(ret, hint)
+	ret = append(ret, values[:index]...)
+	return append(ret, values[index+1:]...)
+}
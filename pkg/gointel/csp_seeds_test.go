@@ -0,0 +1,84 @@
+package gointel
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCSPDomain_FixedSeeds(t *testing.T) {
+	csp := newQueensCSP(8)
+	csp.SeedMode = SEED_FIXED
+	csp.SetSeed(0, 0)
+	solutions := csp.FindAllSolutions()
+	if len(solutions) != 4 {
+		t.Fatalf("expected 4 solutions with a queen in the corner, got %d", len(solutions))
+	}
+	for _, solution := range solutions {
+		if solution[0] != 0 {
+			t.Errorf("expected the seed to be kept, got %v", solution)
+		}
+	}
+
+	csp.SetSeed(1, 1)
+	if err := ValidateSeeds[int, int](csp); err == nil {
+		t.Errorf("expected attacking seeds to be rejected")
+	}
+	if solutions, err := csp.TryFindAllSolutions(); len(solutions) != 0 || err == nil {
+		t.Errorf("expected no solutions and an error for invalid seeds, got %d and %v", len(solutions), err)
+	}
+
+	outside := newQueensCSP(4)
+	outside.SeedMode = SEED_FIXED
+	outside.SetSeed(0, 9)
+	if err := ValidateSeeds[int, int](outside); err == nil {
+		t.Errorf("expected a seed outside the domain to be rejected")
+	}
+	if solution, err := outside.TryFindOneSolution(); solution != nil || err == nil {
+		t.Errorf("expected the seed outside the domain to be returned, got %v and %v", solution, err)
+	}
+
+	// Three different values out of two: the seeds are consistent but leave C without values
+	wipeOut := NewCSPDomain(map[string][]int{"A": {1, 2}, "B": {1, 2}, "C": {1, 2}})
+	var different Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"A", "B", "C"}}
+	wipeOut.AddConstraint(&different)
+	wipeOut.SeedMode = SEED_FIXED
+	wipeOut.SetSeed("A", 1)
+	wipeOut.SetSeed("B", 2)
+	if _, err := wipeOut.TryFindAllSolutions(); !errors.Is(err, ErrInconsistent) {
+		t.Errorf("expected ErrInconsistent when the seeds empty a domain, got %v", err)
+	}
+}
+
+func TestCSPDomain_HintSeeds(t *testing.T) {
+	variables := []string{"A", "B", "C", "D"}
+	domainMap := map[string][]int{}
+	for _, variable := range variables {
+		domainMap[variable] = []int{1, 2, 3, 4}
+	}
+	yesterday := map[string]int{"A": 2, "B": 1, "C": 4, "D": 3}
+	newRoster := func() *CSPDomain[string, int] {
+		csp := NewCSPDomain(CloneMapWithSlices(domainMap))
+		csp.SetSortingFunction(func(a, b string) bool { return a < b })
+		csp.Parallelism = 1
+		csp.Seeds = &yesterday
+		var c Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &variables}
+		csp.AddConstraint(&c)
+		return csp
+	}
+
+	if solution := newRoster().FindOneSolution(); !reflect.DeepEqual(solution, yesterday) {
+		t.Errorf("expected yesterday's roster, got %v", solution)
+	}
+
+	changed := newRoster()
+	var c Constraint[string, int] = NewAtMostConstraint([]string{"A"}, 0, 2)
+	changed.AddConstraint(&c)
+	solution := changed.FindOneSolution()
+	if solution["A"] == 2 || solution["C"] != 4 || solution["D"] != 3 {
+		t.Errorf("expected only A and B to change, got %v", solution)
+	}
+	if solutions := changed.FindAllSolutions(); len(solutions) != 18 {
+		t.Errorf("expected hints to keep every solution, got %d", len(solutions))
+	}
+}
@@ -0,0 +1,190 @@
+package gointel
+
+// CSPSession re-solves one model under small edits. Constraints are grouped in scopes that can be pushed and popped,
+// and the preprocessed domains of every scope are cached: a scope only adds constraints, so it is preprocessed
+// starting from the domains of the scope below it. DomainMap is never modified, so retracting a constraint or popping
+// its scope restores the domains it pruned.
+type CSPSession[VAR comparable, DOMAIN comparable] struct {
+	DomainMap     map[VAR][]DOMAIN
+	Preprocessors []CSPPreprocessor[VAR, DOMAIN]
+	// NewCSP builds the solver for each solve, a CSPDomain when nil.
+	NewCSP  func(domainMap map[VAR][]DOMAIN) CSP[VAR, DOMAIN]
+	scopes  [][]*Constraint[VAR, DOMAIN]
+	domains []map[VAR][]DOMAIN
+}
+
+func NewCSPSession[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *CSPSession[VAR, DOMAIN] {
+	return &CSPSession[VAR, DOMAIN]{
+		DomainMap:     domainMap,
+		Preprocessors: preprocessors,
+		scopes:        [][]*Constraint[VAR, DOMAIN]{{}},
+		domains:       []map[VAR][]DOMAIN{nil},
+	}
+}
+
+// Push opens a scope, constraints added afterward are discarded by the matching Pop.
+func (S *CSPSession[VAR, DOMAIN]) Push() {
+	S.scopes = append(S.scopes, []*Constraint[VAR, DOMAIN]{})
+	S.domains = append(S.domains, nil)
+}
+
+// Pop discards the innermost scope and its constraints, returning false when only the base scope is left.
+func (S *CSPSession[VAR, DOMAIN]) Pop() bool {
+	if len(S.scopes) == 1 {
+		return false
+	}
+	S.scopes = S.scopes[:len(S.scopes)-1]
+	S.domains = S.domains[:len(S.domains)-1]
+	return true
+}
+
+// Depth is the number of pushed scopes.
+func (S *CSPSession[VAR, DOMAIN]) Depth() int {
+	return len(S.scopes) - 1
+}
+
+// Add puts the constraints in the innermost scope.
+func (S *CSPSession[VAR, DOMAIN]) Add(constraints ...*Constraint[VAR, DOMAIN]) {
+	top := len(S.scopes) - 1
+	S.scopes[top] = append(S.scopes[top], constraints...)
+	S.invalidate(top)
+}
+
+// Retract removes the constraint from whichever scope holds it, returning false when it was never added.
+func (S *CSPSession[VAR, DOMAIN]) Retract(constraint *Constraint[VAR, DOMAIN]) bool {
+	for level, scope := range S.scopes {
+		for index, other := range scope {
+			if other == constraint {
+				S.scopes[level] = append(scope[:index:index], scope[index+1:]...)
+				S.invalidate(level)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (S *CSPSession[VAR, DOMAIN]) invalidate(level int) {
+	for i := level; i < len(S.domains); i++ {
+		S.domains[i] = nil
+	}
+}
+
+// Constraints returns the constraints of every scope, outermost first.
+func (S *CSPSession[VAR, DOMAIN]) Constraints() []*Constraint[VAR, DOMAIN] {
+	ret := []*Constraint[VAR, DOMAIN]{}
+	for _, scope := range S.scopes {
+		ret = append(ret, scope...)
+	}
+	return ret
+}
+
+// Domains returns the preprocessed domains of the innermost scope, computing only the scopes that changed.
+func (S *CSPSession[VAR, DOMAIN]) Domains() map[VAR][]DOMAIN {
+	start := len(S.domains) - 1
+	for start >= 0 && S.domains[start] == nil {
+		start--
+	}
+	constraints := []*Constraint[VAR, DOMAIN]{}
+	for level := 0; level <= start; level++ {
+		constraints = append(constraints, S.scopes[level]...)
+	}
+	for level := start + 1; level < len(S.domains); level++ {
+		from := S.DomainMap
+		if level > 0 {
+			from = S.domains[level-1]
+		}
+		constraints = append(constraints, S.scopes[level]...)
+		S.domains[level] = S.preprocess(from, constraints)
+	}
+	return CloneMapWithSlices(S.domains[len(S.domains)-1])
+}
+
+func (S *CSPSession[VAR, DOMAIN]) preprocess(from map[VAR][]DOMAIN, constraints []*Constraint[VAR, DOMAIN]) map[VAR][]DOMAIN {
+	csp := NewCSPDomain(CloneMapWithSlices(from), S.Preprocessors...)
+	csp.AddAllConstraints(constraints...)
+	if hasEmptyDomain(csp.DomainMap) {
+		return csp.DomainMap
+	}
+	csp.Preprocess()
+	return CloneMapWithSlices(csp.GetDomainMap())
+}
+
+func hasEmptyDomain[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN) bool {
+	for _, domain := range domainMap {
+		if len(domain) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// newCSP builds the solver over the preprocessed domains with each assumed variable fixed to its value,
+// or returns nil when an assumption is outside its domain.
+func (S *CSPSession[VAR, DOMAIN]) newCSP(assumptions map[VAR]DOMAIN) (CSP[VAR, DOMAIN], error) {
+	domainMap := S.Domains()
+	for variable, value := range assumptions {
+		domain, ok := domainMap[variable]
+		if !ok {
+			return nil, nil
+		}
+		domainMap[variable] = []DOMAIN{}
+		for _, other := range domain {
+			if other == value {
+				domainMap[variable] = []DOMAIN{value}
+			}
+		}
+	}
+	if hasEmptyDomain(domainMap) {
+		return nil, nil
+	}
+	var csp CSP[VAR, DOMAIN]
+	if S.NewCSP != nil {
+		csp = S.NewCSP(domainMap)
+	} else {
+		csp = NewCSPDomain(domainMap)
+	}
+	if err := csp.AddAllConstraints(S.Constraints()...); err != nil {
+		return nil, err
+	}
+	return csp, nil
+}
+
+func (S *CSPSession[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	return S.FindOneSolutionUnder(nil)
+}
+
+// FindOneSolutionUnder solves with the assumptions fixed for this call only.
+func (S *CSPSession[VAR, DOMAIN]) FindOneSolutionUnder(assumptions map[VAR]DOMAIN) map[VAR]DOMAIN {
+	ret, _ := S.TryFindOneSolutionUnder(assumptions)
+	return ret
+}
+
+// TryFindOneSolutionUnder is FindOneSolutionUnder returning the error of a solver built by NewCSP that rejects one of
+// the session's constraints.
+func (S *CSPSession[VAR, DOMAIN]) TryFindOneSolutionUnder(assumptions map[VAR]DOMAIN) (map[VAR]DOMAIN, error) {
+	csp, err := S.newCSP(assumptions)
+	if csp == nil {
+		return nil, err
+	}
+	return csp.FindOneSolution(), nil
+}
+
+func (S *CSPSession[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
+	return S.FindAllSolutionsUnder(nil)
+}
+
+func (S *CSPSession[VAR, DOMAIN]) FindAllSolutionsUnder(assumptions map[VAR]DOMAIN) []map[VAR]DOMAIN {
+	ret, _ := S.TryFindAllSolutionsUnder(assumptions)
+	return ret
+}
+
+// TryFindAllSolutionsUnder is FindAllSolutionsUnder returning the error of a solver built by NewCSP that rejects one
+// of the session's constraints.
+func (S *CSPSession[VAR, DOMAIN]) TryFindAllSolutionsUnder(assumptions map[VAR]DOMAIN) ([]map[VAR]DOMAIN, error) {
+	csp, err := S.newCSP(assumptions)
+	if csp == nil {
+		return []map[VAR]DOMAIN{}, err
+	}
+	return csp.FindAllSolutions(), nil
+}
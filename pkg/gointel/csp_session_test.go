@@ -0,0 +1,67 @@
+package gointel
+
+import (
+	"reflect"
+	"testing"
+)
+
+type countingPreprocessor[VAR comparable, DOMAIN comparable] struct {
+	calls int
+}
+
+func (p *countingPreprocessor[VAR, DOMAIN]) Preprocess(csp *CSP[VAR, DOMAIN]) {
+	p.calls++
+	(&AC3Preprocessor[VAR, DOMAIN]{}).Preprocess(csp)
+}
+
+func TestCSPSession(t *testing.T) {
+	variables := []string{"X", "Y", "Z"}
+	domainMap := map[string][]int{"X": {1, 2, 3}, "Y": {1, 2, 3}, "Z": {1, 2, 3}}
+	original := CloneMapWithSlices(domainMap)
+	preprocessor := &countingPreprocessor[string, int]{}
+	session := NewCSPSession[string, int](domainMap, preprocessor)
+	var allDifferent Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &variables}
+	session.Add(&allDifferent)
+	if solutions := session.FindAllSolutions(); len(solutions) != 6 {
+		t.Fatalf("expected 6 solutions, got %d", len(solutions))
+	}
+
+	session.Push()
+	var notOne Constraint[string, int] = NewAtMostConstraint([]string{"X"}, 0, 1)
+	session.Add(&notOne)
+	if domain := session.Domains()["X"]; len(domain) != 2 {
+		t.Errorf("expected X to be pruned to 2 values, got %v", domain)
+	}
+	if solutions := session.FindAllSolutions(); len(solutions) != 4 {
+		t.Errorf("expected 4 solutions in the pushed scope, got %d", len(solutions))
+	}
+	if solution := session.FindOneSolutionUnder(map[string]int{"X": 1}); solution != nil {
+		t.Errorf("expected no solution assuming X=1, got %v", solution)
+	}
+	if solution := session.FindOneSolutionUnder(map[string]int{"X": 3, "Y": 1}); solution == nil || solution["Z"] != 2 {
+		t.Errorf("expected Z=2 under the assumptions, got %v", solution)
+	}
+	calls := preprocessor.calls
+
+	if !session.Pop() || session.Pop() {
+		t.Errorf("expected exactly one scope to pop")
+	}
+	if solutions := session.FindAllSolutions(); len(solutions) != 6 {
+		t.Errorf("expected 6 solutions after popping, got %d", len(solutions))
+	}
+	if preprocessor.calls != calls {
+		t.Errorf("expected the base scope's domains to be reused")
+	}
+
+	if !session.Retract(&allDifferent) || session.Retract(&allDifferent) {
+		t.Errorf("expected the constraint to be retracted once")
+	}
+	var singleOne Constraint[string, int] = NewAtMostConstraint(variables, 1, 1)
+	session.Add(&singleOne)
+	if solutions := session.FindAllSolutions(); len(solutions) != 20 {
+		t.Errorf("expected 20 solutions after replacing the constraint, got %d", len(solutions))
+	}
+	if !reflect.DeepEqual(domainMap, original) {
+		t.Errorf("expected the original domains to be preserved, got %v", domainMap)
+	}
+}
@@ -0,0 +1,222 @@
+package gointel
+
+// Symmetry maps one assignment onto another: variable v takes the value that Variables[v] had, mapped through Values.
+// Variables missing from either map are mapped onto themselves. Symmetries that move values between variables, like
+// the rotations of an N-Queens board, set Literals instead, which maps each variable and its value onto another
+// variable and value and replaces Variables and Values.
+type Symmetry[VAR comparable, DOMAIN comparable] struct {
+	Variables map[VAR]VAR
+	Values    map[DOMAIN]DOMAIN
+	Literals  func(variable VAR, value DOMAIN) (VAR, DOMAIN)
+}
+
+func (s Symmetry[VAR, DOMAIN]) mapVariable(variable VAR) VAR {
+	if mapped, ok := s.Variables[variable]; ok {
+		return mapped
+	}
+	return variable
+}
+
+func (s Symmetry[VAR, DOMAIN]) mapValue(value DOMAIN) DOMAIN {
+	if mapped, ok := s.Values[value]; ok {
+		return mapped
+	}
+	return value
+}
+
+func (s Symmetry[VAR, DOMAIN]) Apply(assignment map[VAR]DOMAIN) map[VAR]DOMAIN {
+	ret := make(map[VAR]DOMAIN, len(assignment))
+	if s.Literals != nil {
+		for variable, value := range assignment {
+			mappedVariable, mappedValue := s.Literals(variable, value)
+			ret[mappedVariable] = mappedValue
+		}
+		return ret
+	}
+	for variable := range assignment {
+		if value, ok := assignment[s.mapVariable(variable)]; ok {
+			ret[variable] = s.mapValue(value)
+		}
+	}
+	return ret
+}
+
+func NewVariableSymmetry[VAR comparable, DOMAIN comparable](variables map[VAR]VAR) Symmetry[VAR, DOMAIN] {
+	return Symmetry[VAR, DOMAIN]{Variables: variables}
+}
+
+func NewValueSymmetry[VAR comparable, DOMAIN comparable](values map[DOMAIN]DOMAIN) Symmetry[VAR, DOMAIN] {
+	return Symmetry[VAR, DOMAIN]{Values: values}
+}
+
+func NewLiteralSymmetry[VAR comparable, DOMAIN comparable](literals func(variable VAR, value DOMAIN) (VAR, DOMAIN)) Symmetry[VAR, DOMAIN] {
+	return Symmetry[VAR, DOMAIN]{Literals: literals}
+}
+
+// ReversalSymmetry swaps the i-th and the (n-1-i)-th variable, e.g. mirroring the columns of an N-Queens board.
+func ReversalSymmetry[VAR comparable, DOMAIN comparable](variables []VAR) Symmetry[VAR, DOMAIN] {
+	mapping := map[VAR]VAR{}
+	for i, variable := range variables {
+		mapping[variable] = variables[len(variables)-1-i]
+	}
+	return NewVariableSymmetry[VAR, DOMAIN](mapping)
+}
+
+// ValueReversalSymmetry swaps the i-th and the (n-1-i)-th value, e.g. mirroring the rows of an N-Queens board.
+func ValueReversalSymmetry[VAR comparable, DOMAIN comparable](values []DOMAIN) Symmetry[VAR, DOMAIN] {
+	mapping := map[DOMAIN]DOMAIN{}
+	for i, value := range values {
+		mapping[value] = values[len(values)-1-i]
+	}
+	return NewValueSymmetry[VAR, DOMAIN](mapping)
+}
+
+// InterchangeableVariables declares that any permutation of the variables maps solutions onto solutions.
+func InterchangeableVariables[VAR comparable, DOMAIN comparable](variables []VAR) []Symmetry[VAR, DOMAIN] {
+	ret := []Symmetry[VAR, DOMAIN]{}
+	for i := 0; i+1 < len(variables); i++ {
+		ret = append(ret, NewVariableSymmetry[VAR, DOMAIN](map[VAR]VAR{
+			variables[i]:   variables[i+1],
+			variables[i+1]: variables[i],
+		}))
+	}
+	return ret
+}
+
+// InterchangeableValues declares that any permutation of the values maps solutions onto solutions.
+func InterchangeableValues[VAR comparable, DOMAIN comparable](values []DOMAIN) []Symmetry[VAR, DOMAIN] {
+	ret := []Symmetry[VAR, DOMAIN]{}
+	for i := 0; i+1 < len(values); i++ {
+		ret = append(ret, NewValueSymmetry[VAR, DOMAIN](map[DOMAIN]DOMAIN{
+			values[i]:   values[i+1],
+			values[i+1]: values[i],
+		}))
+	}
+	return ret
+}
+
+// GridSymmetries returns the rotations and reflections of a grid of variables, indexed as grid[row][col].
+// Square grids get all seven non-identity symmetries, rectangular grids only the 180 degree rotation and both flips.
+func GridSymmetries[VAR comparable, DOMAIN comparable](grid [][]VAR) []Symmetry[VAR, DOMAIN] {
+	rows := len(grid)
+	if rows == 0 {
+		return []Symmetry[VAR, DOMAIN]{}
+	}
+	cols := len(grid[0])
+	ret := []Symmetry[VAR, DOMAIN]{}
+	for _, transform := range gridTransforms(rows, cols) {
+		mapping := map[VAR]VAR{}
+		for row := 0; row < rows; row++ {
+			for col := 0; col < cols; col++ {
+				mappedRow, mappedCol := transform(row, col)
+				mapping[grid[row][col]] = grid[mappedRow][mappedCol]
+			}
+		}
+		ret = append(ret, NewVariableSymmetry[VAR, DOMAIN](mapping))
+	}
+	return ret
+}
+
+// gridTransforms are the rotations and reflections of a rows by cols grid other than the identity.
+func gridTransforms(rows, cols int) []func(row, col int) (int, int) {
+	transforms := []func(row, col int) (int, int){
+		func(row, col int) (int, int) { return rows - 1 - row, cols - 1 - col },
+		func(row, col int) (int, int) { return rows - 1 - row, col },
+		func(row, col int) (int, int) { return row, cols - 1 - col },
+	}
+	if rows == cols {
+		transforms = append(transforms,
+			func(row, col int) (int, int) { return col, rows - 1 - row },
+			func(row, col int) (int, int) { return cols - 1 - col, row },
+			func(row, col int) (int, int) { return col, row },
+			func(row, col int) (int, int) { return cols - 1 - col, rows - 1 - row },
+		)
+	}
+	return transforms
+}
+
+// BoardSymmetries returns the rotations and reflections of a board whose columns are the variables and whose rows
+// are the values, as in N-Queens. Square boards get all seven, rectangular ones the 180 degree rotation and both flips.
+func BoardSymmetries[VAR comparable, DOMAIN comparable](variables []VAR, values []DOMAIN) []Symmetry[VAR, DOMAIN] {
+	columns := map[VAR]int{}
+	for col, variable := range variables {
+		columns[variable] = col
+	}
+	rows := map[DOMAIN]int{}
+	for row, value := range values {
+		rows[value] = row
+	}
+	ret := []Symmetry[VAR, DOMAIN]{}
+	for _, transform := range gridTransforms(len(values), len(variables)) {
+		ret = append(ret, NewLiteralSymmetry(func(variable VAR, value DOMAIN) (VAR, DOMAIN) {
+			row, col := transform(rows[value], columns[variable])
+			return variables[col], values[row]
+		}))
+	}
+	return ret
+}
+
+// NewSymmetryBreakingConstraint builds the lex-leader constraint order <=lex symmetry(order),
+// which keeps the lexicographically smallest member of every class of symmetric solutions.
+func NewSymmetryBreakingConstraint[VAR comparable, DOMAIN comparable](order []VAR, compare func(a, b DOMAIN) int, symmetry Symmetry[VAR, DOMAIN]) *LexLessEqConstraint[VAR, DOMAIN] {
+	if symmetry.Literals != nil {
+		ret := NewLexLessEqConstraint(order, order, compare)
+		ret.Literals = symmetry.Literals
+		return ret
+	}
+	left := []VAR{}
+	right := []VAR{}
+	for _, variable := range order {
+		mapped := symmetry.mapVariable(variable)
+		if mapped == variable && len(symmetry.Values) == 0 {
+			// Always equal, contributes nothing to the ordering
+			continue
+		}
+		left = append(left, variable)
+		right = append(right, mapped)
+	}
+	ret := NewLexLessEqConstraint(left, right, compare)
+	ret.Values = symmetry.Values
+	return ret
+}
+
+// BreakSymmetries adds one lex-leader constraint per symmetry to the csp, stopping at the first one it rejects.
+// Every symmetry must be compared along the same variable order for the constraints to be sound.
+func BreakSymmetries[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN], order []VAR, compare func(a, b DOMAIN) int, symmetries ...Symmetry[VAR, DOMAIN]) error {
+	for _, symmetry := range symmetries {
+		var c Constraint[VAR, DOMAIN] = NewSymmetryBreakingConstraint(order, compare, symmetry)
+		if err := csp.AddConstraint(&c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpandSolutions returns the closure of the solutions under the symmetries, without duplicates.
+func ExpandSolutions[VAR comparable, DOMAIN comparable](solutions []map[VAR]DOMAIN, symmetries ...Symmetry[VAR, DOMAIN]) []map[VAR]DOMAIN {
+	seen := map[string]bool{}
+	ret := []map[VAR]DOMAIN{}
+	queue := []map[VAR]DOMAIN{}
+	for _, solution := range solutions {
+		key := assignmentKey(solution)
+		if !seen[key] {
+			seen[key] = true
+			ret = append(ret, solution)
+			queue = append(queue, solution)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, symmetry := range symmetries {
+			image := symmetry.Apply(current)
+			key := assignmentKey(image)
+			if !seen[key] {
+				seen[key] = true
+				ret = append(ret, image)
+				queue = append(queue, image)
+			}
+		}
+	}
+	return ret
+}
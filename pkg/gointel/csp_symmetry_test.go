@@ -0,0 +1,112 @@
+package gointel
+
+import (
+	"testing"
+)
+
+func TestCSPDomain_BreakSymmetries_NQueens(t *testing.T) {
+	n := 8
+	columns := []int{}
+	rows := []int{}
+	for i := 0; i < n; i++ {
+		columns = append(columns, i)
+		rows = append(rows, i)
+	}
+	symmetries := []Symmetry[int, int]{
+		ReversalSymmetry[int, int](columns),
+		ValueReversalSymmetry[int, int](rows),
+	}
+	compare := func(a, b int) int { return a - b }
+
+	csp := newQueensCSP(n)
+	csp.BreakSymmetries(columns, compare, symmetries...)
+	canonical := csp.FindAllSolutions()
+	if len(canonical) == 0 || len(canonical) >= 92 {
+		t.Fatalf("expected fewer canonical solutions than 92, got %d", len(canonical))
+	}
+	expanded := ExpandSolutions(canonical, symmetries...)
+	if len(expanded) != 92 {
+		t.Errorf("expected 92 expanded solutions, got %d", len(expanded))
+	}
+
+	expanding := newQueensCSP(n)
+	expanding.ExpandSymmetries = true
+	expanding.BreakSymmetries(columns, compare, symmetries...)
+	if solutions := expanding.FindAllSolutions(); len(solutions) != 92 {
+		t.Errorf("expected 92 solutions with ExpandSymmetries, got %d", len(solutions))
+	}
+}
+
+func TestGridSymmetries(t *testing.T) {
+	grid := [][]string{{"a", "b"}, {"c", "d"}}
+	variables := []string{"a", "b", "c", "d"}
+	domainMap := map[string][]int{}
+	for _, variable := range variables {
+		domainMap[variable] = []int{0, 1}
+	}
+	csp := NewCSPDomain(domainMap)
+	var c Constraint[string, int] = NewExactlyConstraint(variables, 1, 1)
+	csp.AddConstraint(&c)
+	symmetries := GridSymmetries[string, int](grid)
+	if len(symmetries) != 7 {
+		t.Fatalf("expected 7 symmetries for a square grid, got %d", len(symmetries))
+	}
+	csp.BreakSymmetries(variables, func(a, b int) int { return b - a }, symmetries...)
+	canonical := csp.FindAllSolutions()
+	if len(canonical) != 1 {
+		t.Fatalf("expected a single canonical solution, got %d", len(canonical))
+	}
+	if canonical[0]["a"] != 1 {
+		t.Errorf("expected the lex-leader to mark the first cell, got %v", canonical[0])
+	}
+	if expanded := ExpandSolutions(canonical, symmetries...); len(expanded) != 4 {
+		t.Errorf("expected 4 expanded solutions, got %d", len(expanded))
+	}
+}
+
+func TestInterchangeableValues(t *testing.T) {
+	variables := []string{"x", "y", "z"}
+	domainMap := map[string][]int{}
+	for _, variable := range variables {
+		domainMap[variable] = []int{0, 1, 2}
+	}
+	csp := NewCSPDomain(domainMap)
+	var c Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &variables}
+	csp.AddConstraint(&c)
+	symmetries := InterchangeableValues[string, int]([]int{0, 1, 2})
+	csp.BreakSymmetries(variables, func(a, b int) int { return a - b }, symmetries...)
+	canonical := csp.FindAllSolutions()
+	if len(canonical) != 1 {
+		t.Fatalf("expected one canonical permutation, got %d", len(canonical))
+	}
+	if expanded := ExpandSolutions(canonical, symmetries...); len(expanded) != 6 {
+		t.Errorf("expected 6 expanded solutions, got %d", len(expanded))
+	}
+}
+
+func TestBoardSymmetries_NQueens(t *testing.T) {
+	n := 8
+	columns := []int{}
+	for i := 0; i < n; i++ {
+		columns = append(columns, i)
+	}
+	symmetries := BoardSymmetries[int, int](columns, columns)
+	if len(symmetries) != 7 {
+		t.Fatalf("expected 7 symmetries for a square board, got %d", len(symmetries))
+	}
+	// Rotating a quarter turn moves the queen of column 0, row 1 to column 6, row 0
+	if rotated := symmetries[3].Apply(map[int]int{0: 1}); len(rotated) != 1 || rotated[6] != 0 {
+		t.Errorf("expected the rotated queen in column 6, row 0, got %v", rotated)
+	}
+
+	csp := newQueensCSP(n)
+	csp.BreakSymmetries(columns, func(a, b int) int { return a - b }, symmetries...)
+	canonical := csp.FindAllSolutions()
+	// 8-queens has 12 fundamental solutions
+	if len(canonical) != 12 {
+		t.Fatalf("expected 12 canonical solutions, got %d", len(canonical))
+	}
+	if expanded := ExpandSolutions(canonical, symmetries...); len(expanded) != 92 {
+		t.Errorf("expected 92 expanded solutions, got %d", len(expanded))
+	}
+}
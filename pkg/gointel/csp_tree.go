@@ -1,6 +1,9 @@
 package gointel
 
-import "github.com/mtresnik/goutils/pkg/goutils"
+import (
+	"context"
+	"github.com/mtresnik/goutils/pkg/goutils"
+)
 
 type CSPTree[VAR comparable, DOMAIN comparable] struct {
 	DomainMap         map[VAR][]DOMAIN
@@ -8,6 +11,10 @@ type CSPTree[VAR comparable, DOMAIN comparable] struct {
 	localConstraints  map[VAR][]*LocalConstraint[VAR, DOMAIN]
 	globalConstraints []*GlobalConstraint[VAR, DOMAIN]
 	sortingFunction   *func(a, b VAR) bool
+	// SearchStrategy and NodePriority are passed to the agent that searches the tree.
+	SearchStrategy SearchStrategy
+	NodePriority   CSPNodePriority[VAR, DOMAIN]
+	cspValidator[VAR, DOMAIN]
 }
 
 func NewCSPTree[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *CSPTree[VAR, DOMAIN] {
@@ -45,10 +52,12 @@ func (C *CSPTree[VAR, DOMAIN]) Contains(v VAR) bool {
 }
 
 func (C *CSPTree[VAR, DOMAIN]) Preprocess() {
-	var csp CSP[VAR, DOMAIN] = C
-	for _, preprocessor := range C.Preprocessors {
-		preprocessor.Preprocess(&csp)
-	}
+	_ = C.TryPreprocess()
+}
+
+// TryPreprocess is Preprocess returning ErrInconsistent when the preprocessors leave a variable without values.
+func (C *CSPTree[VAR, DOMAIN]) TryPreprocess() error {
+	return tryPreprocess[VAR, DOMAIN](C, C.Preprocessors)
 }
 
 func (C *CSPTree[VAR, DOMAIN]) GetLocalConstraints() map[VAR][]*LocalConstraint[VAR, DOMAIN] {
@@ -59,9 +68,12 @@ func (C *CSPTree[VAR, DOMAIN]) GetGlobalConstraints() []*GlobalConstraint[VAR, D
 	return C.globalConstraints
 }
 
-func (C *CSPTree[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) {
+func (C *CSPTree[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
 	if constraint == nil {
-		return
+		return nil
+	}
+	if err := C.accept(constraint, C.Contains); err != nil {
+		return err
 	}
 	local := (*constraint).AsLocal()
 	if local != nil {
@@ -84,30 +96,58 @@ func (C *CSPTree[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]
 		var global GlobalConstraint[VAR, DOMAIN] = *constraint
 		C.globalConstraints = append(C.globalConstraints, &global)
 	}
+	return nil
 }
 
-func (C *CSPTree[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) {
-	for _, constraint := range constraints {
-		C.AddConstraint(constraint)
-	}
+func (C *CSPTree[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *CSPTree[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
 }
 
 func (C *CSPTree[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
-	C.Preprocess()
+	solutions, _ := C.TryFindAllSolutions()
+	return solutions
+}
+
+// TryFindAllSolutions is FindAllSolutions returning the error of TryPreprocess, so an inconsistent model is told
+// apart from one whose search found nothing.
+func (C *CSPTree[VAR, DOMAIN]) TryFindAllSolutions() ([]map[VAR]DOMAIN, error) {
+	if err := C.TryPreprocess(); err != nil {
+		return []map[VAR]DOMAIN{}, err
+	}
 	agent := C.constructAgent()
 	if agent == nil {
-		return []map[VAR]DOMAIN{}
+		return []map[VAR]DOMAIN{}, nil
 	}
-	return agent.FindAllSolutions()
+	return agent.FindAllSolutions(), nil
 }
 
 func (C *CSPTree[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
-	C.Preprocess()
+	return C.FindOneSolutionContext(context.Background())
+}
+
+func (C *CSPTree[VAR, DOMAIN]) FindOneSolutionContext(ctx context.Context) map[VAR]DOMAIN {
+	solution, _ := C.TryFindOneSolutionContext(ctx)
+	return solution
+}
+
+// TryFindOneSolution is FindOneSolution returning the error of TryPreprocess.
+func (C *CSPTree[VAR, DOMAIN]) TryFindOneSolution() (map[VAR]DOMAIN, error) {
+	return C.TryFindOneSolutionContext(context.Background())
+}
+
+func (C *CSPTree[VAR, DOMAIN]) TryFindOneSolutionContext(ctx context.Context) (map[VAR]DOMAIN, error) {
+	if err := C.TryPreprocess(); err != nil {
+		return nil, err
+	}
 	agent := C.constructAgent()
 	if agent == nil {
-		return nil
+		return nil, nil
 	}
-	return agent.FindOneSolution()
+	return agent.FindOneSolutionContext(ctx), nil
 }
 
 func (C *CSPTree[VAR, DOMAIN]) GenerateSolutionChannel() chan map[VAR]DOMAIN {
@@ -141,6 +181,8 @@ func (C *CSPTree[VAR, DOMAIN]) constructAgent() *CSPAgent[VAR, DOMAIN] {
 	}
 	ret := NewCSPAgent(&C.DomainMap, variables, rootNodes)
 	ret.SortingFunction = C.sortingFunction
+	ret.SearchStrategy = C.SearchStrategy
+	ret.NodePriority = C.NodePriority
 	for _, local := range C.localConstraints {
 		toAdd := []*Constraint[VAR, DOMAIN]{}
 		for _, constraint := range local {
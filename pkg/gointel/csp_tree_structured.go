@@ -0,0 +1,377 @@
+package gointel
+
+import (
+	"context"
+	"github.com/mtresnik/goutils/pkg/goutils"
+)
+
+// TreeStructuredCSP exploits the shape of binary constraint graphs. When the graph is a forest it is solved in two
+// linear passes: directional arc consistency from the leaves up, then a backtrack-free assignment from the roots down.
+// Near-trees are solved by cycle cutset conditioning, enumerating the cutset and solving the remaining forest for each
+// cutset assignment. Anything else, such as global or non-binary constraints, falls back to CSPDomain.
+type TreeStructuredCSP[VAR comparable, DOMAIN comparable] struct {
+	DomainMap     map[VAR][]DOMAIN
+	Preprocessors []CSPPreprocessor[VAR, DOMAIN]
+	MaxCutsetSize int
+	cspConstraints[VAR, DOMAIN]
+	variables *[]VAR
+}
+
+func NewTreeStructuredCSP[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, preprocessors ...CSPPreprocessor[VAR, DOMAIN]) *TreeStructuredCSP[VAR, DOMAIN] {
+	return &TreeStructuredCSP[VAR, DOMAIN]{
+		DomainMap:      domainMap,
+		Preprocessors:  preprocessors,
+		MaxCutsetSize:  CSP_MAX_CUTSET_SIZE,
+		cspConstraints: newCSPConstraints[VAR, DOMAIN](),
+	}
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) GetDomainMap() map[VAR][]DOMAIN {
+	return C.DomainMap
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) SetDomainMap(m map[VAR][]DOMAIN) {
+	C.DomainMap = m
+	C.variables = nil
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) GetVariables() []VAR {
+	if C.variables == nil {
+		variables := goutils.Keys(C.DomainMap)
+		C.variables = &variables
+	}
+	return *C.variables
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) GetDomainForVariable(variable VAR) []DOMAIN {
+	ret, ok := C.DomainMap[variable]
+	if !ok {
+		return []DOMAIN{}
+	}
+	return ret
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) Contains(v VAR) bool {
+	_, ok := C.DomainMap[v]
+	return ok
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) Preprocess() {
+	var csp CSP[VAR, DOMAIN] = C
+	for _, preprocessor := range C.Preprocessors {
+		preprocessor.Preprocess(&csp)
+	}
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) AddConstraint(constraint *Constraint[VAR, DOMAIN]) error {
+	return C.add(constraint, C.Contains)
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) AddAllConstraints(constraints ...*Constraint[VAR, DOMAIN]) error {
+	return addAll(C.AddConstraint, constraints)
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) Validate() []ValidationIssue[VAR, DOMAIN] {
+	return C.validate(C)
+}
+
+// Cutset returns the cycle cutset the solver would condition on, empty for tree-shaped graphs.
+// The second value is false when the model has global or non-binary constraints.
+func (C *TreeStructuredCSP[VAR, DOMAIN]) Cutset() ([]VAR, bool) {
+	solver := newTreeSolver[VAR, DOMAIN](C)
+	if solver == nil {
+		return nil, false
+	}
+	return solver.cutset, true
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) IsTreeStructured() bool {
+	cutset, ok := C.Cutset()
+	return ok && len(cutset) == 0
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) fallback() *CSPDomain[VAR, DOMAIN] {
+	ret := NewCSPDomain(CloneMapWithSlices(C.DomainMap))
+	copyConstraints[VAR, DOMAIN](C, ret)
+	return ret
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) solver() *treeSolver[VAR, DOMAIN] {
+	solver := newTreeSolver[VAR, DOMAIN](C)
+	if solver == nil || len(solver.cutset) > C.MaxCutsetSize {
+		return nil
+	}
+	return solver
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) FindOneSolution() map[VAR]DOMAIN {
+	C.Preprocess()
+	solver := C.solver()
+	if solver == nil {
+		return C.fallback().FindOneSolution()
+	}
+	var ret map[VAR]DOMAIN = nil
+	solver.solve(func(solution map[VAR]DOMAIN) bool {
+		ret = solution
+		return false
+	})
+	return ret
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) FindAllSolutions() []map[VAR]DOMAIN {
+	C.Preprocess()
+	solver := C.solver()
+	if solver == nil {
+		return C.fallback().FindAllSolutions()
+	}
+	collected := []map[VAR]DOMAIN{}
+	solver.solve(func(solution map[VAR]DOMAIN) bool {
+		collected = append(collected, solution)
+		return true
+	})
+	return collected
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) GenerateSolutionChannel() chan map[VAR]DOMAIN {
+	return C.GenerateSolutionChannelContext(context.Background())
+}
+
+// GenerateSolutionChannelContext sends the solutions as the solver finds them and closes the channel when there are
+// no more or ctx is done.
+func (C *TreeStructuredCSP[VAR, DOMAIN]) GenerateSolutionChannelContext(ctx context.Context) chan map[VAR]DOMAIN {
+	C.Preprocess()
+	solver := C.solver()
+	if solver == nil {
+		return C.fallback().GenerateSolutionChannelContext(ctx)
+	}
+	ch := make(chan map[VAR]DOMAIN)
+	go func() {
+		defer close(ch)
+		solver.solve(func(solution map[VAR]DOMAIN) bool {
+			select {
+			case ch <- solution:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
+
+func (C *TreeStructuredCSP[VAR, DOMAIN]) GetSeeds() *map[VAR]DOMAIN {
+	return nil
+}
+
+type treeSolver[VAR comparable, DOMAIN comparable] struct {
+	domains   map[VAR][]DOMAIN
+	binary    map[VAR]map[VAR][]LocalConstraint[VAR, DOMAIN]
+	neighbors map[VAR][]VAR
+	cutset    []VAR
+	order     []VAR
+	parent    map[VAR]VAR
+}
+
+// newTreeSolver returns nil when the csp has global constraints or constraints over more than two variables.
+func newTreeSolver[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN]) *treeSolver[VAR, DOMAIN] {
+	if len(csp.GetGlobalConstraints()) > 0 {
+		return nil
+	}
+	variables := csp.GetVariables()
+	domains := map[VAR][]DOMAIN{}
+	binary := map[VAR]map[VAR][]LocalConstraint[VAR, DOMAIN]{}
+	for _, variable := range variables {
+		domains[variable] = csp.GetDomainForVariable(variable)
+		binary[variable] = map[VAR][]LocalConstraint[VAR, DOMAIN]{}
+	}
+	for _, constraint := range uniqueLocalConstraints(variables, csp.GetLocalConstraints()) {
+		scope := goutils.Unique(goutils.Filter((*constraint).GetVariables(), csp.Contains))
+		switch len(scope) {
+		case 1:
+			x := scope[0]
+			domains[x] = goutils.Filter(domains[x], func(value DOMAIN) bool {
+				return (*constraint).IsPossiblySatisfied(map[VAR]DOMAIN{x: value})
+			})
+		case 2:
+			x, y := scope[0], scope[1]
+			binary[x][y] = append(binary[x][y], *constraint)
+			binary[y][x] = append(binary[y][x], *constraint)
+		default:
+			return nil
+		}
+	}
+	graph := NewConstraintGraph(csp)
+	ret := &treeSolver[VAR, DOMAIN]{
+		domains:   domains,
+		binary:    binary,
+		neighbors: graph.Neighbors,
+		cutset:    cycleCutset(graph),
+		parent:    map[VAR]VAR{},
+	}
+	ret.orderForest(variables)
+	return ret
+}
+
+// cycleCutset greedily removes the highest degree variable until pruning leaves empties the graph.
+func cycleCutset[VAR comparable](graph *ConstraintGraph[VAR]) []VAR {
+	remaining := map[VAR]map[VAR]bool{}
+	for _, variable := range graph.Variables {
+		remaining[variable] = map[VAR]bool{}
+		for _, neighbor := range graph.Neighbors[variable] {
+			remaining[variable][neighbor] = true
+		}
+	}
+	remove := func(variable VAR) {
+		for neighbor := range remaining[variable] {
+			delete(remaining[neighbor], variable)
+		}
+		delete(remaining, variable)
+	}
+	cutset := []VAR{}
+	for {
+		for pruned := true; pruned; {
+			pruned = false
+			for _, variable := range graph.Variables {
+				if neighbors, ok := remaining[variable]; ok && len(neighbors) <= 1 {
+					remove(variable)
+					pruned = true
+				}
+			}
+		}
+		if len(remaining) == 0 {
+			return cutset
+		}
+		var chosen VAR
+		maxDegree := -1
+		for _, variable := range graph.Variables {
+			if neighbors, ok := remaining[variable]; ok && len(neighbors) > maxDegree {
+				chosen = variable
+				maxDegree = len(neighbors)
+			}
+		}
+		cutset = append(cutset, chosen)
+		remove(chosen)
+	}
+}
+
+// orderForest orders the variables outside the cutset breadth first so every variable follows its parent.
+func (s *treeSolver[VAR, DOMAIN]) orderForest(variables []VAR) {
+	visited := map[VAR]bool{}
+	for _, variable := range s.cutset {
+		visited[variable] = true
+	}
+	for _, root := range variables {
+		if visited[root] {
+			continue
+		}
+		visited[root] = true
+		start := len(s.order)
+		s.order = append(s.order, root)
+		for i := start; i < len(s.order); i++ {
+			current := s.order[i]
+			for _, neighbor := range s.neighbors[current] {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					s.parent[neighbor] = current
+					s.order = append(s.order, neighbor)
+				}
+			}
+		}
+	}
+}
+
+func (s *treeSolver[VAR, DOMAIN]) consistent(x VAR, valueX DOMAIN, y VAR, valueY DOMAIN) bool {
+	assignment := map[VAR]DOMAIN{x: valueX, y: valueY}
+	for _, constraint := range s.binary[x][y] {
+		if !constraint.IsPossiblySatisfied(assignment) {
+			return false
+		}
+	}
+	return true
+}
+
+// solve passes every solution to yield until it returns false.
+func (s *treeSolver[VAR, DOMAIN]) solve(yield func(map[VAR]DOMAIN) bool) {
+	s.assignCutset(0, map[VAR]DOMAIN{}, yield)
+}
+
+func (s *treeSolver[VAR, DOMAIN]) assignCutset(index int, assignment map[VAR]DOMAIN, yield func(map[VAR]DOMAIN) bool) bool {
+	if index == len(s.cutset) {
+		return s.solveForest(assignment, yield)
+	}
+	variable := s.cutset[index]
+	for _, value := range s.domains[variable] {
+		consistent := true
+		for other, otherValue := range assignment {
+			if !s.consistent(variable, value, other, otherValue) {
+				consistent = false
+				break
+			}
+		}
+		if !consistent {
+			continue
+		}
+		assignment[variable] = value
+		if !s.assignCutset(index+1, assignment, yield) {
+			return false
+		}
+		delete(assignment, variable)
+	}
+	return true
+}
+
+func (s *treeSolver[VAR, DOMAIN]) solveForest(cutsetAssignment map[VAR]DOMAIN, yield func(map[VAR]DOMAIN) bool) bool {
+	domains := map[VAR][]DOMAIN{}
+	for _, variable := range s.order {
+		domains[variable] = goutils.Filter(s.domains[variable], func(value DOMAIN) bool {
+			for other, otherValue := range cutsetAssignment {
+				if !s.consistent(variable, value, other, otherValue) {
+					return false
+				}
+			}
+			return true
+		})
+		if len(domains[variable]) == 0 {
+			return true
+		}
+	}
+
+	// Directional arc consistency, leaves first
+	for i := len(s.order) - 1; i >= 0; i-- {
+		child := s.order[i]
+		parent, ok := s.parent[child]
+		if !ok {
+			continue
+		}
+		domains[parent] = goutils.Filter(domains[parent], func(parentValue DOMAIN) bool {
+			return goutils.Any(domains[child], func(childValue DOMAIN) bool {
+				return s.consistent(parent, parentValue, child, childValue)
+			})
+		})
+		if len(domains[parent]) == 0 {
+			return true
+		}
+	}
+	return s.enumerate(0, domains, CloneMap(cutsetAssignment), yield)
+}
+
+// enumerate never backtracks out of a dead end: after directional arc consistency every value left
+// for a parent has a supporting value in each of its children.
+func (s *treeSolver[VAR, DOMAIN]) enumerate(index int, domains map[VAR][]DOMAIN, assignment map[VAR]DOMAIN, yield func(map[VAR]DOMAIN) bool) bool {
+	if index == len(s.order) {
+		return yield(CloneMap(assignment))
+	}
+	variable := s.order[index]
+	parent, hasParent := s.parent[variable]
+	for _, value := range domains[variable] {
+		if hasParent && !s.consistent(variable, value, parent, assignment[parent]) {
+			continue
+		}
+		assignment[variable] = value
+		if !s.enumerate(index+1, domains, assignment, yield) {
+			return false
+		}
+	}
+	delete(assignment, variable)
+	return true
+}
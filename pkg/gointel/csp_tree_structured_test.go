@@ -0,0 +1,74 @@
+package gointel
+
+import (
+	"context"
+	"testing"
+)
+
+func newColoringTreeStructuredCSP(variables []string, edges [][2]string) *TreeStructuredCSP[string, string] {
+	domains := map[string][]string{}
+	for _, variable := range variables {
+		domains[variable] = []string{"red", "green", "blue"}
+	}
+	csp := NewTreeStructuredCSP(domains)
+	for _, edge := range edges {
+		var c Constraint[string, string] = &mapColoringConstraint{From: edge[0], To: edge[1]}
+		csp.AddConstraint(&c)
+	}
+	return csp
+}
+
+func TestTreeStructuredCSP_Tree(t *testing.T) {
+	csp := newColoringTreeStructuredCSP(
+		[]string{"A", "B", "C", "D", "E"},
+		[][2]string{{"A", "B"}, {"B", "C"}, {"B", "D"}},
+	)
+	if !csp.IsTreeStructured() {
+		t.Fatalf("expected a tree structured graph")
+	}
+	// 3 * 2 * 2 * 2 colorings of the tree times 3 for the isolated variable
+	if solutions := csp.FindAllSolutions(); len(solutions) != 72 {
+		t.Errorf("expected 72 solutions, got %d", len(solutions))
+	}
+	solution := csp.FindOneSolution()
+	if len(solution) != 5 || solution["A"] == solution["B"] || solution["B"] == solution["C"] {
+		t.Errorf("invalid solution %v", solution)
+	}
+
+	// The producer stops once ctx is done instead of blocking on the remaining solutions
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := csp.GenerateSolutionChannelContext(ctx)
+	<-ch
+	cancel()
+	for range ch {
+		// Solutions already on their way may still arrive, then the channel closes
+	}
+}
+
+func TestTreeStructuredCSP_CutsetConditioning(t *testing.T) {
+	csp := newColoringTreeStructuredCSP(
+		[]string{"A", "B", "C", "D", "E"},
+		[][2]string{{"A", "B"}, {"B", "C"}, {"C", "D"}, {"D", "E"}, {"E", "A"}},
+	)
+	cutset, ok := csp.Cutset()
+	if !ok || len(cutset) != 1 {
+		t.Fatalf("expected a single variable cutset, got %v", cutset)
+	}
+	// Chromatic polynomial of the 5-cycle: (k-1)^5 - (k-1)
+	if solutions := csp.FindAllSolutions(); len(solutions) != 30 {
+		t.Errorf("expected 30 solutions, got %d", len(solutions))
+	}
+}
+
+func TestTreeStructuredCSP_Fallback(t *testing.T) {
+	domains := map[string][]int{"A": {1, 2, 3}, "B": {1, 2, 3}, "C": {1, 2, 3}}
+	csp := NewTreeStructuredCSP(domains)
+	var c Constraint[string, int] = &GlobalAllDifferentConstraint[string, int]{}
+	csp.AddConstraint(&c)
+	if _, ok := csp.Cutset(); ok {
+		t.Fatalf("expected global constraints to disable the tree solver")
+	}
+	if solutions := csp.FindAllSolutions(); len(solutions) != 6 {
+		t.Errorf("expected 6 solutions, got %d", len(solutions))
+	}
+}
@@ -0,0 +1,177 @@
+package gointel
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type ValidationIssueKind int
+
+const (
+	// VALIDATION_UNKNOWN_VARIABLE is a local constraint over a variable missing from the domain map.
+	VALIDATION_UNKNOWN_VARIABLE ValidationIssueKind = iota
+	// VALIDATION_EMPTY_DOMAIN is a variable without values, which leaves the CSP without solutions.
+	VALIDATION_EMPTY_DOMAIN
+	// VALIDATION_UNCONSTRAINED_VARIABLE is a variable in no constraint, which takes every value of its domain.
+	VALIDATION_UNCONSTRAINED_VARIABLE
+	// VALIDATION_DUPLICATE_CONSTRAINT is a constraint added more than once.
+	VALIDATION_DUPLICATE_CONSTRAINT
+	// VALIDATION_ARITY_MISMATCH is a local constraint whose scope is empty or repeats a variable.
+	VALIDATION_ARITY_MISMATCH
+)
+
+func (k ValidationIssueKind) String() string {
+	switch k {
+	case VALIDATION_UNKNOWN_VARIABLE:
+		return "unknown variable"
+	case VALIDATION_EMPTY_DOMAIN:
+		return "empty domain"
+	case VALIDATION_UNCONSTRAINED_VARIABLE:
+		return "unconstrained variable"
+	case VALIDATION_DUPLICATE_CONSTRAINT:
+		return "duplicate constraint"
+	case VALIDATION_ARITY_MISMATCH:
+		return "arity mismatch"
+	}
+	return fmt.Sprintf("ValidationIssueKind(%d)", int(k))
+}
+
+// ValidationIssue is a problem Validate found in a model. It is also the error strict AddConstraint returns.
+type ValidationIssue[VAR comparable, DOMAIN comparable] struct {
+	Kind       ValidationIssueKind
+	Variables  []VAR
+	Constraint *Constraint[VAR, DOMAIN]
+	Message    string
+}
+
+func (v ValidationIssue[VAR, DOMAIN]) Error() string {
+	return v.Kind.String() + ": " + v.Message
+}
+
+// cspValidator remembers every constraint handed to AddConstraint, including the ones the CSP drops because none
+// of their variables are known, so Validate can report them.
+type cspValidator[VAR comparable, DOMAIN comparable] struct {
+	// Strict makes AddConstraint reject constraints over unknown variables, with a malformed scope or added twice.
+	Strict bool
+	added  []*Constraint[VAR, DOMAIN]
+	seen   map[any]bool
+}
+
+func (V *cspValidator[VAR, DOMAIN]) accept(constraint *Constraint[VAR, DOMAIN], contains func(VAR) bool) error {
+	if V.seen == nil {
+		V.seen = map[any]bool{}
+	}
+	if V.Strict {
+		if issues := constraintIssues(constraint, contains, V.seen); len(issues) > 0 {
+			return issues[0]
+		}
+	}
+	V.added = append(V.added, constraint)
+	if key, ok := constraintKey(constraint); ok {
+		V.seen[key] = true
+	}
+	return nil
+}
+
+// constraintKey identifies a constraint by pointer, or by value for comparable constraint structs.
+func constraintKey[VAR comparable, DOMAIN comparable](constraint *Constraint[VAR, DOMAIN]) (any, bool) {
+	if *constraint == nil || !reflect.TypeOf(*constraint).Comparable() {
+		return nil, false
+	}
+	return *constraint, true
+}
+
+func constraintIssues[VAR comparable, DOMAIN comparable](constraint *Constraint[VAR, DOMAIN], contains func(VAR) bool, seen map[any]bool) []ValidationIssue[VAR, DOMAIN] {
+	issues := []ValidationIssue[VAR, DOMAIN]{}
+	if key, ok := constraintKey(constraint); ok && seen[key] {
+		issues = append(issues, ValidationIssue[VAR, DOMAIN]{
+			Kind:       VALIDATION_DUPLICATE_CONSTRAINT,
+			Constraint: constraint,
+			Message:    fmt.Sprintf("%s was added more than once", constraintLabel[VAR, DOMAIN](*constraint, nil)),
+		})
+	}
+	local := (*constraint).AsLocal()
+	if local == nil {
+		return issues
+	}
+	scope := (*local).GetVariables()
+	unknown := []VAR{}
+	repeated := map[VAR]bool{}
+	for i, variable := range scope {
+		if !contains(variable) {
+			unknown = append(unknown, variable)
+		}
+		for _, other := range scope[:i] {
+			if other == variable {
+				repeated[variable] = true
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		issues = append(issues, ValidationIssue[VAR, DOMAIN]{
+			Kind:       VALIDATION_UNKNOWN_VARIABLE,
+			Variables:  unknown,
+			Constraint: constraint,
+			Message:    fmt.Sprintf("%s references variables %v missing from the domain map", constraintLabel(*constraint, scope), unknown),
+		})
+	}
+	if len(scope) == 0 || len(repeated) > 0 {
+		issues = append(issues, ValidationIssue[VAR, DOMAIN]{
+			Kind:       VALIDATION_ARITY_MISMATCH,
+			Constraint: constraint,
+			Message:    fmt.Sprintf("%s has %d variables but %d distinct ones", constraintLabel(*constraint, scope), len(scope), len(scope)-len(repeated)),
+		})
+	}
+	return issues
+}
+
+// validate reports the issues of every constraint added so far and of the csp's domains.
+func (V *cspValidator[VAR, DOMAIN]) validate(csp CSP[VAR, DOMAIN]) []ValidationIssue[VAR, DOMAIN] {
+	issues := []ValidationIssue[VAR, DOMAIN]{}
+	seen := map[any]bool{}
+	constrained := map[VAR]bool{}
+	hasGlobal := false
+	for _, constraint := range V.added {
+		issues = append(issues, constraintIssues(constraint, csp.Contains, seen)...)
+		if key, ok := constraintKey(constraint); ok {
+			seen[key] = true
+		}
+		local := (*constraint).AsLocal()
+		if local == nil {
+			hasGlobal = true
+			continue
+		}
+		for _, variable := range (*local).GetVariables() {
+			constrained[variable] = true
+		}
+	}
+	for _, variable := range csp.GetVariables() {
+		if len(csp.GetDomainForVariable(variable)) == 0 {
+			issues = append(issues, ValidationIssue[VAR, DOMAIN]{
+				Kind:      VALIDATION_EMPTY_DOMAIN,
+				Variables: []VAR{variable},
+				Message:   fmt.Sprintf("%v has no values", variable),
+			})
+		}
+		if !hasGlobal && !constrained[variable] {
+			issues = append(issues, ValidationIssue[VAR, DOMAIN]{
+				Kind:      VALIDATION_UNCONSTRAINED_VARIABLE,
+				Variables: []VAR{variable},
+				Message:   fmt.Sprintf("%v is not in any constraint", variable),
+			})
+		}
+	}
+	return issues
+}
+
+// addAll adds every constraint, returning the errors of the ones strict mode rejected.
+func addAll[VAR comparable, DOMAIN comparable](add func(*Constraint[VAR, DOMAIN]) error, constraints []*Constraint[VAR, DOMAIN]) error {
+	errs := []error{}
+	for _, constraint := range constraints {
+		if err := add(constraint); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package gointel
+
+import (
+	"errors"
+	"testing"
+)
+
+func issueKinds[VAR comparable, DOMAIN comparable](issues []ValidationIssue[VAR, DOMAIN]) map[ValidationIssueKind]int {
+	ret := map[ValidationIssueKind]int{}
+	for _, issue := range issues {
+		ret[issue.Kind]++
+	}
+	return ret
+}
+
+func TestCSPDomain_Validate(t *testing.T) {
+	csp := NewCSPDomain(map[string][]int{"A": {1, 2}, "B": {1, 2}, "C": {}, "D": {1}})
+	var ab Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"A", "B"}}
+	var unknown Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"A", "Z"}}
+	var repeated Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"C", "C"}}
+	csp.AddAllConstraints(&ab, &unknown, &repeated, &ab)
+
+	kinds := issueKinds(csp.Validate())
+	expected := map[ValidationIssueKind]int{
+		VALIDATION_UNKNOWN_VARIABLE:       1,
+		VALIDATION_EMPTY_DOMAIN:           1,
+		VALIDATION_UNCONSTRAINED_VARIABLE: 1,
+		VALIDATION_DUPLICATE_CONSTRAINT:   1,
+		VALIDATION_ARITY_MISMATCH:         1,
+	}
+	for kind, count := range expected {
+		if kinds[kind] != count {
+			t.Errorf("expected %d %v issues, got %d", count, kind, kinds[kind])
+		}
+	}
+}
+
+func TestStrictAddConstraint(t *testing.T) {
+	csps := []CSP[string, int]{
+		NewCSPDomain(map[string][]int{"A": {1, 2}, "B": {1, 2}}),
+		NewCSPTree(map[string][]int{"A": {1, 2}, "B": {1, 2}}),
+		NewDecomposedCSP(map[string][]int{"A": {1, 2}, "B": {1, 2}}),
+	}
+	csps[0].(*CSPDomain[string, int]).Strict = true
+	csps[1].(*CSPTree[string, int]).Strict = true
+	csps[2].(*DecomposedCSP[string, int]).Strict = true
+	for _, csp := range csps {
+		var ab Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"A", "B"}}
+		var unknown Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"A", "Z"}}
+		if err := csp.AddConstraint(&ab); err != nil {
+			t.Errorf("%T: expected a valid constraint to be added, got %v", csp, err)
+		}
+		err := csp.AddAllConstraints(&unknown, &ab)
+		var issue ValidationIssue[string, int]
+		if !errors.As(err, &issue) || issue.Kind != VALIDATION_UNKNOWN_VARIABLE {
+			t.Errorf("%T: expected an unknown variable error, got %v", csp, err)
+		}
+		if len(csp.GetLocalConstraints()["A"]) != 1 {
+			t.Errorf("%T: expected rejected constraints to be left out, got %d", csp, len(csp.GetLocalConstraints()["A"]))
+		}
+	}
+}
+
+func TestUnconstrainedVariable(t *testing.T) {
+	csps := []CSP[string, int]{
+		NewCSPDomain(map[string][]int{"A": {1, 2}, "B": {1, 2}, "C": {1, 2, 3}}),
+		NewCSPTree(map[string][]int{"A": {1, 2}, "B": {1, 2}, "C": {1, 2, 3}}),
+	}
+	for _, csp := range csps {
+		var ab Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"A", "B"}}
+		csp.AddConstraint(&ab)
+		if solutions := csp.FindAllSolutions(); len(solutions) != 6 {
+			t.Errorf("%T: expected the free variable to take all 3 values in 6 solutions, got %d", csp, len(solutions))
+		}
+	}
+}
+
+func TestStrictErrorsReachCallers(t *testing.T) {
+	domainMap := map[string][]int{"A": {1, 2}, "B": {1, 2}}
+	var unknown Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"A", "Z"}}
+	newStrict := func(domainMap map[string][]int) CSP[string, int] {
+		csp := NewCSPDomain(domainMap)
+		csp.Strict = true
+		return csp
+	}
+
+	if _, err := finishCSP(newStrict(CloneMapWithSlices(domainMap)), CSPFactoryRequest[string, int]{Constraints: []*Constraint[string, int]{&unknown}}); err == nil {
+		t.Errorf("expected finishCSP to return the rejected constraint")
+	}
+
+	session := NewCSPSession(CloneMapWithSlices(domainMap))
+	session.NewCSP = newStrict
+	session.Add(&unknown)
+	if _, err := session.TryFindOneSolutionUnder(nil); err == nil {
+		t.Errorf("expected the session to return the rejected constraint")
+	}
+
+	strict := newStrict(CloneMapWithSlices(domainMap)).(*CSPDomain[string, int])
+	swap := NewVariableSymmetry[string, int](map[string]string{"A": "Z", "Z": "A"})
+	if err := strict.BreakSymmetries([]string{"A", "Z"}, func(a, b int) int { return a - b }, swap); err == nil {
+		t.Errorf("expected BreakSymmetries to return the rejected constraint")
+	}
+}
@@ -0,0 +1,169 @@
+package gointel
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// cspNodeDeque is a worker's share of the open frontier. The owner works depth first from the tail
+// while idle workers steal from the head, where the shallow nodes with the largest subtrees are.
+type cspNodeDeque[VAR comparable, DOMAIN comparable] struct {
+	mutex sync.Mutex
+	nodes []CSPNode[VAR, DOMAIN]
+}
+
+func (d *cspNodeDeque[VAR, DOMAIN]) push(nodes ...CSPNode[VAR, DOMAIN]) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.nodes = append(d.nodes, nodes...)
+}
+
+func (d *cspNodeDeque[VAR, DOMAIN]) pop() (CSPNode[VAR, DOMAIN], bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.nodes) == 0 {
+		return CSPNode[VAR, DOMAIN]{}, false
+	}
+	last := d.nodes[len(d.nodes)-1]
+	d.nodes = d.nodes[:len(d.nodes)-1]
+	return last, true
+}
+
+func (d *cspNodeDeque[VAR, DOMAIN]) isEmpty() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.nodes) == 0
+}
+
+// stealHalf removes the oldest half of the nodes, rounding up.
+func (d *cspNodeDeque[VAR, DOMAIN]) stealHalf() []CSPNode[VAR, DOMAIN] {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	count := (len(d.nodes) + 1) / 2
+	stolen := make([]CSPNode[VAR, DOMAIN], count)
+	copy(stolen, d.nodes[:count])
+	d.nodes = d.nodes[count:]
+	return stolen
+}
+
+// cspWorkStealingSearch expands the frontier of a single agent on a bounded number of workers.
+type cspWorkStealingSearch[VAR comparable, DOMAIN comparable] struct {
+	agent   *CSPAgent[VAR, DOMAIN]
+	deques  []*cspNodeDeque[VAR, DOMAIN]
+	pending atomic.Int64
+	stopped atomic.Bool
+	// idle parks the workers that found nothing to steal until nodes are pushed or the search ends, parked counts them.
+	idle   *sync.Cond
+	parked atomic.Int64
+}
+
+// newCSPWorkStealingSearch deals the agent's stack out to the workers; parallelism defaults to runtime.NumCPU().
+func newCSPWorkStealingSearch[VAR comparable, DOMAIN comparable](agent *CSPAgent[VAR, DOMAIN], parallelism int) *cspWorkStealingSearch[VAR, DOMAIN] {
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+	ret := &cspWorkStealingSearch[VAR, DOMAIN]{
+		agent:  agent,
+		deques: make([]*cspNodeDeque[VAR, DOMAIN], parallelism),
+		idle:   sync.NewCond(&sync.Mutex{}),
+	}
+	for i := range ret.deques {
+		ret.deques[i] = &cspNodeDeque[VAR, DOMAIN]{}
+	}
+	// Push in reverse so the first node of each deque is explored first
+	for i := len(agent.Stack) - 1; i >= 0; i-- {
+		ret.deques[i%parallelism].push(agent.Stack[i])
+	}
+	ret.pending.Store(int64(len(agent.Stack)))
+	return ret
+}
+
+// run blocks until the frontier is exhausted, ctx is done or emit returns false.
+// emit is called concurrently from every worker.
+func (s *cspWorkStealingSearch[VAR, DOMAIN]) run(ctx context.Context, emit func(map[VAR]DOMAIN) bool) {
+	stop := context.AfterFunc(ctx, s.wake)
+	defer stop()
+	var wg sync.WaitGroup
+	for id := range s.deques {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			s.work(ctx, id, emit)
+		}(id)
+	}
+	wg.Wait()
+}
+
+func (s *cspWorkStealingSearch[VAR, DOMAIN]) work(ctx context.Context, id int, emit func(map[VAR]DOMAIN) bool) {
+	own := s.deques[id]
+	trail := newCSPTrail[VAR, DOMAIN]()
+	for ctx.Err() == nil && !s.stopped.Load() {
+		current, ok := own.pop()
+		if !ok {
+			// Every open node is counted in pending, including the ones in flight between deques
+			if s.pending.Load() == 0 {
+				return
+			}
+			if !s.steal(id) {
+				s.park(ctx)
+			}
+			continue
+		}
+		solution, children := s.agent.expand(&current, trail.moveTo(&current))
+		if solution != nil && !emit(solution) {
+			s.stopped.Store(true)
+			s.wake()
+		}
+		// Children are counted before they can be stolen, so pending never drops to zero while they are open
+		s.pending.Add(int64(len(children)))
+		for i := len(children) - 1; i >= 0; i-- {
+			own.push(children[i])
+		}
+		if len(children) > 0 && s.parked.Load() > 0 {
+			s.wake()
+		}
+		if s.pending.Add(-1) == 0 {
+			s.wake()
+		}
+	}
+}
+
+func (s *cspWorkStealingSearch[VAR, DOMAIN]) steal(id int) bool {
+	for offset := 1; offset < len(s.deques); offset++ {
+		victim := s.deques[(id+offset)%len(s.deques)]
+		if stolen := victim.stealHalf(); len(stolen) > 0 {
+			s.deques[id].push(stolen...)
+			return true
+		}
+	}
+	return false
+}
+
+// park waits until another deque has nodes to steal or the search ends. Workers count themselves as parked before
+// looking at the deques, so a push either is seen here or wakes them.
+func (s *cspWorkStealingSearch[VAR, DOMAIN]) park(ctx context.Context) {
+	s.idle.L.Lock()
+	defer s.idle.L.Unlock()
+	s.parked.Add(1)
+	defer s.parked.Add(-1)
+	for s.pending.Load() > 0 && !s.stopped.Load() && ctx.Err() == nil && !s.hasWork() {
+		s.idle.Wait()
+	}
+}
+
+func (s *cspWorkStealingSearch[VAR, DOMAIN]) hasWork() bool {
+	for _, deque := range s.deques {
+		if !deque.isEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *cspWorkStealingSearch[VAR, DOMAIN]) wake() {
+	s.idle.L.Lock()
+	defer s.idle.L.Unlock()
+	s.idle.Broadcast()
+}
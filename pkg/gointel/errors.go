@@ -0,0 +1,16 @@
+package gointel
+
+import "errors"
+
+var (
+	// ErrInconsistent is returned when propagation wipes out the domain of a variable, so the CSP has no solution.
+	ErrInconsistent = errors.New("inconsistent constraints")
+	// ErrShapeMismatch is returned when inputs or targets don't match the size of the layer they are fed to.
+	ErrShapeMismatch = errors.New("shape mismatch")
+	// ErrEmptyDataset is returned when training is asked to learn from no samples.
+	ErrEmptyDataset = errors.New("empty dataset")
+	// ErrUnsupported is returned for model features a reader or writer doesn't handle.
+	ErrUnsupported = errors.New("unsupported")
+	// ErrUnknownSolver is returned when no CSP factory is registered under the requested name.
+	ErrUnknownSolver = errors.New("unknown solver")
+)
@@ -0,0 +1,91 @@
+package gointel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNeuralNetworkErrors(t *testing.T) {
+	nn := NewNeuralNetwork([]int{2, 3, 1}, 0.1, 0.9, 0.01)
+	if _, err := nn.TryForward([]float64{1}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch from TryForward, got %v", err)
+	}
+	if _, err := nn.TryTrain([]float64{0, 1}, []float64{1, 0}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch from TryTrain, got %v", err)
+	}
+	if _, _, err := nn.TryTrainEpoch(nil); !errors.Is(err, ErrEmptyDataset) {
+		t.Errorf("expected ErrEmptyDataset from TryTrainEpoch, got %v", err)
+	}
+	badData := []struct{ inputs, targets []float64 }{
+		{[]float64{0, 0}, []float64{0}},
+		{[]float64{0, 1, 1}, []float64{1}},
+	}
+	if _, _, err := nn.TryTrainEpoch(badData); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch from TryTrainEpoch, got %v", err)
+	}
+	if _, err := TryOptimizeNNLayers(2, 1, nil, 10, 1, 0.1, 10, 1, 0.1, 0.9, sigmoid, sigmoidDerivative); !errors.Is(err, ErrEmptyDataset) {
+		t.Errorf("expected ErrEmptyDataset from TryOptimizeNNLayers, got %v", err)
+	}
+	if _, err := TryNewInferredNeuralNetwork(2, 1, badData, 10, 1, 0.1, 10, 1, 0.1, 0.9, 0.01, sigmoid, sigmoidDerivative); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch from TryNewInferredNeuralNetwork, got %v", err)
+	}
+	if output, err := nn.TryForward([]float64{0, 1}); err != nil || len(output) != 1 {
+		t.Errorf("expected one output, got %v, %v", output, err)
+	}
+}
+
+func TestAC3PreprocessorInconsistent(t *testing.T) {
+	var csp CSP[string, int] = NewCSPDomain(map[string][]int{"A": {1}, "B": {1}, "C": {1, 2}})
+	csp.AddConstraint(notEqualInts("A", "B"))
+	csp.AddConstraint(notEqualInts("B", "C"))
+	if err := (&AC3Preprocessor[string, int]{}).TryPreprocess(&csp); !errors.Is(err, ErrInconsistent) {
+		t.Errorf("expected ErrInconsistent, got %v", err)
+	}
+
+	var intCSP CSP[int, int] = NewIntCSP(map[int][]int{0: {1}, 1: {1}})
+	var allDifferent Constraint[int, int] = &LocalAllDifferentConstraint[int, int]{Variables: &[]int{0, 1}}
+	intCSP.AddConstraint(&allDifferent)
+	if err := (&IntAC3Preprocessor{}).TryPreprocess(&intCSP); !errors.Is(err, ErrInconsistent) {
+		t.Errorf("expected ErrInconsistent from IntAC3Preprocessor, got %v", err)
+	}
+
+	if _, err := TryDefaultCSPFactory(CSPFactoryRequest[string, int]{Solver: "missing"}); !errors.Is(err, ErrUnknownSolver) {
+		t.Errorf("expected ErrUnknownSolver, got %v", err)
+	}
+}
+
+func TestTryFindSolutionInconsistent(t *testing.T) {
+	domainMap := map[string][]int{"A": {1}, "B": {1}, "C": {1, 2}}
+	domain := NewCSPDomain(CloneMapWithSlices(domainMap), &AC3Preprocessor[string, int]{})
+	tree := NewCSPTree(CloneMapWithSlices(domainMap), &AC3Preprocessor[string, int]{})
+	for _, csp := range []CSP[string, int]{domain, tree} {
+		csp.AddConstraint(notEqualInts("A", "B"))
+		csp.AddConstraint(notEqualInts("B", "C"))
+	}
+	if solution, err := domain.TryFindOneSolution(); solution != nil || !errors.Is(err, ErrInconsistent) {
+		t.Errorf("expected ErrInconsistent from CSPDomain, got %v and %v", solution, err)
+	}
+	if solutions, err := tree.TryFindAllSolutions(); len(solutions) != 0 || !errors.Is(err, ErrInconsistent) {
+		t.Errorf("expected ErrInconsistent from CSPTree, got %v and %v", solutions, err)
+	}
+
+	intCSP := NewIntCSP(map[int][]int{0: {1}, 1: {1}}, &IntAC3Preprocessor{})
+	var allDifferent Constraint[int, int] = &LocalAllDifferentConstraint[int, int]{Variables: &[]int{0, 1}}
+	intCSP.AddConstraint(&allDifferent)
+	if solution, err := intCSP.TryFindOneSolution(); solution != nil || !errors.Is(err, ErrInconsistent) {
+		t.Errorf("expected ErrInconsistent from IntCSP, got %v and %v", solution, err)
+	}
+
+	// A consistent model that the search proves unsatisfiable is not an error
+	queensDomains, queensConstraints := queensModel(3)
+	queens := NewCSPDomain(queensDomains, &AC3Preprocessor[int, int]{})
+	queens.AddAllConstraints(queensConstraints...)
+	if solutions, err := queens.TryFindAllSolutions(); len(solutions) != 0 || err != nil {
+		t.Errorf("expected no solutions and no error for 3 queens, got %v and %v", solutions, err)
+	}
+}
+
+func notEqualInts(a, b string) *Constraint[string, int] {
+	var c Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{a, b}}
+	return &c
+}
@@ -73,9 +73,19 @@ func (nn *NeuralNetwork) SetActivation(activation ActivationFunc, derivative Act
 	nn.activationDerivative = derivative
 }
 
+// Forward panics when the inputs don't match the input layer, see TryForward.
 func (nn *NeuralNetwork) Forward(inputs []float64) []float64 {
+	ret, err := nn.TryForward(inputs)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// TryForward returns ErrShapeMismatch when the inputs don't match the input layer.
+func (nn *NeuralNetwork) TryForward(inputs []float64) ([]float64, error) {
 	if len(inputs) != nn.layers[0] {
-		panic("Invalid input size")
+		return nil, fmt.Errorf("%w: invalid input size. Expected %d, got %d", ErrShapeMismatch, nn.layers[0], len(inputs))
 	}
 
 	currentLayer := inputs
@@ -92,15 +102,22 @@ func (nn *NeuralNetwork) Forward(inputs []float64) []float64 {
 		currentLayer = nextLayer
 	}
 
-	return currentLayer
+	return currentLayer, nil
 }
 
+// Train panics when the inputs or targets don't match the network, see TryTrain.
 func (nn *NeuralNetwork) Train(inputs []float64, targets []float64) float64 {
-	if len(inputs) != nn.layers[0] {
-		panic("Invalid input size")
+	ret, err := nn.TryTrain(inputs, targets)
+	if err != nil {
+		panic(err)
 	}
-	if len(targets) != nn.layers[len(nn.layers)-1] {
-		panic(fmt.Sprintf("Invalid target size. Expected %d, got %d", nn.layers[len(nn.layers)-1], len(targets)))
+	return ret
+}
+
+// TryTrain returns ErrShapeMismatch without touching the weights when the inputs or targets don't match the network.
+func (nn *NeuralNetwork) TryTrain(inputs []float64, targets []float64) (float64, error) {
+	if err := nn.checkSample(inputs, targets); err != nil {
+		return 0, err
 	}
 
 	activations := make([][]float64, len(nn.layers))
@@ -154,18 +171,47 @@ func (nn *NeuralNetwork) Train(inputs []float64, targets []float64) float64 {
 		}
 	}
 
-	return totalError / float64(nn.layers[lastLayer])
+	return totalError / float64(nn.layers[lastLayer]), nil
 }
 
+// TrainEpoch panics when a sample doesn't match the network, see TryTrainEpoch.
 func (nn *NeuralNetwork) TrainEpoch(trainingData []struct{ inputs, targets []float64 }) (float64, bool) {
+	avgError, done, err := nn.TryTrainEpoch(trainingData)
+	if err != nil {
+		panic(err)
+	}
+	return avgError, done
+}
+
+// TryTrainEpoch returns ErrEmptyDataset for no samples, and ErrShapeMismatch for a sample that doesn't match the
+// network. Every sample is checked before training, so a bad one leaves the weights unchanged.
+func (nn *NeuralNetwork) TryTrainEpoch(trainingData []struct{ inputs, targets []float64 }) (float64, bool, error) {
+	if len(trainingData) == 0 {
+		return 0, false, ErrEmptyDataset
+	}
+	for i, sample := range trainingData {
+		if err := nn.checkSample(sample.inputs, sample.targets); err != nil {
+			return 0, false, fmt.Errorf("sample %d: %w", i, err)
+		}
+	}
 	indices := rand.Perm(len(trainingData))
 	epochError := 0.0
 	for _, idx := range indices {
-		err := nn.Train(trainingData[idx].inputs, trainingData[idx].targets)
-		epochError += err
+		sampleError, _ := nn.TryTrain(trainingData[idx].inputs, trainingData[idx].targets)
+		epochError += sampleError
 	}
 	avgError := epochError / float64(len(trainingData))
-	return avgError, avgError <= nn.errorThreshold
+	return avgError, avgError <= nn.errorThreshold, nil
+}
+
+func (nn *NeuralNetwork) checkSample(inputs []float64, targets []float64) error {
+	if len(inputs) != nn.layers[0] {
+		return fmt.Errorf("%w: invalid input size. Expected %d, got %d", ErrShapeMismatch, nn.layers[0], len(inputs))
+	}
+	if len(targets) != nn.layers[len(nn.layers)-1] {
+		return fmt.Errorf("%w: invalid target size. Expected %d, got %d", ErrShapeMismatch, nn.layers[len(nn.layers)-1], len(targets))
+	}
+	return nil
 }
 
 type NNLayerState struct {
@@ -310,16 +356,36 @@ func OptimizeNNLayers(
 	activation ActivationFunc,
 	activationDerivative ActivationFunc,
 ) []int {
-	if len(trainingData) == 0 {
-		panic("No training data provided")
+	ret, err := TryOptimizeNNLayers(inputSize, outputSize, trainingData, initialTemp, finalTemp, coolingRate,
+		iterationsPerTemp, epochs, learningRate, momentum, activation, activationDerivative)
+	if err != nil {
+		panic(err)
 	}
+	return ret
+}
 
-	if len(trainingData[0].targets) != outputSize {
-		panic(fmt.Sprintf("Output size mismatch. Expected %d, got %d", outputSize, len(trainingData[0].targets)))
+// TryOptimizeNNLayers returns ErrEmptyDataset for no training data, and ErrShapeMismatch when a sample doesn't match
+// inputSize and outputSize.
+func TryOptimizeNNLayers(
+	inputSize, outputSize int,
+	trainingData []struct{ inputs, targets []float64 },
+	initialTemp, finalTemp, coolingRate float64,
+	iterationsPerTemp, epochs int,
+	learningRate, momentum float64,
+	activation ActivationFunc,
+	activationDerivative ActivationFunc,
+) ([]int, error) {
+	if len(trainingData) == 0 {
+		return nil, ErrEmptyDataset
 	}
 
-	if len(trainingData[0].inputs) != inputSize {
-		panic(fmt.Sprintf("Input size mismatch. Expected %d, got %d", inputSize, len(trainingData[0].inputs)))
+	for i, sample := range trainingData {
+		if len(sample.targets) != outputSize {
+			return nil, fmt.Errorf("%w: sample %d output size. Expected %d, got %d", ErrShapeMismatch, i, outputSize, len(sample.targets))
+		}
+		if len(sample.inputs) != inputSize {
+			return nil, fmt.Errorf("%w: sample %d input size. Expected %d, got %d", ErrShapeMismatch, i, inputSize, len(sample.inputs))
+		}
 	}
 
 	initialState := &NNLayerState{
@@ -340,7 +406,7 @@ func OptimizeNNLayers(
 	sa := NewSimulatedAnnealing(initialTemp, finalTemp, coolingRate, iterationsPerTemp)
 	bestState := sa.Optimize(initialState)
 
-	return bestState.(*NNLayerState).layers
+	return bestState.(*NNLayerState).layers, nil
 }
 
 func NewInferredNeuralNetwork(
@@ -352,7 +418,25 @@ func NewInferredNeuralNetwork(
 	activation ActivationFunc,
 	activationDerivative ActivationFunc,
 ) *NeuralNetwork {
-	layers := OptimizeNNLayers(inputSize,
+	ret, err := TryNewInferredNeuralNetwork(inputSize, outputSize, trainingData, initialTemp, finalTemp, coolingRate,
+		iterationsPerTemp, epochs, learningRate, momentum, errorThreshold, activation, activationDerivative)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// TryNewInferredNeuralNetwork returns the errors of TryOptimizeNNLayers instead of panicking.
+func TryNewInferredNeuralNetwork(
+	inputSize, outputSize int,
+	trainingData []struct{ inputs, targets []float64 },
+	initialTemp, finalTemp, coolingRate float64,
+	iterationsPerTemp, epochs int,
+	learningRate, momentum, errorThreshold float64,
+	activation ActivationFunc,
+	activationDerivative ActivationFunc,
+) (*NeuralNetwork, error) {
+	layers, err := TryOptimizeNNLayers(inputSize,
 		outputSize,
 		trainingData,
 		initialTemp,
@@ -364,7 +448,10 @@ func NewInferredNeuralNetwork(
 		momentum,
 		activation,
 		activationDerivative)
+	if err != nil {
+		return nil, err
+	}
 	ret := NewNeuralNetwork(layers, learningRate, momentum, errorThreshold)
 	ret.SetActivation(activation, activationDerivative)
-	return ret
+	return ret, nil
 }
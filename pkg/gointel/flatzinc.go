@@ -0,0 +1,820 @@
+package gointel
+
+import (
+	"context"
+	"fmt"
+	"github.com/mtresnik/goutils/pkg/goutils"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FlatZincModel is a FlatZinc model read by ReadFlatZinc. Arrays and aliases are resolved while reading, so only
+// the FlatZinc variables themselves end up in DomainMap, bools as 0 and 1.
+type FlatZincModel struct {
+	DomainMap   map[string][]int
+	Constraints []*Constraint[string, int]
+	// Objective is the variable to minimize, or to maximize when Maximize is set. It is empty for solve satisfy.
+	Objective    string
+	Maximize     bool
+	outputs      []fznOutput
+	inconsistent bool
+}
+
+// fznTerm is an argument of a constraint, a variable or a constant.
+type fznTerm struct {
+	variable string
+	value    int
+}
+
+// fznOutput is a variable or an array annotated with output_var or output_array.
+type fznOutput struct {
+	name       string
+	isBool     bool
+	dimensions [][2]int
+	terms      []fznTerm
+}
+
+// ReadFlatZinc parses a FlatZinc model with int and bool variables, the constraints int_lin_eq, int_lin_le,
+// int_lin_ne, int_eq, int_ne, int_le, int_lt, all_different_int, table_int, bool2int, bool_eq, bool_not and
+// bool_clause, and solve satisfy, minimize or maximize. Other constraints and unbounded variables return
+// ErrUnsupported.
+func ReadFlatZinc(r io.Reader) (*FlatZincModel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenizeFlatZinc(string(data))
+	if err != nil {
+		return nil, err
+	}
+	reader := &fznReader{
+		tokens:    tokens,
+		model:     &FlatZincModel{DomainMap: map[string][]int{}},
+		constants: map[string]fznTerm{},
+		aliases:   map[string]fznTerm{},
+		arrays:    map[string][]fznTerm{},
+	}
+	for !reader.done() {
+		if err := reader.readItem(); err != nil {
+			return nil, err
+		}
+	}
+	return reader.model, nil
+}
+
+// NewCSP builds a CSPDomain over a copy of the model's domains.
+func (M *FlatZincModel) NewCSP() (*CSPDomain[string, int], error) {
+	domainMap := CloneMapWithSlices(M.DomainMap)
+	if M.inconsistent {
+		for variable := range domainMap {
+			domainMap[variable] = []int{}
+		}
+	}
+	csp := NewCSPDomain(domainMap)
+	if err := csp.AddAllConstraints(M.Constraints...); err != nil {
+		return nil, err
+	}
+	return csp, nil
+}
+
+// Solve calls onSolution with every solution up to limit when all is set, or with the first one otherwise. With an
+// objective it calls onSolution with each improving solution instead, the last one being the best. It returns true
+// when the search finished before ctx was done, so the solutions are all there is, or the last one is optimal.
+func (M *FlatZincModel) Solve(ctx context.Context, all bool, limit int, onSolution func(map[string]int)) (bool, error) {
+	csp, err := M.NewCSP()
+	if err != nil {
+		return false, err
+	}
+	if M.Objective == "" {
+		if !all {
+			if solution := csp.FindOneSolutionContext(ctx); solution != nil {
+				onSolution(solution)
+				return true, nil
+			}
+			return ctx.Err() == nil, nil
+		}
+		searchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		found := 0
+		for solution := range csp.GenerateSolutionChannelContext(searchCtx) {
+			onSolution(solution)
+			found++
+			if limit > 0 && found >= limit {
+				return true, nil
+			}
+		}
+		return ctx.Err() == nil, nil
+	}
+	operator := SUM_LT
+	if M.Maximize {
+		operator = SUM_GT
+	}
+	for {
+		solution := csp.FindOneSolutionContext(ctx)
+		if solution == nil {
+			return ctx.Err() == nil, nil
+		}
+		onSolution(solution)
+		var bound Constraint[string, int] = NewLinearSumConstraint([]string{M.Objective}, nil, operator, solution[M.Objective], M.DomainMap)
+		if err := csp.AddConstraint(&bound); err != nil {
+			return false, err
+		}
+	}
+}
+
+const (
+	FLATZINC_SOLUTION_SEPARATOR = "----------"
+	FLATZINC_SEARCH_COMPLETE    = "=========="
+	FLATZINC_UNSATISFIABLE      = "=====UNSATISFIABLE====="
+	FLATZINC_UNKNOWN            = "=====UNKNOWN====="
+)
+
+// WriteSolution prints the output variables and arrays of the solution followed by the solution separator.
+func (M *FlatZincModel) WriteSolution(w io.Writer, solution map[string]int) error {
+	builder := &strings.Builder{}
+	for _, output := range M.outputs {
+		values := make([]string, len(output.terms))
+		for i, term := range output.terms {
+			value := term.value
+			if term.variable != "" {
+				value = solution[term.variable]
+			}
+			values[i] = strconv.Itoa(value)
+			if output.isBool {
+				values[i] = strconv.FormatBool(value != 0)
+			}
+		}
+		if output.dimensions == nil {
+			fmt.Fprintf(builder, "%s = %s;\n", output.name, values[0])
+			continue
+		}
+		ranges := make([]string, len(output.dimensions))
+		for i, dimension := range output.dimensions {
+			ranges[i] = fmt.Sprintf("%d..%d", dimension[0], dimension[1])
+		}
+		fmt.Fprintf(builder, "%s = array%dd(%s, [%s]);\n", output.name, len(ranges), strings.Join(ranges, ", "), strings.Join(values, ", "))
+	}
+	builder.WriteString(FLATZINC_SOLUTION_SEPARATOR + "\n")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// WriteSolutions solves the model and prints the solutions in the FlatZinc output format, ending with the
+// search complete, unsatisfiable or unknown marker when one applies.
+func (M *FlatZincModel) WriteSolutions(ctx context.Context, w io.Writer, all bool, limit int) error {
+	found := 0
+	var err error
+	complete, solveErr := M.Solve(ctx, all, limit, func(solution map[string]int) {
+		found++
+		if err == nil {
+			err = M.WriteSolution(w, solution)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if solveErr != nil {
+		return solveErr
+	}
+	switch {
+	case found == 0 && complete:
+		_, err = fmt.Fprintln(w, FLATZINC_UNSATISFIABLE)
+	case found == 0:
+		_, err = fmt.Fprintln(w, FLATZINC_UNKNOWN)
+	case complete && (all || M.Objective != "") && (limit == 0 || found < limit):
+		_, err = fmt.Fprintln(w, FLATZINC_SEARCH_COMPLETE)
+	}
+	return err
+}
+
+func tokenizeFlatZinc(text string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(text); {
+		ch := rune(text[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '%':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case ch == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, text[i:i+end+2])
+			i += end + 2
+		case strings.HasPrefix(text[i:], "::") || strings.HasPrefix(text[i:], ".."):
+			tokens = append(tokens, text[i:i+2])
+			i += 2
+		case strings.ContainsRune("[](){},:;=", ch):
+			tokens = append(tokens, string(ch))
+			i++
+		case ch == '-' || ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch):
+			start := i
+			i++
+			for i < len(text) && (text[i] == '_' || unicode.IsLetter(rune(text[i])) || unicode.IsDigit(rune(text[i]))) {
+				i++
+			}
+			tokens = append(tokens, text[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected %q", ch)
+		}
+	}
+	return tokens, nil
+}
+
+// fznExpr is a parsed FlatZinc expression: an identifier, an integer, a range, a set, an array or a call.
+type fznExpr struct {
+	identifier string
+	value      int
+	isInt      bool
+	isRange    bool
+	low, high  int
+	set        []int
+	isSet      bool
+	elements   []fznExpr
+	isArray    bool
+	index      int
+	isIndexed  bool
+}
+
+type fznReader struct {
+	tokens    []string
+	position  int
+	model     *FlatZincModel
+	constants map[string]fznTerm
+	aliases   map[string]fznTerm
+	arrays    map[string][]fznTerm
+}
+
+func (R *fznReader) done() bool {
+	return R.position >= len(R.tokens)
+}
+
+func (R *fznReader) peek() string {
+	if R.done() {
+		return ""
+	}
+	return R.tokens[R.position]
+}
+
+func (R *fznReader) next() string {
+	token := R.peek()
+	R.position++
+	return token
+}
+
+func (R *fznReader) expect(token string) error {
+	if next := R.next(); next != token {
+		return fmt.Errorf("expected %q, got %q", token, next)
+	}
+	return nil
+}
+
+func (R *fznReader) skipItem() {
+	for !R.done() && R.next() != ";" {
+	}
+}
+
+func (R *fznReader) readItem() error {
+	switch R.peek() {
+	case "predicate":
+		R.skipItem()
+		return nil
+	case "constraint":
+		R.next()
+		return R.readConstraint()
+	case "solve":
+		R.next()
+		return R.readSolve()
+	case "array":
+		return R.readArray()
+	}
+	return R.readDeclaration()
+}
+
+// fznType is the type of a declaration: var or par, bool or int, and the domain of an int var.
+type fznType struct {
+	isVar  bool
+	isBool bool
+	domain []int
+}
+
+// readType reads a type, var int is only allowed for arrays, which refer to variables declared before.
+func (R *fznReader) readType(array bool) (fznType, error) {
+	ret := fznType{}
+	if R.peek() == "var" {
+		R.next()
+		ret.isVar = true
+	}
+	switch token := R.peek(); {
+	case token == "bool":
+		R.next()
+		ret.isBool = true
+		ret.domain = []int{0, 1}
+	case token == "int":
+		R.next()
+		if ret.isVar && !array {
+			return ret, fmt.Errorf("%w: unbounded var int", ErrUnsupported)
+		}
+	case token == "set" || token == "float":
+		return ret, fmt.Errorf("%w: %s variables", ErrUnsupported, token)
+	default:
+		expr, err := R.readExpr()
+		if err != nil {
+			return ret, err
+		}
+		switch {
+		case expr.isRange:
+			for value := expr.low; value <= expr.high; value++ {
+				ret.domain = append(ret.domain, value)
+			}
+		case expr.isSet:
+			ret.domain = expr.set
+		default:
+			return ret, fmt.Errorf("bad type %q", token)
+		}
+	}
+	return ret, nil
+}
+
+// readAnnotations returns the annotations by name with their arguments.
+func (R *fznReader) readAnnotations() (map[string][]fznExpr, error) {
+	ret := map[string][]fznExpr{}
+	for R.peek() == "::" {
+		R.next()
+		expr, err := R.readExpr()
+		if err != nil {
+			return nil, err
+		}
+		ret[expr.identifier] = expr.elements
+	}
+	return ret, nil
+}
+
+func (R *fznReader) readDeclaration() error {
+	declared, err := R.readType(false)
+	if err != nil {
+		return err
+	}
+	if err := R.expect(":"); err != nil {
+		return err
+	}
+	name := R.next()
+	annotations, err := R.readAnnotations()
+	if err != nil {
+		return err
+	}
+	var value *fznExpr
+	if R.peek() == "=" {
+		R.next()
+		expr, err := R.readExpr()
+		if err != nil {
+			return err
+		}
+		value = &expr
+	}
+	if err := R.expect(";"); err != nil {
+		return err
+	}
+	if !declared.isVar {
+		if value == nil {
+			return fmt.Errorf("parameter %s has no value", name)
+		}
+		term, err := R.term(*value)
+		if err != nil {
+			return err
+		}
+		R.constants[name] = term
+		return nil
+	}
+	if value != nil {
+		term, err := R.term(*value)
+		if err != nil {
+			return err
+		}
+		if term.variable != "" {
+			// The alias narrows the domain of the variable it stands for
+			R.aliases[name] = term
+			R.model.DomainMap[term.variable] = goutils.Filter(R.model.DomainMap[term.variable], func(v int) bool {
+				return goutils.Contains(declared.domain, func(other int) bool { return other == v })
+			})
+		} else {
+			declared.domain = goutils.Filter(declared.domain, func(v int) bool { return v == term.value })
+			R.model.DomainMap[name] = declared.domain
+		}
+	} else {
+		R.model.DomainMap[name] = declared.domain
+	}
+	if _, ok := annotations["output_var"]; ok {
+		term, _ := R.term(fznExpr{identifier: name})
+		R.model.outputs = append(R.model.outputs, fznOutput{name: name, isBool: declared.isBool, terms: []fznTerm{term}})
+	}
+	return nil
+}
+
+func (R *fznReader) readArray() error {
+	R.next()
+	if err := R.expect("["); err != nil {
+		return err
+	}
+	if _, err := R.readExpr(); err != nil {
+		return err
+	}
+	if err := R.expect("]"); err != nil {
+		return err
+	}
+	if err := R.expect("of"); err != nil {
+		return err
+	}
+	declared, err := R.readType(true)
+	if err != nil {
+		return err
+	}
+	if err := R.expect(":"); err != nil {
+		return err
+	}
+	name := R.next()
+	annotations, err := R.readAnnotations()
+	if err != nil {
+		return err
+	}
+	if err := R.expect("="); err != nil {
+		return err
+	}
+	value, err := R.readExpr()
+	if err != nil {
+		return err
+	}
+	if err := R.expect(";"); err != nil {
+		return err
+	}
+	terms, err := R.terms(value)
+	if err != nil {
+		return err
+	}
+	R.arrays[name] = terms
+	if dimensions, ok := annotations["output_array"]; ok && len(dimensions) == 1 {
+		output := fznOutput{name: name, isBool: declared.isBool, terms: terms, dimensions: [][2]int{}}
+		for _, dimension := range dimensions[0].elements {
+			output.dimensions = append(output.dimensions, [2]int{dimension.low, dimension.high})
+		}
+		R.model.outputs = append(R.model.outputs, output)
+	}
+	return nil
+}
+
+func (R *fznReader) readExpr() (fznExpr, error) {
+	token := R.next()
+	switch {
+	case token == "":
+		return fznExpr{}, fmt.Errorf("unexpected end of model")
+	case token == "[" || token == "{":
+		closing := map[string]string{"[": "]", "{": "}"}[token]
+		elements := []fznExpr{}
+		for R.peek() != closing {
+			element, err := R.readExpr()
+			if err != nil {
+				return fznExpr{}, err
+			}
+			elements = append(elements, element)
+			if R.peek() == "," {
+				R.next()
+			} else if R.peek() != closing {
+				return fznExpr{}, fmt.Errorf("expected %q, got %q", closing, R.peek())
+			}
+		}
+		R.next()
+		if token == "[" {
+			return fznExpr{elements: elements, isArray: true}, nil
+		}
+		ret := fznExpr{isSet: true, set: []int{}}
+		for _, element := range elements {
+			if !element.isInt {
+				return fznExpr{}, fmt.Errorf("%w: set of %v", ErrUnsupported, element.identifier)
+			}
+			ret.set = append(ret.set, element.value)
+		}
+		return ret, nil
+	case token[0] == '"':
+		return fznExpr{identifier: token}, nil
+	}
+	if value, err := strconv.Atoi(token); err == nil {
+		if R.peek() != ".." {
+			return fznExpr{value: value, isInt: true}, nil
+		}
+		R.next()
+		high, err := strconv.Atoi(R.next())
+		if err != nil {
+			return fznExpr{}, fmt.Errorf("bad range %d..", value)
+		}
+		return fznExpr{isRange: true, low: value, high: high}, nil
+	}
+	switch token {
+	case "true":
+		return fznExpr{value: 1, isInt: true}, nil
+	case "false":
+		return fznExpr{value: 0, isInt: true}, nil
+	}
+	ret := fznExpr{identifier: token}
+	switch R.peek() {
+	case "[":
+		R.next()
+		index, err := strconv.Atoi(R.next())
+		if err != nil {
+			return fznExpr{}, fmt.Errorf("bad index of %s", token)
+		}
+		ret.index, ret.isIndexed = index, true
+		return ret, R.expect("]")
+	case "(":
+		R.next()
+		for R.peek() != ")" {
+			arg, err := R.readExpr()
+			if err != nil {
+				return fznExpr{}, err
+			}
+			ret.elements = append(ret.elements, arg)
+			if R.peek() == "," {
+				R.next()
+			}
+		}
+		R.next()
+	}
+	return ret, nil
+}
+
+// term resolves an expression to a variable or a constant, following aliases, parameters and array elements.
+func (R *fznReader) term(expr fznExpr) (fznTerm, error) {
+	if expr.isInt {
+		return fznTerm{value: expr.value}, nil
+	}
+	if expr.identifier == "" {
+		return fznTerm{}, fmt.Errorf("expected a variable or an integer")
+	}
+	if expr.isIndexed {
+		elements, ok := R.arrays[expr.identifier]
+		if !ok || expr.index < 1 || expr.index > len(elements) {
+			return fznTerm{}, fmt.Errorf("bad array access %s[%d]", expr.identifier, expr.index)
+		}
+		return elements[expr.index-1], nil
+	}
+	if constant, ok := R.constants[expr.identifier]; ok {
+		return constant, nil
+	}
+	if alias, ok := R.aliases[expr.identifier]; ok {
+		return alias, nil
+	}
+	if _, ok := R.model.DomainMap[expr.identifier]; ok {
+		return fznTerm{variable: expr.identifier}, nil
+	}
+	return fznTerm{}, fmt.Errorf("unknown identifier %s", expr.identifier)
+}
+
+// terms resolves an array literal or the name of an array.
+func (R *fznReader) terms(expr fznExpr) ([]fznTerm, error) {
+	if !expr.isArray {
+		elements, ok := R.arrays[expr.identifier]
+		if !ok || expr.isIndexed {
+			return nil, fmt.Errorf("expected an array, got %s", expr.identifier)
+		}
+		return elements, nil
+	}
+	ret := make([]fznTerm, len(expr.elements))
+	for i, element := range expr.elements {
+		term, err := R.term(element)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = term
+	}
+	return ret, nil
+}
+
+func (R *fznReader) constantsOf(expr fznExpr) ([]int, error) {
+	terms, err := R.terms(expr)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]int, len(terms))
+	for i, term := range terms {
+		if term.variable != "" {
+			return nil, fmt.Errorf("%w: variable %s where a constant is expected", ErrUnsupported, term.variable)
+		}
+		ret[i] = term.value
+	}
+	return ret, nil
+}
+
+func (R *fznReader) readSolve() error {
+	if _, err := R.readAnnotations(); err != nil {
+		return err
+	}
+	switch goal := R.next(); goal {
+	case "satisfy":
+	case "minimize", "maximize":
+		expr, err := R.readExpr()
+		if err != nil {
+			return err
+		}
+		term, err := R.term(expr)
+		if err != nil {
+			return err
+		}
+		if term.variable == "" {
+			break
+		}
+		R.model.Objective = term.variable
+		R.model.Maximize = goal == "maximize"
+	default:
+		return fmt.Errorf("bad solve goal %q", goal)
+	}
+	return R.expect(";")
+}
+
+func (R *fznReader) readConstraint() error {
+	call, err := R.readExpr()
+	if err != nil {
+		return err
+	}
+	if _, err := R.readAnnotations(); err != nil {
+		return err
+	}
+	if err := R.expect(";"); err != nil {
+		return err
+	}
+	args := call.elements
+	arity := map[string]int{
+		"int_lin_eq": 3, "int_lin_le": 3, "int_lin_ne": 3, "int_eq": 2, "int_ne": 2, "int_le": 2, "int_lt": 2,
+		"all_different_int": 1, "fzn_all_different_int": 1, "table_int": 2, "fzn_table_int": 2,
+		"bool2int": 2, "bool_eq": 2, "bool_not": 2, "bool_clause": 2,
+	}
+	expected, ok := arity[call.identifier]
+	if !ok {
+		return fmt.Errorf("%w: constraint %s", ErrUnsupported, call.identifier)
+	}
+	if len(args) != expected {
+		return fmt.Errorf("%s takes %d arguments, got %d", call.identifier, expected, len(args))
+	}
+	switch call.identifier {
+	case "int_lin_eq", "int_lin_le", "int_lin_ne":
+		coeffs, err := R.constantsOf(args[0])
+		if err != nil {
+			return err
+		}
+		terms, err := R.terms(args[1])
+		if err != nil {
+			return err
+		}
+		right, err := R.term(args[2])
+		if err != nil || right.variable != "" {
+			return fmt.Errorf("%s needs a constant right side", call.identifier)
+		}
+		if len(coeffs) != len(terms) {
+			return fmt.Errorf("%s has %d coefficients for %d terms", call.identifier, len(coeffs), len(terms))
+		}
+		operator := map[string]SumOperator{"int_lin_eq": SUM_EQ, "int_lin_le": SUM_LE, "int_lin_ne": SUM_NE}[call.identifier]
+		R.addLinear(coeffs, terms, operator, right.value)
+	case "int_eq", "int_ne", "int_le", "int_lt", "bool2int", "bool_eq", "bool_not":
+		a, err := R.term(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := R.term(args[1])
+		if err != nil {
+			return err
+		}
+		operator := map[string]SumOperator{
+			"int_eq": SUM_EQ, "int_ne": SUM_NE, "int_le": SUM_LE, "int_lt": SUM_LT,
+			"bool2int": SUM_EQ, "bool_eq": SUM_EQ, "bool_not": SUM_NE,
+		}[call.identifier]
+		R.addLinear([]int{1, -1}, []fznTerm{a, b}, operator, 0)
+	case "all_different_int", "fzn_all_different_int":
+		terms, err := R.terms(args[0])
+		if err != nil {
+			return err
+		}
+		variables := []string{}
+		constants := map[int]bool{}
+		for _, term := range terms {
+			if term.variable == "" {
+				if constants[term.value] {
+					R.model.inconsistent = true
+				}
+				constants[term.value] = true
+				continue
+			}
+			variables = append(variables, term.variable)
+		}
+		// Constants are removed from the domains of the variables
+		for _, variable := range variables {
+			R.model.DomainMap[variable] = goutils.Filter(R.model.DomainMap[variable], func(v int) bool { return !constants[v] })
+		}
+		R.add(&LocalAllDifferentConstraint[string, int]{Variables: &variables})
+	case "table_int", "fzn_table_int":
+		terms, err := R.terms(args[0])
+		if err != nil {
+			return err
+		}
+		flat, err := R.constantsOf(args[1])
+		if err != nil {
+			return err
+		}
+		if len(terms) == 0 || len(flat)%len(terms) != 0 {
+			return fmt.Errorf("table_int has %d values for %d terms", len(flat), len(terms))
+		}
+		variables := []string{}
+		for _, term := range terms {
+			if term.variable != "" {
+				variables = append(variables, term.variable)
+			}
+		}
+		tuples := [][]int{}
+	tuple:
+		for start := 0; start < len(flat); start += len(terms) {
+			tuple := []int{}
+			for i, term := range terms {
+				value := flat[start+i]
+				if term.variable == "" {
+					if value != term.value {
+						continue tuple
+					}
+					continue
+				}
+				tuple = append(tuple, value)
+			}
+			tuples = append(tuples, tuple)
+		}
+		if len(variables) == 0 {
+			R.model.inconsistent = R.model.inconsistent || len(tuples) == 0
+			return nil
+		}
+		R.add(NewSupportsConstraint(variables, tuples))
+	case "bool_clause":
+		positive, err := R.terms(args[0])
+		if err != nil {
+			return err
+		}
+		negative, err := R.terms(args[1])
+		if err != nil {
+			return err
+		}
+		literals := []string{}
+		for _, term := range positive {
+			if term.variable == "" && term.value != 0 {
+				return nil
+			}
+			if term.variable != "" {
+				literals = append(literals, term.variable)
+			}
+		}
+		for _, term := range negative {
+			if term.variable == "" && term.value == 0 {
+				return nil
+			}
+			if term.variable != "" {
+				literals = append(literals, "not("+term.variable+")")
+			}
+		}
+		switch len(literals) {
+		case 0:
+			R.model.inconsistent = true
+			return nil
+		case 1:
+			literals = append(literals, "false")
+		}
+		clause, err := NewIntensionConstraint("or(" + strings.Join(literals, ",") + ")")
+		if err != nil {
+			return err
+		}
+		R.add(clause)
+	}
+	return nil
+}
+
+func (R *fznReader) add(constraint Constraint[string, int]) {
+	R.model.Constraints = append(R.model.Constraints, &constraint)
+}
+
+// addLinear adds sum(coeffs[i] * terms[i]) operator right, moving the constants to the right side.
+func (R *fznReader) addLinear(coeffs []int, terms []fznTerm, operator SumOperator, right int) {
+	variables := []string{}
+	variableCoeffs := []int{}
+	for i, term := range terms {
+		if term.variable == "" {
+			right -= coeffs[i] * term.value
+			continue
+		}
+		variables = append(variables, term.variable)
+		variableCoeffs = append(variableCoeffs, coeffs[i])
+	}
+	sum := NewLinearSumConstraint(variables, variableCoeffs, operator, right, R.model.DomainMap)
+	if len(variables) == 0 {
+		R.model.inconsistent = R.model.inconsistent || !sum.IsSatisfied(map[string]int{})
+		return
+	}
+	R.add(sum)
+}
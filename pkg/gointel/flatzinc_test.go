@@ -0,0 +1,74 @@
+package gointel
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const flatZincModel = `
+% x + y = 5, all different, z follows y in the table
+array [1..2] of int: coeffs = [1, 1];
+var 1..4: x :: output_var;
+var 1..4: y :: output_var;
+var 1..4: z;
+var bool: b :: output_var;
+var 0..1: bi;
+array [1..3] of var int: xs :: output_array([1..3]) = [x, y, z];
+constraint all_different_int(xs);
+constraint int_lin_eq(coeffs, [x, y], 5);
+constraint table_int([y, z], [1, 2, 2, 3, 3, 4, 4, 1]);
+constraint bool2int(b, bi);
+constraint int_le(bi, 0);
+`
+
+func TestReadFlatZinc(t *testing.T) {
+	model, err := ReadFlatZinc(strings.NewReader(flatZincModel + "solve satisfy;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := &strings.Builder{}
+	if err := model.WriteSolutions(context.Background(), output, true, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"x = 2;\ny = 3;\nb = false;\nxs = array1d(1..3, [2, 3, 4]);\n----------\n",
+		"x = 4;\ny = 1;\nb = false;\nxs = array1d(1..3, [4, 1, 2]);\n----------\n",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected %q in\n%s", expected, output)
+		}
+	}
+	if strings.Count(output.String(), FLATZINC_SOLUTION_SEPARATOR) != 2 || !strings.HasSuffix(output.String(), FLATZINC_SEARCH_COMPLETE+"\n") {
+		t.Errorf("expected two solutions and a complete search, got\n%s", output)
+	}
+
+	model, err = ReadFlatZinc(strings.NewReader(flatZincModel + "solve minimize x;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.Reset()
+	if err := model.WriteSolutions(context.Background(), output, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(output.String(), "x = 2;\ny = 3;\nb = false;\nxs = array1d(1..3, [2, 3, 4]);\n----------\n==========\n") {
+		t.Errorf("expected the optimal solution last, got\n%s", output)
+	}
+
+	model, err = ReadFlatZinc(strings.NewReader(flatZincModel + "constraint int_lt(x, x);\nsolve satisfy;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.Reset()
+	if err := model.WriteSolutions(context.Background(), output, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if output.String() != FLATZINC_UNSATISFIABLE+"\n" {
+		t.Errorf("expected an unsatisfiable model, got\n%s", output)
+	}
+
+	if _, err := ReadFlatZinc(strings.NewReader("var 1..3: x;\nconstraint int_times(x, x, x);\nsolve satisfy;")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,440 @@
+package gointel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Interval is the closed range of reals from Low to High. It is empty when Low > High, and bounds may be infinite.
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+func NewInterval(low float64, high float64) Interval {
+	return Interval{Low: low, High: high}
+}
+
+func EntireInterval() Interval {
+	return Interval{Low: math.Inf(-1), High: math.Inf(1)}
+}
+
+func EmptyInterval() Interval {
+	return Interval{Low: math.Inf(1), High: math.Inf(-1)}
+}
+
+func (i Interval) IsEmpty() bool {
+	return !(i.Low <= i.High)
+}
+
+func (i Interval) Width() float64 {
+	if i.IsEmpty() {
+		return 0
+	}
+	return i.High - i.Low
+}
+
+// Mid is the midpoint of the interval, a finite point for half-infinite and entire intervals.
+func (i Interval) Mid() float64 {
+	switch {
+	case math.IsInf(i.Low, -1) && math.IsInf(i.High, 1):
+		return 0
+	case math.IsInf(i.Low, -1):
+		return math.Min(-1, 2*i.High)
+	case math.IsInf(i.High, 1):
+		return math.Max(1, 2*i.Low)
+	}
+	return i.Low + (i.High-i.Low)/2
+}
+
+func (i Interval) Contains(value float64) bool {
+	return i.Low <= value && value <= i.High
+}
+
+func (i Interval) Intersect(other Interval) Interval {
+	ret := Interval{Low: math.Max(i.Low, other.Low), High: math.Min(i.High, other.High)}
+	if ret.IsEmpty() {
+		return EmptyInterval()
+	}
+	return ret
+}
+
+// Hull is the smallest interval containing both.
+func (i Interval) Hull(other Interval) Interval {
+	if i.IsEmpty() {
+		return other
+	}
+	if other.IsEmpty() {
+		return i
+	}
+	return Interval{Low: math.Min(i.Low, other.Low), High: math.Max(i.High, other.High)}
+}
+
+func (i Interval) String() string {
+	if i.IsEmpty() {
+		return "[]"
+	}
+	return fmt.Sprintf("[%g, %g]", i.Low, i.High)
+}
+
+// outward widens the bounds by one ulp, so that rounding never excludes a real result.
+func outward(low float64, high float64) Interval {
+	if math.IsNaN(low) || math.IsNaN(high) {
+		return EntireInterval()
+	}
+	return Interval{Low: math.Nextafter(low, math.Inf(-1)), High: math.Nextafter(high, math.Inf(1))}
+}
+
+func (i Interval) add(other Interval) Interval {
+	return outward(i.Low+other.Low, i.High+other.High)
+}
+
+func (i Interval) sub(other Interval) Interval {
+	return outward(i.Low-other.High, i.High-other.Low)
+}
+
+func (i Interval) neg() Interval {
+	return Interval{Low: -i.High, High: -i.Low}
+}
+
+// product treats 0 times infinity as 0, which holds for the bounds of a range.
+func product(a float64, b float64) float64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return a * b
+}
+
+func (i Interval) mul(other Interval) Interval {
+	products := []float64{product(i.Low, other.Low), product(i.Low, other.High), product(i.High, other.Low), product(i.High, other.High)}
+	sort.Float64s(products)
+	return outward(products[0], products[3])
+}
+
+// div is the hull of the quotients, entire when the divisor contains 0 and empty when it is only 0. The reciprocals
+// are rounded outward before the product rounds again.
+func (i Interval) div(other Interval) Interval {
+	if other.Contains(0) {
+		if other.Low == 0 && other.High == 0 {
+			return EmptyInterval()
+		}
+		return EntireInterval()
+	}
+	return i.mul(outward(1/other.High, 1/other.Low))
+}
+
+// factor encloses the x with x * other in the interval, which is any x when both contain 0.
+func (i Interval) factor(other Interval) Interval {
+	if i.Contains(0) && other.Contains(0) {
+		return EntireInterval()
+	}
+	return i.div(other)
+}
+
+func (i Interval) pow(exponent int) Interval {
+	low, high := math.Pow(i.Low, float64(exponent)), math.Pow(i.High, float64(exponent))
+	if exponent%2 == 1 {
+		return outward(low, high)
+	}
+	if i.Contains(0) {
+		return outward(0, math.Max(low, high)).Intersect(Interval{Low: 0, High: math.Inf(1)})
+	}
+	return outward(math.Min(low, high), math.Max(low, high))
+}
+
+// root is the hull of the real roots in the interval of the values of x^exponent.
+func (i Interval) root(exponent int, x Interval) Interval {
+	if exponent%2 == 1 {
+		return outward(signedRoot(i.Low, exponent), signedRoot(i.High, exponent))
+	}
+	values := i.Intersect(Interval{Low: 0, High: math.Inf(1)})
+	if values.IsEmpty() {
+		return EmptyInterval()
+	}
+	positive := outward(math.Pow(values.Low, 1/float64(exponent)), math.Pow(values.High, 1/float64(exponent)))
+	return x.Intersect(positive).Hull(x.Intersect(positive.neg()))
+}
+
+func signedRoot(value float64, exponent int) float64 {
+	if value < 0 {
+		return -math.Pow(-value, 1/float64(exponent))
+	}
+	return math.Pow(value, 1/float64(exponent))
+}
+
+type intervalOperator int
+
+const (
+	intervalVariable intervalOperator = iota
+	intervalConstant
+	intervalAdd
+	intervalSub
+	intervalMul
+	intervalDiv
+	intervalNeg
+	intervalPow
+	intervalSqrt
+	intervalExp
+	intervalLog
+)
+
+// IntervalTerm is an arithmetic expression over named real variables, built with IntervalVariable,
+// IntervalConstant and the methods combining terms.
+type IntervalTerm struct {
+	operator intervalOperator
+	children []*IntervalTerm
+	name     string
+	value    Interval
+	exponent int
+}
+
+func IntervalVariable(name string) *IntervalTerm {
+	return &IntervalTerm{operator: intervalVariable, name: name}
+}
+
+func IntervalConstant(value float64) *IntervalTerm {
+	return &IntervalTerm{operator: intervalConstant, value: Interval{Low: value, High: value}}
+}
+
+func (t *IntervalTerm) Add(other *IntervalTerm) *IntervalTerm {
+	return &IntervalTerm{operator: intervalAdd, children: []*IntervalTerm{t, other}}
+}
+
+func (t *IntervalTerm) Sub(other *IntervalTerm) *IntervalTerm {
+	return &IntervalTerm{operator: intervalSub, children: []*IntervalTerm{t, other}}
+}
+
+func (t *IntervalTerm) Mul(other *IntervalTerm) *IntervalTerm {
+	return &IntervalTerm{operator: intervalMul, children: []*IntervalTerm{t, other}}
+}
+
+func (t *IntervalTerm) Div(other *IntervalTerm) *IntervalTerm {
+	return &IntervalTerm{operator: intervalDiv, children: []*IntervalTerm{t, other}}
+}
+
+func (t *IntervalTerm) Neg() *IntervalTerm {
+	return &IntervalTerm{operator: intervalNeg, children: []*IntervalTerm{t}}
+}
+
+// Pow raises the term to a positive integer exponent.
+func (t *IntervalTerm) Pow(exponent int) *IntervalTerm {
+	return &IntervalTerm{operator: intervalPow, children: []*IntervalTerm{t}, exponent: max(exponent, 1)}
+}
+
+func (t *IntervalTerm) Sqr() *IntervalTerm {
+	return t.Pow(2)
+}
+
+func (t *IntervalTerm) Sqrt() *IntervalTerm {
+	return &IntervalTerm{operator: intervalSqrt, children: []*IntervalTerm{t}}
+}
+
+func (t *IntervalTerm) Exp() *IntervalTerm {
+	return &IntervalTerm{operator: intervalExp, children: []*IntervalTerm{t}}
+}
+
+func (t *IntervalTerm) Log() *IntervalTerm {
+	return &IntervalTerm{operator: intervalLog, children: []*IntervalTerm{t}}
+}
+
+// Variables lists the names of the term's variables once each, in order of appearance.
+func (t *IntervalTerm) Variables() []string {
+	ret := []string{}
+	seen := map[string]bool{}
+	var visit func(term *IntervalTerm)
+	visit = func(term *IntervalTerm) {
+		if term.operator == intervalVariable && !seen[term.name] {
+			seen[term.name] = true
+			ret = append(ret, term.name)
+		}
+		for _, child := range term.children {
+			visit(child)
+		}
+	}
+	visit(t)
+	return ret
+}
+
+// Evaluate encloses the values of the term over the box, variables missing from the box range over all reals.
+func (t *IntervalTerm) Evaluate(box map[string]Interval) Interval {
+	return t.forward(box, map[*IntervalTerm]Interval{})
+}
+
+// forward evaluates the term bottom-up and records the enclosure of every node.
+func (t *IntervalTerm) forward(box map[string]Interval, nodes map[*IntervalTerm]Interval) Interval {
+	children := make([]Interval, len(t.children))
+	for i, child := range t.children {
+		children[i] = child.forward(box, nodes)
+		if children[i].IsEmpty() {
+			nodes[t] = EmptyInterval()
+			return nodes[t]
+		}
+	}
+	var ret Interval
+	switch t.operator {
+	case intervalVariable:
+		var ok bool
+		if ret, ok = box[t.name]; !ok {
+			ret = EntireInterval()
+		}
+	case intervalConstant:
+		ret = t.value
+	case intervalAdd:
+		ret = children[0].add(children[1])
+	case intervalSub:
+		ret = children[0].sub(children[1])
+	case intervalMul:
+		ret = children[0].mul(children[1])
+	case intervalDiv:
+		ret = children[0].div(children[1])
+	case intervalNeg:
+		ret = children[0].neg()
+	case intervalPow:
+		ret = children[0].pow(t.exponent)
+	case intervalSqrt:
+		domain := children[0].Intersect(Interval{Low: 0, High: math.Inf(1)})
+		ret = EmptyInterval()
+		if !domain.IsEmpty() {
+			ret = outward(math.Sqrt(domain.Low), math.Sqrt(domain.High)).Intersect(Interval{Low: 0, High: math.Inf(1)})
+		}
+	case intervalExp:
+		ret = outward(math.Exp(children[0].Low), math.Exp(children[0].High)).Intersect(Interval{Low: 0, High: math.Inf(1)})
+	case intervalLog:
+		domain := children[0].Intersect(Interval{Low: 0, High: math.Inf(1)})
+		ret = EmptyInterval()
+		if !domain.IsEmpty() && domain.High > 0 {
+			ret = outward(math.Log(domain.Low), math.Log(domain.High))
+		}
+	}
+	nodes[t] = ret
+	return ret
+}
+
+// backward narrows the node to target and projects it onto the children, then onto the variables of the box.
+// It returns false when a node becomes empty, so the box holds no solution.
+func (t *IntervalTerm) backward(target Interval, box map[string]Interval, nodes map[*IntervalTerm]Interval) bool {
+	value := nodes[t].Intersect(target)
+	if value.IsEmpty() {
+		return false
+	}
+	nodes[t] = value
+	var projections []Interval
+	children := make([]Interval, len(t.children))
+	for i, child := range t.children {
+		children[i] = nodes[child]
+	}
+	switch t.operator {
+	case intervalVariable:
+		if current, ok := box[t.name]; ok {
+			value = value.Intersect(current)
+		}
+		box[t.name] = value
+		return !value.IsEmpty()
+	case intervalConstant:
+		return true
+	case intervalAdd:
+		projections = []Interval{value.sub(children[1]), value.sub(children[0])}
+	case intervalSub:
+		projections = []Interval{value.add(children[1]), children[0].sub(value)}
+	case intervalMul:
+		projections = []Interval{value.factor(children[1]), value.factor(children[0])}
+	case intervalDiv:
+		projections = []Interval{value.mul(children[1]), children[0].factor(value)}
+	case intervalNeg:
+		projections = []Interval{value.neg()}
+	case intervalPow:
+		projections = []Interval{value.root(t.exponent, children[0])}
+	case intervalSqrt:
+		projections = []Interval{value.pow(2)}
+	case intervalExp:
+		positive := value.Intersect(Interval{Low: 0, High: math.Inf(1)})
+		projections = []Interval{EmptyInterval()}
+		if !positive.IsEmpty() && positive.High > 0 {
+			projections[0] = outward(math.Log(positive.Low), math.Log(positive.High))
+		}
+	case intervalLog:
+		projections = []Interval{outward(math.Exp(value.Low), math.Exp(value.High))}
+	}
+	for i, child := range t.children {
+		if !child.backward(projections[i], box, nodes) {
+			return false
+		}
+	}
+	return true
+}
+
+// IntervalConstraint compares two terms with SUM_LE, SUM_EQ or SUM_GE. Strict comparisons are treated as their
+// non-strict forms, and SUM_NE never prunes.
+type IntervalConstraint struct {
+	Left       *IntervalTerm
+	Operator   SumOperator
+	Right      *IntervalTerm
+	difference *IntervalTerm
+	variables  []string
+}
+
+func NewIntervalConstraint(left *IntervalTerm, operator SumOperator, right *IntervalTerm) *IntervalConstraint {
+	difference := left.Sub(right)
+	return &IntervalConstraint{Left: left, Operator: operator, Right: right, difference: difference, variables: difference.Variables()}
+}
+
+// allowed is the range of Left - Right that satisfies the operator.
+func (c *IntervalConstraint) allowed() Interval {
+	switch c.Operator {
+	case SUM_LT, SUM_LE:
+		return Interval{Low: math.Inf(-1), High: 0}
+	case SUM_EQ:
+		return Interval{Low: 0, High: 0}
+	case SUM_GE, SUM_GT:
+		return Interval{Low: 0, High: math.Inf(1)}
+	}
+	return EntireInterval()
+}
+
+// Revise narrows the box with HC4: it evaluates both sides bottom-up, then projects the allowed difference back
+// onto the variables. It returns false when the box holds no solution.
+func (c *IntervalConstraint) Revise(box map[string]Interval) bool {
+	nodes := map[*IntervalTerm]Interval{}
+	if c.difference.forward(box, nodes).IsEmpty() {
+		return false
+	}
+	return c.difference.backward(c.allowed(), box, nodes)
+}
+
+func (c *IntervalConstraint) IsPossiblySatisfied(assignment map[string]Interval) bool {
+	return !c.difference.Evaluate(assignment).Intersect(c.allowed()).IsEmpty()
+}
+
+func (c *IntervalConstraint) IsSatisfied(assignment map[string]Interval) bool {
+	return c.IsPossiblySatisfied(assignment)
+}
+
+func (c *IntervalConstraint) GetVariables() []string {
+	return c.variables
+}
+
+func (c *IntervalConstraint) AsLocal() *LocalConstraint[string, Interval] {
+	var local LocalConstraint[string, Interval] = c
+	return &local
+}
+
+func (c *IntervalConstraint) IsReusable() bool {
+	return false
+}
+
+// ReduceDomain narrows every interval of the domain by the constraint, given the intervals of the other variables.
+func (c *IntervalConstraint) ReduceDomain(variable string, assignment map[string]Interval, domain []Interval) []Interval {
+	ret := []Interval{}
+	for _, interval := range domain {
+		box := make(map[string]Interval, len(assignment)+1)
+		for name, other := range assignment {
+			box[name] = other
+		}
+		box[variable] = interval
+		if c.Revise(box) {
+			ret = append(ret, box[variable])
+		}
+	}
+	return ret
+}
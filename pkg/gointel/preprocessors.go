@@ -1,6 +1,7 @@
 package gointel
 
 import (
+	"fmt"
 	"github.com/mtresnik/goutils/pkg/goutils"
 )
 
@@ -8,6 +9,38 @@ type CSPPreprocessor[VAR comparable, DOMAIN comparable] interface {
 	Preprocess(csp *CSP[VAR, DOMAIN])
 }
 
+// FallibleCSPPreprocessor is a preprocessor that reports the models it proves inconsistent.
+type FallibleCSPPreprocessor[VAR comparable, DOMAIN comparable] interface {
+	CSPPreprocessor[VAR, DOMAIN]
+	TryPreprocess(csp *CSP[VAR, DOMAIN]) error
+}
+
+// tryPreprocess runs the preprocessors in order, stopping at the first error, then returns ErrInconsistent when a
+// variable of csp is left without values.
+func tryPreprocess[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN], preprocessors []CSPPreprocessor[VAR, DOMAIN]) error {
+	for _, preprocessor := range preprocessors {
+		fallible, ok := preprocessor.(FallibleCSPPreprocessor[VAR, DOMAIN])
+		if !ok {
+			preprocessor.Preprocess(&csp)
+			continue
+		}
+		if err := fallible.TryPreprocess(&csp); err != nil {
+			return err
+		}
+	}
+	return emptyDomainError(csp)
+}
+
+// emptyDomainError returns ErrInconsistent for the first variable of csp without values, nil when there is none.
+func emptyDomainError[VAR comparable, DOMAIN comparable](csp CSP[VAR, DOMAIN]) error {
+	for _, variable := range csp.GetVariables() {
+		if len(csp.GetDomainForVariable(variable)) == 0 {
+			return fmt.Errorf("%w: %v has an empty domain", ErrInconsistent, variable)
+		}
+	}
+	return nil
+}
+
 // AC3Preprocessor Refs https://en.wikipedia.org/wiki/AC-3_algorithm
 type AC3Preprocessor[VAR comparable, DOMAIN comparable] struct {
 }
@@ -30,10 +63,16 @@ func CloneMap[K comparable, V any](original map[K]V) map[K]V {
 	return cloned
 }
 
+// Preprocess leaves a wiped out domain empty, so the search finds no solutions, see TryPreprocess.
 func (A *AC3Preprocessor[VAR, DOMAIN]) Preprocess(cspPtr *CSP[VAR, DOMAIN]) {
+	_ = A.TryPreprocess(cspPtr)
+}
+
+// TryPreprocess reduces the domains like Preprocess and returns ErrInconsistent when a variable is left without values.
+func (A *AC3Preprocessor[VAR, DOMAIN]) TryPreprocess(cspPtr *CSP[VAR, DOMAIN]) error {
 	currentDomain := map[VAR][]DOMAIN{}
 	if cspPtr == nil {
-		return
+		return nil
 	}
 	csp := *cspPtr
 	originalDomain := CloneMapWithSlices(csp.GetDomainMap())
@@ -66,30 +105,33 @@ func (A *AC3Preprocessor[VAR, DOMAIN]) Preprocess(cspPtr *CSP[VAR, DOMAIN]) {
 		})
 	}
 
+	var err error
+	for _, variable := range variables {
+		if len(currentDomain[variable]) == 0 {
+			err = fmt.Errorf("%w: %v has an empty domain", ErrInconsistent, variable)
+		}
+	}
+
 	// Find all domains that work for binary constraints
 	workQueue := []LocalConstraint[VAR, DOMAIN]{}
 	for _, it := range binaryConstraints {
 		workQueue = append(workQueue, it...)
 	}
 
-	for {
-		if len(workQueue) > 0 {
-			constraint := workQueue[0]
-			workQueue = workQueue[1:]
-			x, y := constraint.GetVariables()[0], constraint.GetVariables()[1]
-			shared := getSharedConstraints(x, y, binaryConstraints)
-			if arcReduce(x, y, shared, currentDomain) && len(currentDomain) == 0 {
-				panic("variable has an empty domain!")
-			}
-		}
-		if len(workQueue) == 0 {
-			break
+	for err == nil && len(workQueue) > 0 {
+		constraint := workQueue[0]
+		workQueue = workQueue[1:]
+		x, y := constraint.GetVariables()[0], constraint.GetVariables()[1]
+		shared := getSharedConstraints(x, y, binaryConstraints)
+		if arcReduce(x, y, shared, currentDomain) && len(currentDomain[x]) == 0 {
+			err = fmt.Errorf("%w: %v has an empty domain", ErrInconsistent, x)
 		}
 	}
 
 	// Set values for CSP
 	newDomain := CloneMap(currentDomain)
 	csp.SetDomainMap(newDomain)
+	return err
 }
 
 func arcReduce[VAR comparable, DOMAIN comparable](x VAR, y VAR, binaryConstraints []LocalConstraint[VAR, DOMAIN], currentDomain map[VAR][]DOMAIN) bool {
@@ -0,0 +1,24 @@
+package puzzles
+
+import "github.com/mtresnik/gointel/pkg/gointel"
+
+// GraphColoring gives every node of the edge list one of the colors 0..colors-1, different from its neighbors'.
+func GraphColoring[NODE comparable](edges [][2]NODE, colors int) *Puzzle[NODE] {
+	domainMap := map[NODE][]int{}
+	puzzle := newPuzzle(domainMap)
+	for _, edge := range edges {
+		for _, node := range edge {
+			if _, ok := domainMap[node]; !ok {
+				domainMap[node] = valueRange(0, colors-1)
+			}
+		}
+		if edge[0] == edge[1] {
+			// A loop can't be colored
+			domainMap[edge[0]] = []int{}
+			continue
+		}
+		nodes := []NODE{edge[0], edge[1]}
+		puzzle.add(&gointel.LocalAllDifferentConstraint[NODE, int]{Variables: &nodes})
+	}
+	return puzzle
+}
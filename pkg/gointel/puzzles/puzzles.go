@@ -0,0 +1,98 @@
+// Package puzzles builds ready-made gointel models of classic puzzles: Sudoku and its killer and diagonal variants,
+// N-Queens, graph coloring, magic squares and Latin squares. Grid puzzles number the cell at row r and column c of a
+// size×size grid as r*size+c, Grid turns their solutions back into rows.
+package puzzles
+
+import (
+	"fmt"
+	"github.com/mtresnik/gointel/pkg/gointel"
+)
+
+// Puzzle is the factory request of a model. Fields such as Solver or MaxTime can be changed before NewCSP builds it.
+type Puzzle[VAR comparable] struct {
+	gointel.CSPFactoryRequest[VAR, int]
+}
+
+func (P *Puzzle[VAR]) NewCSP() gointel.CSP[VAR, int] {
+	return *gointel.DefaultCSPFactory(P.CSPFactoryRequest)
+}
+
+func (P *Puzzle[VAR]) add(constraint gointel.Constraint[VAR, int]) {
+	P.Constraints = append(P.Constraints, &constraint)
+}
+
+func newPuzzle[VAR comparable](domainMap map[VAR][]int) *Puzzle[VAR] {
+	return &Puzzle[VAR]{gointel.CSPFactoryRequest[VAR, int]{DomainMap: domainMap}}
+}
+
+// Cell is a position of a grid puzzle.
+type Cell struct {
+	Row int
+	Col int
+}
+
+func (c Cell) Index(size int) int {
+	return c.Row*size + c.Col
+}
+
+func valueRange(low int, high int) []int {
+	ret := make([]int, 0, high-low+1)
+	for value := low; value <= high; value++ {
+		ret = append(ret, value)
+	}
+	return ret
+}
+
+// newGrid gives every cell of a size×size grid the values low..high, or its clue when the clue isn't 0.
+func newGrid(size int, low int, high int, clues [][]int) (map[int][]int, error) {
+	if clues != nil && len(clues) != size {
+		return nil, fmt.Errorf("%w: %d rows of clues for a grid of %d", gointel.ErrShapeMismatch, len(clues), size)
+	}
+	domainMap := map[int][]int{}
+	for row := 0; row < size; row++ {
+		if clues != nil && len(clues[row]) != size {
+			return nil, fmt.Errorf("%w: %d clues in row %d of a grid of %d", gointel.ErrShapeMismatch, len(clues[row]), row, size)
+		}
+		for col := 0; col < size; col++ {
+			cell := Cell{row, col}.Index(size)
+			if clues != nil && clues[row][col] != 0 {
+				if clues[row][col] < low || clues[row][col] > high {
+					return nil, fmt.Errorf("clue %d at row %d, column %d is outside %d..%d", clues[row][col], row, col, low, high)
+				}
+				domainMap[cell] = []int{clues[row][col]}
+				continue
+			}
+			domainMap[cell] = valueRange(low, high)
+		}
+	}
+	return domainMap, nil
+}
+
+func allDifferent(variables []int) gointel.Constraint[int, int] {
+	return &gointel.LocalAllDifferentConstraint[int, int]{Variables: &variables}
+}
+
+// addRowsAndColumns makes the values of every row and every column different.
+func addRowsAndColumns(puzzle *Puzzle[int], size int) {
+	for i := 0; i < size; i++ {
+		row, col := []int{}, []int{}
+		for j := 0; j < size; j++ {
+			row = append(row, Cell{i, j}.Index(size))
+			col = append(col, Cell{j, i}.Index(size))
+		}
+		puzzle.add(allDifferent(row))
+		puzzle.add(allDifferent(col))
+	}
+}
+
+// Grid lays the solution of a size×size grid puzzle out in rows, cells without a value are 0.
+func Grid(solution map[int]int, size int) [][]int {
+	ret := make([][]int, size)
+	for row := range ret {
+		ret[row] = make([]int, size)
+		for col := range ret[row] {
+			ret[row][col] = solution[Cell{row, col}.Index(size)]
+		}
+	}
+	return ret
+}
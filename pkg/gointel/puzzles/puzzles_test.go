@@ -0,0 +1,167 @@
+package puzzles
+
+import (
+	"errors"
+	"github.com/mtresnik/gointel/pkg/gointel"
+	"math/rand"
+	"testing"
+)
+
+func TestSudoku(t *testing.T) {
+	clues := [][]int{
+		{5, 3, 0, 0, 7, 0, 0, 0, 0},
+		{6, 0, 0, 1, 9, 5, 0, 0, 0},
+		{0, 9, 8, 0, 0, 0, 0, 6, 0},
+		{8, 0, 0, 0, 6, 0, 0, 0, 3},
+		{4, 0, 0, 8, 0, 3, 0, 0, 1},
+		{7, 0, 0, 0, 2, 0, 0, 0, 6},
+		{0, 6, 0, 0, 0, 0, 2, 8, 0},
+		{0, 0, 0, 4, 1, 9, 0, 0, 5},
+		{0, 0, 0, 0, 8, 0, 0, 7, 9},
+	}
+	puzzle, err := Sudoku(3, clues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid := Grid(puzzle.NewCSP().FindOneSolution(), 9)
+	if expected := []int{5, 3, 4, 6, 7, 8, 9, 1, 2}; !equalRows(grid[0], expected) {
+		t.Errorf("expected first row %v, got %v", expected, grid[0])
+	}
+	if expected := []int{3, 4, 5, 2, 8, 6, 1, 7, 9}; !equalRows(grid[8], expected) {
+		t.Errorf("expected last row %v, got %v", expected, grid[8])
+	}
+
+	empty, _ := Sudoku(2, nil)
+	if solutions := empty.NewCSP().FindAllSolutions(); len(solutions) != 288 {
+		t.Errorf("expected 288 4x4 sudokus, got %d", len(solutions))
+	}
+	diagonal, _ := DiagonalSudoku(2, nil)
+	if solutions := diagonal.NewCSP().FindAllSolutions(); len(solutions) != 48 {
+		t.Errorf("expected 48 4x4 diagonal sudokus, got %d", len(solutions))
+	}
+	killer, _ := KillerSudoku(2, []Cage{{Cells: []Cell{{0, 0}, {0, 1}}, Sum: 3}, {Cells: []Cell{{1, 0}, {1, 1}}, Sum: 7}}, nil)
+	killerSolutions := killer.NewCSP().FindAllSolutions()
+	if len(killerSolutions) == 0 {
+		t.Error("expected killer sudokus")
+	}
+	for _, solution := range killerSolutions {
+		grid := Grid(solution, 4)
+		if grid[0][0]+grid[0][1] != 3 || grid[1][0]+grid[1][1] != 7 {
+			t.Errorf("solution %v breaks a cage", grid)
+		}
+	}
+
+	if _, err := Sudoku(3, clues[:8]); !errors.Is(err, gointel.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+}
+
+func equalRows(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNQueens(t *testing.T) {
+	puzzle := NQueens(8)
+	solutions := puzzle.NewCSP().FindAllSolutions()
+	if len(solutions) != 92 {
+		t.Fatalf("expected 92 solutions, got %d", len(solutions))
+	}
+	board := QueensBoard(solutions[0], 8)
+	queens := 0
+	for _, row := range board {
+		for _, queen := range row {
+			if queen {
+				queens++
+			}
+		}
+	}
+	if queens != 8 {
+		t.Errorf("expected 8 queens on %v", board)
+	}
+}
+
+func TestGraphColoring(t *testing.T) {
+	triangle := [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}}
+	if solution := GraphColoring(triangle, 2).NewCSP().FindOneSolution(); solution != nil {
+		t.Errorf("a triangle can't be colored with 2 colors, got %v", solution)
+	}
+	if solutions := GraphColoring(triangle, 3).NewCSP().FindAllSolutions(); len(solutions) != 6 {
+		t.Errorf("expected 6 colorings, got %d", len(solutions))
+	}
+}
+
+func TestSquares(t *testing.T) {
+	if solutions := MagicSquare(3).NewCSP().FindAllSolutions(); len(solutions) != 8 {
+		t.Errorf("expected 8 magic squares, got %d", len(solutions))
+	}
+	latin, err := LatinSquare(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solutions := latin.NewCSP().FindAllSolutions(); len(solutions) != 576 {
+		t.Errorf("expected 576 latin squares, got %d", len(solutions))
+	}
+}
+
+func parseSudoku(rows ...string) [][]int {
+	ret := make([][]int, len(rows))
+	for i, row := range rows {
+		for _, char := range row {
+			value := 0
+			if char >= '1' && char <= '9' {
+				value = int(char - '0')
+			}
+			ret[i] = append(ret[i], value)
+		}
+	}
+	return ret
+}
+
+func TestRateSudoku(t *testing.T) {
+	easy := parseSudoku(
+		"53..7....", "6..195...", ".98....6.",
+		"8...6...3", "4..8.3..1", "7...2...6",
+		".6....28.", "...419..5", "....8..79")
+	if rating, err := RateSudoku(3, easy); err != nil || rating.Difficulty != SUDOKU_EASY || rating.Clues != 30 {
+		t.Errorf("expected an easy puzzle with 30 clues, got %+v, %v", rating, err)
+	}
+	expert := parseSudoku(
+		"8........", "..36.....", ".7..9.2..",
+		".5...7...", "....457..", "...1...3.",
+		"..1....68", "..85...1.", ".9....4..")
+	rating, err := RateSudoku(3, expert)
+	if err != nil || rating.Difficulty != SUDOKU_EXPERT || rating.SearchDepth == 0 {
+		t.Errorf("expected an expert puzzle, got %+v, %v", rating, err)
+	}
+	easy[0][2] = 5
+	if _, err := RateSudoku(3, easy); !errors.Is(err, gointel.ErrInconsistent) {
+		t.Errorf("expected ErrInconsistent, got %v", err)
+	}
+}
+
+func TestGenerateSudoku(t *testing.T) {
+	random := rand.New(rand.NewSource(7))
+	for _, difficulty := range []SudokuDifficulty{SUDOKU_EASY, SUDOKU_MEDIUM, SUDOKU_HARD} {
+		clues, rating, err := GenerateSudoku(3, difficulty, random)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rating.Difficulty != difficulty {
+			t.Errorf("expected a %v puzzle, got %+v", difficulty, rating)
+		}
+		if rated, _ := RateSudoku(3, clues); rated != rating {
+			t.Errorf("expected the rating %+v, got %+v", rating, rated)
+		}
+		if !hasUniqueSolution(3, clues) {
+			t.Errorf("expected a unique solution for %v", clues)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package puzzles
+
+import "github.com/mtresnik/gointel/pkg/gointel"
+
+// NQueens places n queens on an n×n board so that none attack another. Variables are the columns and values the
+// rows of their queens.
+func NQueens(n int) *Puzzle[int] {
+	domainMap := map[int][]int{}
+	columns := make([]int, n)
+	for col := range columns {
+		columns[col] = col
+		domainMap[col] = valueRange(0, n-1)
+	}
+	puzzle := newPuzzle(domainMap)
+	puzzle.add(allDifferent(columns))
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			puzzle.add(&diagonalConstraint{A: a, B: b})
+		}
+	}
+	return puzzle
+}
+
+// QueensBoard marks the queens of an NQueens solution on an n×n board indexed by row, then column.
+func QueensBoard(solution map[int]int, n int) [][]bool {
+	ret := make([][]bool, n)
+	for row := range ret {
+		ret[row] = make([]bool, n)
+	}
+	for col, row := range solution {
+		if row >= 0 && row < n && col >= 0 && col < n {
+			ret[row][col] = true
+		}
+	}
+	return ret
+}
+
+// diagonalConstraint keeps the queens of columns A and B off a shared diagonal.
+type diagonalConstraint struct {
+	A int
+	B int
+}
+
+func (d *diagonalConstraint) IsPossiblySatisfied(assignment map[int]int) bool {
+	rowA, okA := assignment[d.A]
+	rowB, okB := assignment[d.B]
+	if !okA || !okB {
+		return true
+	}
+	distance := rowA - rowB
+	if distance < 0 {
+		distance = -distance
+	}
+	return distance != d.B-d.A
+}
+
+func (d *diagonalConstraint) IsSatisfied(assignment map[int]int) bool {
+	return d.IsPossiblySatisfied(assignment)
+}
+
+func (d *diagonalConstraint) AsLocal() *gointel.LocalConstraint[int, int] {
+	var local gointel.LocalConstraint[int, int] = d
+	return &local
+}
+
+func (d *diagonalConstraint) IsReusable() bool {
+	return false
+}
+
+func (d *diagonalConstraint) GetVariables() []int {
+	return []int{d.A, d.B}
+}
+
+func (d *diagonalConstraint) ReduceDomain(variable int, assignment map[int]int, domain []int) []int {
+	other, distance := d.B, d.B-d.A
+	if variable == d.B {
+		other = d.A
+	}
+	row, ok := assignment[other]
+	if !ok {
+		return domain
+	}
+	ret := []int{}
+	for _, value := range domain {
+		if value != row-distance && value != row+distance {
+			ret = append(ret, value)
+		}
+	}
+	return ret
+}
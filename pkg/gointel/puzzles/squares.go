@@ -0,0 +1,39 @@
+package puzzles
+
+import "github.com/mtresnik/gointel/pkg/gointel"
+
+// MagicSquare fills an n×n grid with 1..n², each once, so that every row, column and main diagonal adds up to
+// n(n²+1)/2.
+func MagicSquare(n int) *Puzzle[int] {
+	domainMap, _ := newGrid(n, 1, n*n, nil)
+	puzzle := newPuzzle(domainMap)
+	puzzle.add(allDifferent(valueRange(0, n*n-1)))
+	total := n * (n*n + 1) / 2
+	lines := [][]int{}
+	main, anti := []int{}, []int{}
+	for i := 0; i < n; i++ {
+		row, col := []int{}, []int{}
+		for j := 0; j < n; j++ {
+			row = append(row, Cell{i, j}.Index(n))
+			col = append(col, Cell{j, i}.Index(n))
+		}
+		lines = append(lines, row, col)
+		main = append(main, Cell{i, i}.Index(n))
+		anti = append(anti, Cell{i, n - 1 - i}.Index(n))
+	}
+	for _, line := range append(lines, main, anti) {
+		puzzle.add(gointel.NewLinearSumConstraint(line, nil, gointel.SUM_EQ, total, domainMap))
+	}
+	return puzzle
+}
+
+// LatinSquare fills an n×n grid with 1..n so that no value repeats in a row or column. Clues are as in Sudoku.
+func LatinSquare(n int, clues [][]int) (*Puzzle[int], error) {
+	domainMap, err := newGrid(n, 1, n, clues)
+	if err != nil {
+		return nil, err
+	}
+	puzzle := newPuzzle(domainMap)
+	addRowsAndColumns(puzzle, n)
+	return puzzle, nil
+}
@@ -0,0 +1,74 @@
+package puzzles
+
+import (
+	"fmt"
+	"github.com/mtresnik/gointel/pkg/gointel"
+)
+
+// Sudoku models a board of n²×n² cells holding 1..n², different in every row, column and n×n box.
+// Clues has a row per board row with 0 for empty cells, nil for an empty board.
+func Sudoku(n int, clues [][]int) (*Puzzle[int], error) {
+	size := n * n
+	domainMap, err := newGrid(size, 1, size, clues)
+	if err != nil {
+		return nil, err
+	}
+	puzzle := newPuzzle(domainMap)
+	addRowsAndColumns(puzzle, size)
+	for boxRow := 0; boxRow < n; boxRow++ {
+		for boxCol := 0; boxCol < n; boxCol++ {
+			box := []int{}
+			for row := boxRow * n; row < (boxRow+1)*n; row++ {
+				for col := boxCol * n; col < (boxCol+1)*n; col++ {
+					box = append(box, Cell{row, col}.Index(size))
+				}
+			}
+			puzzle.add(allDifferent(box))
+		}
+	}
+	return puzzle, nil
+}
+
+// DiagonalSudoku is a Sudoku whose two main diagonals also hold different values.
+func DiagonalSudoku(n int, clues [][]int) (*Puzzle[int], error) {
+	puzzle, err := Sudoku(n, clues)
+	if err != nil {
+		return nil, err
+	}
+	size := n * n
+	main, anti := []int{}, []int{}
+	for i := 0; i < size; i++ {
+		main = append(main, Cell{i, i}.Index(size))
+		anti = append(anti, Cell{i, size - 1 - i}.Index(size))
+	}
+	puzzle.add(allDifferent(main))
+	puzzle.add(allDifferent(anti))
+	return puzzle, nil
+}
+
+// Cage is a group of cells of a killer Sudoku whose different values add up to Sum.
+type Cage struct {
+	Cells []Cell
+	Sum   int
+}
+
+// KillerSudoku is a Sudoku with cages, usually without clues.
+func KillerSudoku(n int, cages []Cage, clues [][]int) (*Puzzle[int], error) {
+	puzzle, err := Sudoku(n, clues)
+	if err != nil {
+		return nil, err
+	}
+	size := n * n
+	for _, cage := range cages {
+		cells := []int{}
+		for _, cell := range cage.Cells {
+			if cell.Row < 0 || cell.Row >= size || cell.Col < 0 || cell.Col >= size {
+				return nil, fmt.Errorf("%w: cage cell %v is off a board of %d", gointel.ErrShapeMismatch, cell, size)
+			}
+			cells = append(cells, cell.Index(size))
+		}
+		puzzle.add(allDifferent(cells))
+		puzzle.add(gointel.NewLinearSumConstraint(cells, nil, gointel.SUM_EQ, cage.Sum, puzzle.DomainMap))
+	}
+	return puzzle, nil
+}
@@ -0,0 +1,407 @@
+package puzzles
+
+import (
+	"fmt"
+	"github.com/mtresnik/gointel/pkg/gointel"
+	"math/bits"
+	"math/rand"
+	"time"
+)
+
+type SudokuDifficulty int
+
+const (
+	// SUDOKU_EASY puzzles are solved by filling the cells that have a single candidate left.
+	SUDOKU_EASY SudokuDifficulty = iota
+	// SUDOKU_MEDIUM puzzles also need the values that fit a single cell of a row, column or box.
+	SUDOKU_MEDIUM
+	// SUDOKU_HARD puzzles also need arc consistency on the all different constraint of every row, column and box.
+	SUDOKU_HARD
+	// SUDOKU_EXPERT puzzles need search.
+	SUDOKU_EXPERT
+)
+
+func (d SudokuDifficulty) String() string {
+	switch d {
+	case SUDOKU_EASY:
+		return "easy"
+	case SUDOKU_MEDIUM:
+		return "medium"
+	case SUDOKU_HARD:
+		return "hard"
+	case SUDOKU_EXPERT:
+		return "expert"
+	}
+	return fmt.Sprintf("SudokuDifficulty(%d)", int(d))
+}
+
+// SUDOKU_GENERATOR_ATTEMPTS is how many filled boards GenerateSudoku digs before giving up on a difficulty.
+const SUDOKU_GENERATOR_ATTEMPTS = 50
+
+type SudokuRating struct {
+	Difficulty SudokuDifficulty
+	Clues      int
+	// SearchDepth is the most guesses made on one branch by a search that propagates with every technique,
+	// 0 below SUDOKU_EXPERT.
+	SearchDepth int
+}
+
+// GenerateSudoku digs clues out of random filled n²×n² boards, keeping a clue whenever removing it would allow a
+// second solution, counted on the CSP, or make the puzzle harder than difficulty. It returns the first puzzle rated
+// exactly difficulty, boards of more than 64 cells a side return ErrUnsupported. A nil random is seeded from the clock.
+func GenerateSudoku(n int, difficulty SudokuDifficulty, random *rand.Rand) ([][]int, SudokuRating, error) {
+	if n < 1 || n*n > 64 {
+		return nil, SudokuRating{}, fmt.Errorf("%w: sudoku of %d×%d boxes", gointel.ErrUnsupported, n, n)
+	}
+	if random == nil {
+		random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	size := n * n
+	for attempt := 0; attempt < SUDOKU_GENERATOR_ATTEMPTS; attempt++ {
+		clues := randomSudoku(n, random)
+		if clues == nil {
+			continue
+		}
+		rating, _ := RateSudoku(n, clues)
+		for _, cell := range random.Perm(size * size) {
+			row, col := cell/size, cell%size
+			value := clues[row][col]
+			clues[row][col] = 0
+			dug, err := RateSudoku(n, clues)
+			if err != nil || dug.Difficulty > difficulty || !hasUniqueSolution(n, clues) {
+				clues[row][col] = value
+				continue
+			}
+			rating = dug
+		}
+		if rating.Difficulty == difficulty {
+			return clues, rating, nil
+		}
+	}
+	return nil, SudokuRating{}, fmt.Errorf("no %v sudoku found in %d attempts", difficulty, SUDOKU_GENERATOR_ATTEMPTS)
+}
+
+// randomSudoku fills the boxes of the diagonal with random permutations, which never conflict, and lets the CSP
+// complete the board.
+func randomSudoku(n int, random *rand.Rand) [][]int {
+	size := n * n
+	clues := make([][]int, size)
+	for row := range clues {
+		clues[row] = make([]int, size)
+	}
+	for box := 0; box < n; box++ {
+		for i, value := range random.Perm(size) {
+			clues[box*n+i/n][box*n+i%n] = value + 1
+		}
+	}
+	puzzle, err := Sudoku(n, clues)
+	if err != nil {
+		return nil
+	}
+	puzzle.Solver = gointel.CSP_SOLVER_INT
+	solution := puzzle.NewCSP().FindOneSolution()
+	if solution == nil {
+		return nil
+	}
+	return Grid(solution, size)
+}
+
+func hasUniqueSolution(n int, clues [][]int) bool {
+	puzzle, err := Sudoku(n, clues)
+	if err != nil {
+		return false
+	}
+	puzzle.Solver = gointel.CSP_SOLVER_INT
+	return gointel.CountSolutions(puzzle.NewCSP(), 2) == 1
+}
+
+// RateSudoku solves the clues with increasingly strong techniques and rates the puzzle by the strongest one needed.
+// Clues that contradict each other return ErrInconsistent.
+func RateSudoku(n int, clues [][]int) (SudokuRating, error) {
+	size := n * n
+	if n < 1 || size > 64 {
+		return SudokuRating{}, fmt.Errorf("%w: sudoku of %d×%d boxes", gointel.ErrUnsupported, n, n)
+	}
+	if _, err := newGrid(size, 1, size, clues); err != nil {
+		return SudokuRating{}, err
+	}
+	board := newSudokuBoard(n)
+	rating := SudokuRating{}
+	for row := range clues {
+		for col, value := range clues[row] {
+			if value == 0 {
+				continue
+			}
+			rating.Clues++
+			if !board.place(Cell{row, col}.Index(size), value-1) {
+				return rating, fmt.Errorf("%w: clue %d at row %d, column %d", gointel.ErrInconsistent, value, row, col)
+			}
+		}
+	}
+	difficulty, ok := board.propagate()
+	if !ok {
+		return rating, fmt.Errorf("%w: the clues have no solution", gointel.ErrInconsistent)
+	}
+	rating.Difficulty = difficulty
+	if board.isSolved() {
+		return rating, nil
+	}
+	depth, found := board.search(1)
+	if !found {
+		return rating, fmt.Errorf("%w: the clues have no solution", gointel.ErrInconsistent)
+	}
+	rating.Difficulty, rating.SearchDepth = SUDOKU_EXPERT, depth
+	return rating, nil
+}
+
+// sudokuBoard keeps the candidate values of every cell as a bitmask, bit v standing for the value v+1.
+type sudokuBoard struct {
+	size       int
+	units      [][]int
+	peers      [][]int
+	candidates []uint64
+	placed     []bool
+}
+
+func newSudokuBoard(n int) *sudokuBoard {
+	size := n * n
+	board := &sudokuBoard{
+		size:       size,
+		peers:      make([][]int, size*size),
+		candidates: make([]uint64, size*size),
+		placed:     make([]bool, size*size),
+	}
+	for i := 0; i < size; i++ {
+		row, col, box := []int{}, []int{}, []int{}
+		for j := 0; j < size; j++ {
+			row = append(row, Cell{i, j}.Index(size))
+			col = append(col, Cell{j, i}.Index(size))
+			box = append(box, Cell{(i/n)*n + j/n, (i%n)*n + j%n}.Index(size))
+		}
+		board.units = append(board.units, row, col, box)
+	}
+	for cell := range board.candidates {
+		board.candidates[cell] = 1<<size - 1
+		seen := map[int]bool{cell: true}
+		for _, unit := range board.units {
+			if !contains(unit, cell) {
+				continue
+			}
+			for _, peer := range unit {
+				if !seen[peer] {
+					seen[peer] = true
+					board.peers[cell] = append(board.peers[cell], peer)
+				}
+			}
+		}
+	}
+	return board
+}
+
+func contains(cells []int, cell int) bool {
+	for _, other := range cells {
+		if other == cell {
+			return true
+		}
+	}
+	return false
+}
+
+func (B *sudokuBoard) clone() *sudokuBoard {
+	return &sudokuBoard{
+		size:       B.size,
+		units:      B.units,
+		peers:      B.peers,
+		candidates: append([]uint64{}, B.candidates...),
+		placed:     append([]bool{}, B.placed...),
+	}
+}
+
+func (B *sudokuBoard) isSolved() bool {
+	for _, placed := range B.placed {
+		if !placed {
+			return false
+		}
+	}
+	return true
+}
+
+// place fixes the cell to the value and removes the value from its peers, returning false when a peer runs out.
+func (B *sudokuBoard) place(cell int, value int) bool {
+	bit := uint64(1) << value
+	if B.candidates[cell]&bit == 0 {
+		return false
+	}
+	B.candidates[cell] = bit
+	B.placed[cell] = true
+	for _, peer := range B.peers[cell] {
+		if B.candidates[peer]&bit != 0 {
+			B.candidates[peer] &^= bit
+			if B.candidates[peer] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// propagate applies the weakest technique that makes progress until none does, returning the strongest one used
+// and false when a contradiction shows up.
+func (B *sudokuBoard) propagate() (SudokuDifficulty, bool) {
+	used := SUDOKU_EASY
+	techniques := []func() (bool, bool){B.nakedSingles, B.hiddenSingles, B.unitConsistency}
+	for !B.isSolved() {
+		progress := false
+		for difficulty, technique := range techniques {
+			changed, ok := technique()
+			if !ok {
+				return used, false
+			}
+			if changed {
+				used = max(used, SudokuDifficulty(difficulty))
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+	return used, true
+}
+
+func (B *sudokuBoard) nakedSingles() (bool, bool) {
+	changed := false
+	for cell, candidates := range B.candidates {
+		if !B.placed[cell] && bits.OnesCount64(candidates) == 1 {
+			changed = true
+			if !B.place(cell, bits.TrailingZeros64(candidates)) {
+				return true, false
+			}
+		}
+	}
+	return changed, true
+}
+
+func (B *sudokuBoard) hiddenSingles() (bool, bool) {
+	changed := false
+	for _, unit := range B.units {
+		for value := 0; value < B.size; value++ {
+			bit := uint64(1) << value
+			last, count, done := -1, 0, false
+			for _, cell := range unit {
+				if B.candidates[cell]&bit == 0 {
+					continue
+				}
+				if B.placed[cell] {
+					done = true
+					break
+				}
+				last = cell
+				count++
+			}
+			switch {
+			case done:
+			case count == 0:
+				return changed, false
+			case count == 1:
+				changed = true
+				if !B.place(last, value) {
+					return true, false
+				}
+			}
+		}
+	}
+	return changed, true
+}
+
+// unitConsistency removes the candidates of a cell that leave the other open cells of a unit without distinct
+// values, which makes every unit's all different constraint arc consistent.
+func (B *sudokuBoard) unitConsistency() (bool, bool) {
+	changed := false
+	for _, unit := range B.units {
+		open := []int{}
+		for _, cell := range unit {
+			if !B.placed[cell] {
+				open = append(open, cell)
+			}
+		}
+		for i, cell := range open {
+			for candidates := B.candidates[cell]; candidates != 0; candidates &= candidates - 1 {
+				value := bits.TrailingZeros64(candidates)
+				if B.hasMatching(open, i, value) {
+					continue
+				}
+				changed = true
+				B.candidates[cell] &^= 1 << value
+				if B.candidates[cell] == 0 {
+					return true, false
+				}
+			}
+		}
+	}
+	return changed, true
+}
+
+// hasMatching tells whether the open cells other than fixed can take distinct candidates other than value.
+func (B *sudokuBoard) hasMatching(open []int, fixed int, value int) bool {
+	owners := make([]int, B.size)
+	for i := range owners {
+		owners[i] = -1
+	}
+	var augment func(i int, visited *uint64) bool
+	augment = func(i int, visited *uint64) bool {
+		for candidates := B.candidates[open[i]] &^ (1 << value); candidates != 0; candidates &= candidates - 1 {
+			other := bits.TrailingZeros64(candidates)
+			if *visited&(1<<other) != 0 {
+				continue
+			}
+			*visited |= 1 << other
+			if owners[other] == -1 || augment(owners[other], visited) {
+				owners[other] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range open {
+		if i == fixed {
+			continue
+		}
+		visited := uint64(0)
+		if !augment(i, &visited) {
+			return false
+		}
+	}
+	return true
+}
+
+// search guesses the values of the open cell with the fewest candidates, propagating after each guess. It returns
+// the deepest guess made and whether a solution was found.
+func (B *sudokuBoard) search(depth int) (int, bool) {
+	best := -1
+	for cell, candidates := range B.candidates {
+		if !B.placed[cell] && (best == -1 || bits.OnesCount64(candidates) < bits.OnesCount64(B.candidates[best])) {
+			best = cell
+		}
+	}
+	deepest := depth
+	for candidates := B.candidates[best]; candidates != 0; candidates &= candidates - 1 {
+		child := B.clone()
+		if !child.place(best, bits.TrailingZeros64(candidates)) {
+			continue
+		}
+		if _, ok := child.propagate(); !ok {
+			continue
+		}
+		if child.isSolved() {
+			return deepest, true
+		}
+		reached, found := child.search(depth + 1)
+		deepest = max(deepest, reached)
+		if found {
+			return deepest, true
+		}
+	}
+	return deepest, false
+}
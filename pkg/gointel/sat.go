@@ -0,0 +1,568 @@
+package gointel
+
+import (
+	"context"
+	"sort"
+)
+
+// Literal is a DIMACS literal: the variable v >= 1 as v, or its negation as -v.
+type Literal int
+
+func (l Literal) Variable() int {
+	if l < 0 {
+		return int(-l)
+	}
+	return int(l)
+}
+
+func (l Literal) Negate() Literal {
+	return -l
+}
+
+// index maps the literals of every variable to consecutive slots of the watch lists.
+func (l Literal) index() int {
+	if l < 0 {
+		return 2*int(-l) + 1
+	}
+	return 2 * int(l)
+}
+
+// CNF is a formula in conjunctive normal form over the variables 1..NumVariables.
+type CNF struct {
+	NumVariables int
+	Clauses      [][]Literal
+}
+
+func (F *CNF) NewVariable() Literal {
+	F.NumVariables++
+	return Literal(F.NumVariables)
+}
+
+func (F *CNF) AddClause(literals ...Literal) {
+	for _, literal := range literals {
+		F.NumVariables = max(F.NumVariables, literal.Variable())
+	}
+	F.Clauses = append(F.Clauses, append([]Literal{}, literals...))
+}
+
+type SATStatus int
+
+const (
+	SAT_UNKNOWN SATStatus = iota
+	SAT_SATISFIABLE
+	SAT_UNSATISFIABLE
+)
+
+const (
+	// SAT_RESTART_BASE is the number of conflicts of the first restart, later restarts follow the Luby sequence.
+	SAT_RESTART_BASE     = 100
+	satVariableDecay     = 0.95
+	satClauseDecay       = 0.999
+	satActivityLimit     = 1e100
+	satActivityRescale   = 1e-100
+	satMinLearntClauses  = 1000
+	satLearntGrowthRatio = 1.1
+)
+
+type satClause struct {
+	literals []Literal
+	learnt   bool
+	activity float64
+	deleted  bool
+}
+
+// SATSolver is a CDCL solver: two watched literals per clause for unit propagation, first UIP clause learning,
+// VSIDS branching with phase saving, Luby restarts and deletion of inactive learnt clauses.
+// Clauses can be added between calls to Solve, for example to block the solutions found so far.
+type SATSolver struct {
+	numVariables      int
+	clauses           []*satClause
+	learnts           []*satClause
+	watches           [][]*satClause
+	values            []int8
+	levels            []int
+	reasons           []*satClause
+	trail             []Literal
+	trailLimits       []int
+	propagated        int
+	activity          []float64
+	activityIncrement float64
+	clauseIncrement   float64
+	polarity          []bool
+	seen              []bool
+	order             satVariableHeap
+	maxLearnts        float64
+	unsatisfiable     bool
+	model             []bool
+	// Conflicts counts the conflicts of every call to Solve.
+	Conflicts int
+}
+
+func NewSATSolver(cnf *CNF) *SATSolver {
+	solver := &SATSolver{activityIncrement: 1, clauseIncrement: 1}
+	solver.order.activity = &solver.activity
+	solver.grow(cnf.NumVariables)
+	for _, clause := range cnf.Clauses {
+		solver.AddClause(clause...)
+	}
+	solver.maxLearnts = max(float64(len(solver.clauses))/3, satMinLearntClauses)
+	return solver
+}
+
+func (S *SATSolver) NumVariables() int {
+	return S.numVariables
+}
+
+func (S *SATSolver) grow(numVariables int) {
+	for S.numVariables < numVariables {
+		S.numVariables++
+		if len(S.values) == 0 {
+			// Slot 0 is unused, as in DIMACS
+			S.values, S.levels, S.reasons = []int8{0}, []int{0}, []*satClause{nil}
+			S.activity, S.polarity, S.seen = []float64{0}, []bool{false}, []bool{false}
+			S.watches = [][]*satClause{nil, nil}
+		}
+		S.values = append(S.values, 0)
+		S.levels = append(S.levels, 0)
+		S.reasons = append(S.reasons, nil)
+		S.activity = append(S.activity, 0)
+		S.polarity = append(S.polarity, false)
+		S.seen = append(S.seen, false)
+		S.watches = append(S.watches, nil, nil)
+		S.order.insert(S.numVariables)
+	}
+}
+
+// value is 1 for a true literal, -1 for a false one and 0 while its variable is unassigned.
+func (S *SATSolver) value(literal Literal) int8 {
+	value := S.values[literal.Variable()]
+	if literal < 0 {
+		return -value
+	}
+	return value
+}
+
+func (S *SATSolver) decisionLevel() int {
+	return len(S.trailLimits)
+}
+
+// AddClause adds a clause at the root level, returning false once the formula is known to be unsatisfiable.
+func (S *SATSolver) AddClause(literals ...Literal) bool {
+	if S.unsatisfiable {
+		return false
+	}
+	S.cancelUntil(0)
+	clause := []Literal{}
+	seen := map[Literal]bool{}
+	for _, literal := range literals {
+		S.grow(literal.Variable())
+		if seen[-literal] || S.value(literal) == 1 {
+			return true
+		}
+		if !seen[literal] && S.value(literal) == 0 {
+			seen[literal] = true
+			clause = append(clause, literal)
+		}
+	}
+	switch len(clause) {
+	case 0:
+		S.unsatisfiable = true
+		return false
+	case 1:
+		S.enqueue(clause[0], nil)
+		if S.propagate() != nil {
+			S.unsatisfiable = true
+			return false
+		}
+		return true
+	}
+	c := &satClause{literals: clause}
+	S.attach(c)
+	S.clauses = append(S.clauses, c)
+	return true
+}
+
+func (S *SATSolver) attach(c *satClause) {
+	S.watches[c.literals[0].index()] = append(S.watches[c.literals[0].index()], c)
+	S.watches[c.literals[1].index()] = append(S.watches[c.literals[1].index()], c)
+}
+
+func (S *SATSolver) enqueue(literal Literal, reason *satClause) {
+	variable := literal.Variable()
+	S.values[variable] = 1
+	if literal < 0 {
+		S.values[variable] = -1
+	}
+	S.levels[variable] = S.decisionLevel()
+	S.reasons[variable] = reason
+	S.trail = append(S.trail, literal)
+}
+
+// propagate assigns the literals implied by unit clauses, returning the clause that became false, if any.
+// The first literal of a reason clause is the literal it implied.
+func (S *SATSolver) propagate() *satClause {
+	for S.propagated < len(S.trail) {
+		falseLiteral := -S.trail[S.propagated]
+		S.propagated++
+		watchers := S.watches[falseLiteral.index()]
+		kept := watchers[:0]
+		var conflict *satClause
+		for i := 0; i < len(watchers); i++ {
+			c := watchers[i]
+			if c.deleted {
+				continue
+			}
+			literals := c.literals
+			if literals[0] == falseLiteral {
+				literals[0], literals[1] = literals[1], literals[0]
+			}
+			if S.value(literals[0]) == 1 {
+				kept = append(kept, c)
+				continue
+			}
+			moved := false
+			for k := 2; k < len(literals); k++ {
+				if S.value(literals[k]) != -1 {
+					literals[1], literals[k] = literals[k], literals[1]
+					S.watches[literals[1].index()] = append(S.watches[literals[1].index()], c)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+			kept = append(kept, c)
+			if S.value(literals[0]) == -1 {
+				conflict = c
+				kept = append(kept, watchers[i+1:]...)
+				break
+			}
+			S.enqueue(literals[0], c)
+		}
+		S.watches[falseLiteral.index()] = kept
+		if conflict != nil {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// analyze derives the first UIP clause of the conflict, with the asserting literal first and a literal of the
+// backtrack level second.
+func (S *SATSolver) analyze(conflict *satClause) ([]Literal, int) {
+	learnt := []Literal{0}
+	pending := 0
+	var literal Literal
+	index := len(S.trail) - 1
+	for c := conflict; ; {
+		if c.learnt {
+			S.bumpClause(c)
+		}
+		start := 0
+		if literal != 0 {
+			start = 1
+		}
+		for _, other := range c.literals[start:] {
+			variable := other.Variable()
+			if S.seen[variable] || S.levels[variable] == 0 {
+				continue
+			}
+			S.seen[variable] = true
+			S.bumpVariable(variable)
+			if S.levels[variable] >= S.decisionLevel() {
+				pending++
+			} else {
+				learnt = append(learnt, other)
+			}
+		}
+		for !S.seen[S.trail[index].Variable()] {
+			index--
+		}
+		literal = S.trail[index]
+		index--
+		c = S.reasons[literal.Variable()]
+		S.seen[literal.Variable()] = false
+		pending--
+		if pending == 0 {
+			break
+		}
+	}
+	learnt[0] = -literal
+
+	// Drop literals implied by the others
+	marked := append([]Literal{}, learnt[1:]...)
+	minimized := learnt[:1]
+	for _, other := range learnt[1:] {
+		if !S.isRedundant(other) {
+			minimized = append(minimized, other)
+		}
+	}
+	for _, other := range marked {
+		S.seen[other.Variable()] = false
+	}
+	learnt = minimized
+
+	level := 0
+	if len(learnt) > 1 {
+		highest := 1
+		for i := 2; i < len(learnt); i++ {
+			if S.levels[learnt[i].Variable()] > S.levels[learnt[highest].Variable()] {
+				highest = i
+			}
+		}
+		learnt[1], learnt[highest] = learnt[highest], learnt[1]
+		level = S.levels[learnt[1].Variable()]
+	}
+	return learnt, level
+}
+
+// isRedundant tells whether every other literal of the literal's reason is in the learnt clause or at the root level.
+func (S *SATSolver) isRedundant(literal Literal) bool {
+	reason := S.reasons[literal.Variable()]
+	if reason == nil {
+		return false
+	}
+	for _, other := range reason.literals[1:] {
+		if !S.seen[other.Variable()] && S.levels[other.Variable()] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (S *SATSolver) cancelUntil(level int) {
+	if S.decisionLevel() <= level {
+		return
+	}
+	for i := len(S.trail) - 1; i >= S.trailLimits[level]; i-- {
+		variable := S.trail[i].Variable()
+		S.polarity[variable] = S.trail[i] > 0
+		S.values[variable] = 0
+		S.reasons[variable] = nil
+		S.order.insert(variable)
+	}
+	S.trail = S.trail[:S.trailLimits[level]]
+	S.trailLimits = S.trailLimits[:level]
+	S.propagated = len(S.trail)
+}
+
+func (S *SATSolver) bumpVariable(variable int) {
+	S.activity[variable] += S.activityIncrement
+	if S.activity[variable] > satActivityLimit {
+		for i := range S.activity {
+			S.activity[i] *= satActivityRescale
+		}
+		S.activityIncrement *= satActivityRescale
+	}
+	S.order.increase(variable)
+}
+
+func (S *SATSolver) bumpClause(c *satClause) {
+	c.activity += S.clauseIncrement
+	if c.activity > satActivityLimit {
+		for _, learnt := range S.learnts {
+			learnt.activity *= satActivityRescale
+		}
+		S.clauseIncrement *= satActivityRescale
+	}
+}
+
+// reduceLearnts deletes the less active half of the learnt clauses, keeping binary ones and current reasons.
+func (S *SATSolver) reduceLearnts() {
+	sort.Slice(S.learnts, func(i, j int) bool {
+		return S.learnts[i].activity < S.learnts[j].activity
+	})
+	kept := S.learnts[:0]
+	for i, c := range S.learnts {
+		first := c.literals[0]
+		locked := S.reasons[first.Variable()] == c && S.value(first) == 1
+		if i < len(S.learnts)/2 && len(c.literals) > 2 && !locked {
+			c.deleted = true
+			continue
+		}
+		kept = append(kept, c)
+	}
+	S.learnts = kept
+}
+
+// pickBranch returns the unassigned variable with the highest activity, or 0 when every variable is assigned.
+func (S *SATSolver) pickBranch() Literal {
+	for S.order.size() > 0 {
+		variable := S.order.removeMax()
+		if S.values[variable] == 0 {
+			if S.polarity[variable] {
+				return Literal(variable)
+			}
+			return Literal(-variable)
+		}
+	}
+	return 0
+}
+
+// luby returns the i-th element of the Luby sequence 1 1 2 1 1 2 4 ...
+func luby(i int) int {
+	size, power := 1, 1
+	for size < i+1 {
+		size = 2*size + 1
+		power *= 2
+	}
+	for size-1 != i {
+		size = (size - 1) / 2
+		power /= 2
+		i %= size
+	}
+	return power
+}
+
+func (S *SATSolver) Solve() SATStatus {
+	return S.SolveContext(context.Background())
+}
+
+// SolveContext returns SAT_UNKNOWN when ctx is done before the search ends.
+func (S *SATSolver) SolveContext(ctx context.Context) SATStatus {
+	S.model = nil
+	if S.unsatisfiable {
+		return SAT_UNSATISFIABLE
+	}
+	if S.propagate() != nil {
+		S.unsatisfiable = true
+		return SAT_UNSATISFIABLE
+	}
+	for restart := 0; ctx.Err() == nil; restart++ {
+		if status := S.search(ctx, luby(restart)*SAT_RESTART_BASE); status != SAT_UNKNOWN {
+			return status
+		}
+	}
+	S.cancelUntil(0)
+	return SAT_UNKNOWN
+}
+
+func (S *SATSolver) search(ctx context.Context, budget int) SATStatus {
+	conflicts := 0
+	for {
+		if conflict := S.propagate(); conflict != nil {
+			conflicts++
+			S.Conflicts++
+			if S.decisionLevel() == 0 {
+				S.unsatisfiable = true
+				return SAT_UNSATISFIABLE
+			}
+			learnt, level := S.analyze(conflict)
+			S.cancelUntil(level)
+			if len(learnt) == 1 {
+				S.enqueue(learnt[0], nil)
+			} else {
+				c := &satClause{literals: learnt, learnt: true}
+				S.attach(c)
+				S.learnts = append(S.learnts, c)
+				S.bumpClause(c)
+				S.enqueue(learnt[0], c)
+			}
+			S.activityIncrement /= satVariableDecay
+			S.clauseIncrement /= satClauseDecay
+			continue
+		}
+		if conflicts >= budget || ctx.Err() != nil {
+			S.cancelUntil(0)
+			return SAT_UNKNOWN
+		}
+		if float64(len(S.learnts)-len(S.trail)) >= S.maxLearnts {
+			S.reduceLearnts()
+			S.maxLearnts *= satLearntGrowthRatio
+		}
+		decision := S.pickBranch()
+		if decision == 0 {
+			S.model = make([]bool, S.numVariables+1)
+			for variable := 1; variable <= S.numVariables; variable++ {
+				S.model[variable] = S.values[variable] == 1
+			}
+			S.cancelUntil(0)
+			return SAT_SATISFIABLE
+		}
+		S.trailLimits = append(S.trailLimits, len(S.trail))
+		S.enqueue(decision, nil)
+	}
+}
+
+// Model returns the assignment of the last satisfiable Solve indexed by variable, slot 0 is unused.
+func (S *SATSolver) Model() []bool {
+	return S.model
+}
+
+// satVariableHeap orders the variables by decreasing activity.
+type satVariableHeap struct {
+	activity  *[]float64
+	variables []int
+	positions map[int]int
+}
+
+func (H *satVariableHeap) size() int {
+	return len(H.variables)
+}
+
+func (H *satVariableHeap) less(i, j int) bool {
+	return (*H.activity)[H.variables[i]] > (*H.activity)[H.variables[j]]
+}
+
+func (H *satVariableHeap) swap(i, j int) {
+	H.variables[i], H.variables[j] = H.variables[j], H.variables[i]
+	H.positions[H.variables[i]] = i
+	H.positions[H.variables[j]] = j
+}
+
+func (H *satVariableHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !H.less(i, parent) {
+			return
+		}
+		H.swap(i, parent)
+		i = parent
+	}
+}
+
+func (H *satVariableHeap) down(i int) {
+	for {
+		best := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(H.variables) && H.less(child, best) {
+				best = child
+			}
+		}
+		if best == i {
+			return
+		}
+		H.swap(i, best)
+		i = best
+	}
+}
+
+func (H *satVariableHeap) insert(variable int) {
+	if H.positions == nil {
+		H.positions = map[int]int{}
+	}
+	if _, ok := H.positions[variable]; ok {
+		return
+	}
+	H.variables = append(H.variables, variable)
+	H.positions[variable] = len(H.variables) - 1
+	H.up(len(H.variables) - 1)
+}
+
+func (H *satVariableHeap) increase(variable int) {
+	if position, ok := H.positions[variable]; ok {
+		H.up(position)
+	}
+}
+
+func (H *satVariableHeap) removeMax() int {
+	top := H.variables[0]
+	H.swap(0, len(H.variables)-1)
+	H.variables = H.variables[:len(H.variables)-1]
+	delete(H.positions, top)
+	if len(H.variables) > 0 {
+		H.down(0)
+	}
+	return top
+}
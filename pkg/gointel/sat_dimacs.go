@@ -0,0 +1,89 @@
+package gointel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadDIMACS parses a formula in the DIMACS CNF format: comment lines start with c, the problem line is
+// "p cnf <variables> <clauses>" and every clause is a list of literals ended by 0.
+func ReadDIMACS(r io.Reader) (*CNF, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<26)
+	cnf := &CNF{}
+	clause := []Literal{}
+	header := false
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "c"):
+			continue
+		case strings.HasPrefix(text, "%"):
+			// End of the formula in the SATLIB benchmarks
+			return cnf, nil
+		case strings.HasPrefix(text, "p"):
+			fields := strings.Fields(text)
+			if header || len(fields) != 4 || fields[1] != "cnf" {
+				return nil, fmt.Errorf("dimacs line %d: invalid problem line %q", line, text)
+			}
+			variables, err := strconv.Atoi(fields[2])
+			if err != nil || variables < 0 {
+				return nil, fmt.Errorf("dimacs line %d: invalid variable count %q", line, fields[2])
+			}
+			cnf.NumVariables = variables
+			header = true
+			continue
+		}
+		if !header {
+			return nil, fmt.Errorf("dimacs line %d: clause before the problem line", line)
+		}
+		for _, field := range strings.Fields(text) {
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("dimacs line %d: invalid literal %q", line, field)
+			}
+			if value == 0 {
+				cnf.AddClause(clause...)
+				clause = clause[:0]
+				continue
+			}
+			clause = append(clause, Literal(value))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("dimacs: missing problem line")
+	}
+	if len(clause) > 0 {
+		cnf.AddClause(clause...)
+	}
+	return cnf, nil
+}
+
+func ReadDIMACSFile(path string) (*CNF, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadDIMACS(file)
+}
+
+func WriteDIMACS(w io.Writer, cnf *CNF) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "p cnf %d %d\n", cnf.NumVariables, len(cnf.Clauses))
+	for _, clause := range cnf.Clauses {
+		for _, literal := range clause {
+			writer.WriteString(strconv.Itoa(int(literal)))
+			writer.WriteByte(' ')
+		}
+		writer.WriteString("0\n")
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,377 @@
+package gointel
+
+import (
+	"fmt"
+	"github.com/mtresnik/goutils/pkg/goutils"
+	"sort"
+)
+
+type SATEncoding int
+
+const (
+	// SAT_DIRECT has a boolean per value of a variable, with clauses for at least and at most one of them.
+	SAT_DIRECT SATEncoding = iota
+	// SAT_ORDER has a boolean x <= d per value of a variable, and defines the value booleans from consecutive ones.
+	SAT_ORDER
+)
+
+// SAT_MAX_TUPLES caps the assignments SATEncoder enumerates for a local constraint without a dedicated encoding.
+const SAT_MAX_TUPLES = 1 << 16
+
+// SATEncoder translates a finite domain CSP into a CNF. Every value of a variable gets a literal that is true when
+// the variable takes it, so extension, all different and cardinality constraints are encoded as clauses over these
+// literals. Other local constraints are encoded by the assignments of their scope that violate them.
+type SATEncoder[VAR comparable, DOMAIN comparable] struct {
+	CNF       *CNF
+	Encoding  SATEncoding
+	MaxTuples int
+	variables []VAR
+	domains   map[VAR][]DOMAIN
+	literals  map[VAR]map[DOMAIN]Literal
+}
+
+// NewSATEncoder declares the variables of the domain map. The order encoding sorts domains with compare, or keeps
+// them as listed when compare is nil.
+func NewSATEncoder[VAR comparable, DOMAIN comparable](domainMap map[VAR][]DOMAIN, encoding SATEncoding, compare func(a, b DOMAIN) int) *SATEncoder[VAR, DOMAIN] {
+	E := &SATEncoder[VAR, DOMAIN]{
+		CNF:       &CNF{},
+		Encoding:  encoding,
+		MaxTuples: SAT_MAX_TUPLES,
+		domains:   map[VAR][]DOMAIN{},
+		literals:  map[VAR]map[DOMAIN]Literal{},
+	}
+	for variable, domain := range domainMap {
+		values := []DOMAIN{}
+		literals := map[DOMAIN]Literal{}
+		for _, value := range domain {
+			if _, ok := literals[value]; !ok {
+				literals[value] = E.CNF.NewVariable()
+				values = append(values, value)
+			}
+		}
+		if compare != nil {
+			sort.SliceStable(values, func(i, j int) bool {
+				return compare(values[i], values[j]) < 0
+			})
+		}
+		E.variables = append(E.variables, variable)
+		E.domains[variable] = values
+		E.literals[variable] = literals
+		E.encodeDomain(values, literals)
+	}
+	return E
+}
+
+func (E *SATEncoder[VAR, DOMAIN]) encodeDomain(values []DOMAIN, literals map[DOMAIN]Literal) {
+	direct := make([]Literal, len(values))
+	for i, value := range values {
+		direct[i] = literals[value]
+	}
+	if E.Encoding == SAT_DIRECT || len(values) < 2 {
+		E.CNF.AddClause(direct...)
+		E.atMost(direct, 1)
+		return
+	}
+	// orders[i] is x <= values[i], the last one always holds
+	orders := make([]Literal, len(values)-1)
+	for i := range orders {
+		orders[i] = E.CNF.NewVariable()
+		if i > 0 {
+			E.CNF.AddClause(-orders[i-1], orders[i])
+		}
+	}
+	for i, literal := range direct {
+		// values[i] holds exactly when x <= values[i] and not x <= values[i-1]
+		definition := []Literal{literal}
+		if i < len(orders) {
+			E.CNF.AddClause(-literal, orders[i])
+			definition = append(definition, -orders[i])
+		}
+		if i > 0 {
+			E.CNF.AddClause(-literal, -orders[i-1])
+			definition = append(definition, orders[i-1])
+		}
+		E.CNF.AddClause(definition...)
+	}
+}
+
+// Literal returns the literal that is true when the variable takes the value.
+func (E *SATEncoder[VAR, DOMAIN]) Literal(variable VAR, value DOMAIN) (Literal, bool) {
+	literal, ok := E.literals[variable][value]
+	return literal, ok
+}
+
+// valueLiterals returns the literals of the variables that can take the value.
+func (E *SATEncoder[VAR, DOMAIN]) valueLiterals(variables []VAR, value DOMAIN) []Literal {
+	ret := []Literal{}
+	for _, variable := range variables {
+		if literal, ok := E.Literal(variable, value); ok {
+			ret = append(ret, literal)
+		}
+	}
+	return ret
+}
+
+func (E *SATEncoder[VAR, DOMAIN]) checkScope(constraint Constraint[VAR, DOMAIN], scope []VAR) error {
+	for _, variable := range scope {
+		if _, ok := E.domains[variable]; !ok {
+			return fmt.Errorf("%w: %s has the undeclared variable %v", ErrUnsupported, constraintLabel(constraint, scope), variable)
+		}
+	}
+	return nil
+}
+
+// Supports returns the ErrUnsupported that Encode would return for the constraint, without adding clauses.
+// Global constraints other than all different are unsupported, as are local constraints over undeclared variables
+// or that need more than MaxTuples assignments enumerated.
+func (E *SATEncoder[VAR, DOMAIN]) Supports(constraint Constraint[VAR, DOMAIN]) error {
+	constraint = unlabeled(constraint)
+	var scope []VAR
+	switch c := constraint.(type) {
+	case *ExtensionConstraint[VAR, DOMAIN]:
+		scope = c.Variables
+	case *LocalAllDifferentConstraint[VAR, DOMAIN]:
+		scope = *c.Variables
+	case *GlobalAllDifferentConstraint[VAR, DOMAIN]:
+		return nil
+	case *CountConstraint[VAR, DOMAIN]:
+		scope = c.Variables
+	case *CardinalityConstraint[VAR, DOMAIN]:
+		scope = c.Variables
+	case *GlobalCardinalityConstraint[VAR, DOMAIN]:
+		scope = c.Variables
+	default:
+		local := constraint.AsLocal()
+		if local == nil {
+			return fmt.Errorf("%w: global constraint %s", ErrUnsupported, constraintLabel(constraint, nil))
+		}
+		scope = goutils.Unique((*local).GetVariables())
+		if err := E.checkScope(constraint, scope); err != nil {
+			return err
+		}
+		count := 1
+		for _, variable := range scope {
+			count *= max(len(E.domains[variable]), 1)
+			if count > E.MaxTuples {
+				return fmt.Errorf("%w: %s has more than %d tuples", ErrUnsupported, constraintLabel(constraint, scope), E.MaxTuples)
+			}
+		}
+		return nil
+	}
+	return E.checkScope(constraint, scope)
+}
+
+// Encode adds the clauses of the constraint, or returns the error of Supports.
+func (E *SATEncoder[VAR, DOMAIN]) Encode(constraint Constraint[VAR, DOMAIN]) error {
+	if err := E.Supports(constraint); err != nil {
+		return err
+	}
+	switch c := unlabeled(constraint).(type) {
+	case *ExtensionConstraint[VAR, DOMAIN]:
+		E.encodeExtension(c)
+	case *LocalAllDifferentConstraint[VAR, DOMAIN]:
+		E.encodeAllDifferent(*c.Variables)
+	case *GlobalAllDifferentConstraint[VAR, DOMAIN]:
+		E.encodeAllDifferent(E.variables)
+	case *CountConstraint[VAR, DOMAIN]:
+		E.encodeCount(c.Variables, c.Domain, c.MinCount, c.MaxCount)
+	case *CardinalityConstraint[VAR, DOMAIN]:
+		E.encodeCount(c.Variables, c.Domain, 0, c.MaxCount)
+	case *GlobalCardinalityConstraint[VAR, DOMAIN]:
+		for value, bounds := range c.Bounds {
+			E.encodeCount(c.Variables, value, bounds.Min, bounds.Max)
+		}
+	default:
+		local := c.AsLocal()
+		E.encodeConflicts(*local, goutils.Unique((*local).GetVariables()))
+	}
+	return nil
+}
+
+// unlabeled returns the constraint a label wraps, or the constraint itself.
+func unlabeled[VAR comparable, DOMAIN comparable](constraint Constraint[VAR, DOMAIN]) Constraint[VAR, DOMAIN] {
+	switch labeled := constraint.(type) {
+	case *LabeledConstraint[VAR, DOMAIN]:
+		return labeled.Constraint
+	case *labeledLocalConstraint[VAR, DOMAIN]:
+		return labeled.LocalConstraint
+	}
+	return constraint
+}
+
+// encodeExtension forbids every conflict tuple. A supports table gets a literal per tuple that implies its values,
+// and every value of the scope implies one of the tuples that contain it.
+func (E *SATEncoder[VAR, DOMAIN]) encodeExtension(c *ExtensionConstraint[VAR, DOMAIN]) {
+	supports := map[VAR]map[DOMAIN][]Literal{}
+	for _, variable := range c.Variables {
+		supports[variable] = map[DOMAIN][]Literal{}
+	}
+	for _, tuple := range c.Tuples {
+		literals := make([]Literal, 0, len(tuple))
+		for i, value := range tuple {
+			if literal, ok := E.Literal(c.Variables[i], value); ok {
+				literals = append(literals, literal)
+			}
+		}
+		if len(literals) < len(tuple) {
+			// A value outside the domain, so the tuple never matches
+			continue
+		}
+		if c.Conflicts {
+			clause := make([]Literal, len(literals))
+			for i, literal := range literals {
+				clause[i] = -literal
+			}
+			E.CNF.AddClause(clause...)
+			continue
+		}
+		selected := E.CNF.NewVariable()
+		for i, literal := range literals {
+			E.CNF.AddClause(-selected, literal)
+			supports[c.Variables[i]][tuple[i]] = append(supports[c.Variables[i]][tuple[i]], selected)
+		}
+	}
+	if c.Conflicts {
+		return
+	}
+	for _, variable := range c.Variables {
+		for _, value := range E.domains[variable] {
+			literal, _ := E.Literal(variable, value)
+			E.CNF.AddClause(append([]Literal{-literal}, supports[variable][value]...)...)
+		}
+	}
+}
+
+func (E *SATEncoder[VAR, DOMAIN]) encodeAllDifferent(variables []VAR) {
+	values := []DOMAIN{}
+	seen := map[DOMAIN]bool{}
+	for _, variable := range variables {
+		for _, value := range E.domains[variable] {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	for _, value := range values {
+		E.atMost(E.valueLiterals(variables, value), 1)
+	}
+}
+
+func (E *SATEncoder[VAR, DOMAIN]) encodeCount(variables []VAR, value DOMAIN, minCount int, maxCount int) {
+	literals := E.valueLiterals(variables, value)
+	E.atMost(literals, maxCount)
+	E.atLeast(literals, minCount)
+}
+
+// atMost bounds the true literals with pairwise clauses for small at most one constraints, and with Sinz's
+// sequential counter otherwise.
+func (E *SATEncoder[VAR, DOMAIN]) atMost(literals []Literal, k int) {
+	n := len(literals)
+	switch {
+	case k >= n:
+		return
+	case k < 0:
+		E.CNF.AddClause()
+		return
+	case k == 0:
+		for _, literal := range literals {
+			E.CNF.AddClause(-literal)
+		}
+		return
+	case k == 1 && n <= 6:
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				E.CNF.AddClause(-literals[i], -literals[j])
+			}
+		}
+		return
+	}
+	// counters[i][j] holds when at least j+1 of the first i+1 literals are true
+	counters := make([][]Literal, n-1)
+	for i := range counters {
+		counters[i] = make([]Literal, k)
+		for j := range counters[i] {
+			counters[i][j] = E.CNF.NewVariable()
+		}
+	}
+	E.CNF.AddClause(-literals[0], counters[0][0])
+	for j := 1; j < k; j++ {
+		E.CNF.AddClause(-counters[0][j])
+	}
+	for i := 1; i < n-1; i++ {
+		E.CNF.AddClause(-literals[i], counters[i][0])
+		E.CNF.AddClause(-counters[i-1][0], counters[i][0])
+		for j := 1; j < k; j++ {
+			E.CNF.AddClause(-literals[i], -counters[i-1][j-1], counters[i][j])
+			E.CNF.AddClause(-counters[i-1][j], counters[i][j])
+		}
+		E.CNF.AddClause(-literals[i], -counters[i-1][k-1])
+	}
+	E.CNF.AddClause(-literals[n-1], -counters[n-2][k-1])
+}
+
+func (E *SATEncoder[VAR, DOMAIN]) atLeast(literals []Literal, k int) {
+	switch {
+	case k <= 0:
+		return
+	case k == 1:
+		E.CNF.AddClause(literals...)
+		return
+	}
+	negated := make([]Literal, len(literals))
+	for i, literal := range literals {
+		negated[i] = -literal
+	}
+	E.atMost(negated, len(literals)-k)
+}
+
+// encodeConflicts forbids every assignment of the scope that violates the constraint.
+func (E *SATEncoder[VAR, DOMAIN]) encodeConflicts(constraint LocalConstraint[VAR, DOMAIN], scope []VAR) {
+	assignment := map[VAR]DOMAIN{}
+	clause := []Literal{}
+	var enumerate func(index int)
+	enumerate = func(index int) {
+		if index == len(scope) {
+			if !constraint.IsSatisfied(assignment) {
+				E.CNF.AddClause(clause...)
+			}
+			return
+		}
+		variable := scope[index]
+		for _, value := range E.domains[variable] {
+			literal, _ := E.Literal(variable, value)
+			assignment[variable] = value
+			clause = append(clause, -literal)
+			enumerate(index + 1)
+			clause = clause[:len(clause)-1]
+		}
+		delete(assignment, variable)
+	}
+	enumerate(0)
+}
+
+// Decode reads the value of every variable from a model of the CNF.
+func (E *SATEncoder[VAR, DOMAIN]) Decode(model []bool) map[VAR]DOMAIN {
+	ret := map[VAR]DOMAIN{}
+	for _, variable := range E.variables {
+		for _, value := range E.domains[variable] {
+			if literal := E.literals[variable][value]; int(literal) < len(model) && model[literal] {
+				ret[variable] = value
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// BlockingClause rules out the solution, so that solving again finds a different one.
+func (E *SATEncoder[VAR, DOMAIN]) BlockingClause(solution map[VAR]DOMAIN) []Literal {
+	clause := []Literal{}
+	for _, variable := range E.variables {
+		if literal, ok := E.Literal(variable, solution[variable]); ok {
+			clause = append(clause, -literal)
+		}
+	}
+	return clause
+}
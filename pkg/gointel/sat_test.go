@@ -0,0 +1,224 @@
+package gointel
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pigeonhole places n+1 pigeons in n holes, which is unsatisfiable.
+func pigeonhole(n int) *CNF {
+	cnf := &CNF{}
+	variable := func(pigeon, hole int) Literal {
+		return Literal(pigeon*n + hole + 1)
+	}
+	for pigeon := 0; pigeon <= n; pigeon++ {
+		clause := []Literal{}
+		for hole := 0; hole < n; hole++ {
+			clause = append(clause, variable(pigeon, hole))
+		}
+		cnf.AddClause(clause...)
+	}
+	for hole := 0; hole < n; hole++ {
+		for a := 0; a <= n; a++ {
+			for b := a + 1; b <= n; b++ {
+				cnf.AddClause(-variable(a, hole), -variable(b, hole))
+			}
+		}
+	}
+	return cnf
+}
+
+func satisfies(cnf *CNF, model []bool) bool {
+	for _, clause := range cnf.Clauses {
+		satisfied := false
+		for _, literal := range clause {
+			if model[literal.Variable()] == (literal > 0) {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			return false
+		}
+	}
+	return true
+}
+
+func bruteForceSatisfiable(cnf *CNF) bool {
+	model := make([]bool, cnf.NumVariables+1)
+	for bits := 0; bits < 1<<cnf.NumVariables; bits++ {
+		for variable := 1; variable <= cnf.NumVariables; variable++ {
+			model[variable] = bits&(1<<(variable-1)) != 0
+		}
+		if satisfies(cnf, model) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSATSolver(t *testing.T) {
+	if status := NewSATSolver(pigeonhole(6)).Solve(); status != SAT_UNSATISFIABLE {
+		t.Errorf("expected 7 pigeons in 6 holes to be unsatisfiable, got %v", status)
+	}
+
+	random := rand.New(rand.NewSource(42))
+	for instance := 0; instance < 200; instance++ {
+		cnf := &CNF{NumVariables: 10}
+		for i := 0; i < 43; i++ {
+			clause := []Literal{}
+			for j := 0; j < 3; j++ {
+				literal := Literal(random.Intn(10) + 1)
+				if random.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf.AddClause(clause...)
+		}
+		solver := NewSATSolver(cnf)
+		status := solver.Solve()
+		if expected := bruteForceSatisfiable(cnf); expected != (status == SAT_SATISFIABLE) {
+			t.Fatalf("instance %d: expected satisfiable %v, got %v", instance, expected, status)
+		}
+		if status == SAT_SATISFIABLE && !satisfies(cnf, solver.Model()) {
+			t.Fatalf("instance %d: model %v violates the formula", instance, solver.Model())
+		}
+	}
+}
+
+func TestDIMACS(t *testing.T) {
+	cnf, err := ReadDIMACS(strings.NewReader("c example\np cnf 3 3\n1 -2 0\n2 3\n0 -1 -3 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnf.NumVariables != 3 || len(cnf.Clauses) != 3 || len(cnf.Clauses[1]) != 2 {
+		t.Fatalf("unexpected formula %+v", cnf)
+	}
+	buffer := &bytes.Buffer{}
+	if err := WriteDIMACS(buffer, cnf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "p cnf 3 3\n1 -2 0\n2 3 0\n-1 -3 0\n"; buffer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buffer.String())
+	}
+	if _, err := ReadDIMACS(strings.NewReader("1 2 0\n")); err == nil {
+		t.Error("expected an error for a clause before the problem line")
+	}
+}
+
+func TestSATCSP_NQueens(t *testing.T) {
+	for _, encoding := range []SATEncoding{SAT_DIRECT, SAT_ORDER} {
+		domainMap, constraints := queensModel(8)
+		// Descending domains leave the order encoding to sort them through Compare
+		for _, domain := range domainMap {
+			slices.Reverse(domain)
+		}
+		csp := NewSATCSP(domainMap)
+		csp.Encoding = encoding
+		csp.Compare = func(a, b int) int { return a - b }
+		csp.AddAllConstraints(constraints...)
+		solutions := csp.FindAllSolutions()
+		if len(solutions) != 92 {
+			t.Errorf("encoding %d: expected 92 solutions, got %d", encoding, len(solutions))
+		}
+		for _, solution := range solutions {
+			for _, constraint := range csp.GetLocalConstraints()[0] {
+				if !(*constraint).IsSatisfied(solution) {
+					t.Fatalf("encoding %d: solution %v violates a constraint", encoding, solution)
+				}
+			}
+		}
+	}
+}
+
+func TestSATCSP_Constraints(t *testing.T) {
+	variables := []string{"a", "b", "c", "d"}
+	domainMap := map[string][]int{}
+	for _, variable := range variables {
+		domainMap[variable] = []int{0, 1, 2}
+	}
+	csp := NewSATCSP(domainMap)
+	var table Constraint[string, int] = NewSupportsConstraint([]string{"a", "b"}, [][]int{{0, 1}, {1, 2}, {2, 0}, {2, 3}})
+	var count Constraint[string, int] = NewExactlyConstraint(variables, 2, 1)
+	var cardinality Constraint[string, int] = NewGlobalCardinalityConstraint(variables, map[int]CardinalityBounds{0: {Min: 1, Max: 1}})
+	csp.AddAllConstraints(&table, &count, &cardinality)
+
+	expected := 0
+	for a := 0; a < 3; a++ {
+		for b := 0; b < 3; b++ {
+			for c := 0; c < 3; c++ {
+				for d := 0; d < 3; d++ {
+					assignment := map[string]int{"a": a, "b": b, "c": c, "d": d}
+					if table.IsSatisfied(assignment) && count.IsSatisfied(assignment) && cardinality.IsSatisfied(assignment) {
+						expected++
+					}
+				}
+			}
+		}
+	}
+	if solutions := csp.FindAllSolutions(); len(solutions) != expected {
+		t.Errorf("expected %d solutions, got %d", expected, len(solutions))
+	}
+
+	var different Constraint[string, int] = &GlobalAllDifferentConstraint[string, int]{}
+	csp.AddConstraint(&different)
+	if solution := csp.FindOneSolution(); solution != nil {
+		t.Errorf("expected four different values out of three to be infeasible, got %v", solution)
+	}
+}
+
+// oddSumConstraint is a global constraint the SAT encoder has no translation for.
+type oddSumConstraint struct{}
+
+func (c *oddSumConstraint) IsPossiblySatisfied(assignment map[string]int) bool {
+	return true
+}
+
+func (c *oddSumConstraint) GetVariables() []string {
+	return []string{}
+}
+
+func (c *oddSumConstraint) IsSatisfied(assignment map[string]int) bool {
+	sum := 0
+	for _, value := range assignment {
+		sum += value
+	}
+	return sum%2 == 1
+}
+
+func (c *oddSumConstraint) AsLocal() *LocalConstraint[string, int] {
+	return nil
+}
+
+func (c *oddSumConstraint) IsReusable() bool {
+	return false
+}
+
+func (c *oddSumConstraint) ReduceDomain(variable string, assignment map[string]int, domain []int) []int {
+	return domain
+}
+
+func TestSATCSP_Unsupported(t *testing.T) {
+	domainMap := map[string][]int{"a": {0, 1}, "b": {0, 1}}
+	var oddSum Constraint[string, int] = &oddSumConstraint{}
+	if err := NewSATCSP(CloneMapWithSlices(domainMap)).AddConstraint(&oddSum); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported adding a global constraint, got %v", err)
+	}
+	request := CSPFactoryRequest[string, int]{DomainMap: domainMap, Constraints: []*Constraint[string, int]{&oddSum}, Solver: CSP_SOLVER_SAT}
+	if _, err := TryDefaultCSPFactory(request); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected the factory to return ErrUnsupported, got %v", err)
+	}
+
+	csp := NewSATCSP(CloneMapWithSlices(domainMap))
+	var different Constraint[string, int] = &LocalAllDifferentConstraint[string, int]{Variables: &[]string{"a", "b"}}
+	csp.AddConstraint(&different)
+	csp.SetDomainMap(map[string][]int{"a": {0, 1}})
+	if solutions, err := csp.TryFindAllSolutions(); !errors.Is(err, ErrUnsupported) || len(solutions) != 0 {
+		t.Errorf("expected ErrUnsupported for a constraint over a removed variable, got %d solutions and %v", len(solutions), err)
+	}
+}
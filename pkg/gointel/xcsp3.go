@@ -0,0 +1,627 @@
+package gointel
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/mtresnik/goutils/pkg/goutils"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ReadXCSP3 parses an XCSP3 instance into a CSPDomain. The supported subset is integer variables and arrays,
+// extension, intension, allDifferent, sum, count and cardinality constraints, and blocks and groups of them.
+// A constraint's id, or its note when it has none, becomes its label. Other elements return ErrUnsupported.
+func ReadXCSP3(r io.Reader) (*CSPDomain[string, int], error) {
+	root := xcspElement{}
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "instance" {
+		return nil, fmt.Errorf("expected <instance>, got <%s>", root.XMLName.Local)
+	}
+	if kind := root.attr("type"); kind != "" && kind != "CSP" {
+		return nil, fmt.Errorf("%w: instance type %s", ErrUnsupported, kind)
+	}
+	reader := &xcspReader{domainMap: map[string][]int{}, arrays: map[string][]int{}}
+	for _, child := range root.Children {
+		if child.XMLName.Local == "variables" {
+			if err := reader.readVariables(child); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, child := range root.Children {
+		switch child.XMLName.Local {
+		case "variables":
+		case "constraints":
+			if err := reader.readConstraints(child); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: <%s>", ErrUnsupported, child.XMLName.Local)
+		}
+	}
+	csp := NewCSPDomain(reader.domainMap)
+	if err := csp.AddAllConstraints(reader.constraints...); err != nil {
+		return nil, err
+	}
+	return csp, nil
+}
+
+func ReadXCSP3File(path string) (*CSPDomain[string, int], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadXCSP3(file)
+}
+
+// xcspElement is any XML element, the reader walks the tree by element name.
+type xcspElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr    `xml:",any,attr"`
+	Text     string        `xml:",chardata"`
+	Children []xcspElement `xml:",any"`
+}
+
+func (e xcspElement) attr(name string) string {
+	for _, attr := range e.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (e xcspElement) child(name string) (xcspElement, bool) {
+	for _, child := range e.Children {
+		if child.XMLName.Local == name {
+			return child, true
+		}
+	}
+	return xcspElement{}, false
+}
+
+type xcspReader struct {
+	domainMap   map[string][]int
+	arrays      map[string][]int
+	constraints []*Constraint[string, int]
+}
+
+var xcspIndexPattern = regexp.MustCompile(`\[([^\]]*)\]`)
+
+// readVariables declares the variables, naming the cells of an array like q[0][1].
+func (R *xcspReader) readVariables(variables xcspElement) error {
+	for _, element := range variables.Children {
+		if kind := element.attr("type"); kind != "" && kind != "integer" {
+			return fmt.Errorf("%w: %s variables", ErrUnsupported, kind)
+		}
+		id := element.attr("id")
+		switch element.XMLName.Local {
+		case "var":
+			domain, err := R.readDomain(element)
+			if err != nil {
+				return err
+			}
+			R.domainMap[id] = domain
+		case "array":
+			sizes := []int{}
+			for _, match := range xcspIndexPattern.FindAllStringSubmatch(element.attr("size"), -1) {
+				size, err := strconv.Atoi(strings.TrimSpace(match[1]))
+				if err != nil {
+					return fmt.Errorf("array %s: bad size %q", id, element.attr("size"))
+				}
+				sizes = append(sizes, size)
+			}
+			if len(sizes) == 0 {
+				return fmt.Errorf("array %s: missing size", id)
+			}
+			R.arrays[id] = sizes
+			if err := R.readArrayDomains(id, element); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: <%s>", ErrUnsupported, element.XMLName.Local)
+		}
+	}
+	return nil
+}
+
+func (R *xcspReader) readDomain(element xcspElement) ([]int, error) {
+	if as := element.attr("as"); as != "" {
+		domain, ok := R.domainMap[as]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %s", as)
+		}
+		return append([]int{}, domain...), nil
+	}
+	return parseXCSPValues(element.Text)
+}
+
+func (R *xcspReader) readArrayDomains(id string, array xcspElement) error {
+	cells := R.expandReference(id + strings.Repeat("[]", len(R.arrays[id])))
+	if len(array.Children) == 0 {
+		domain, err := parseXCSPValues(array.Text)
+		if err != nil {
+			return err
+		}
+		for _, cell := range cells {
+			R.domainMap[cell] = append([]int{}, domain...)
+		}
+		return nil
+	}
+	for _, element := range array.Children {
+		domain, err := parseXCSPValues(element.Text)
+		if err != nil {
+			return err
+		}
+		if element.attr("for") == "others" {
+			for _, cell := range cells {
+				if _, ok := R.domainMap[cell]; !ok {
+					R.domainMap[cell] = append([]int{}, domain...)
+				}
+			}
+			continue
+		}
+		for _, field := range strings.Fields(element.attr("for")) {
+			for _, cell := range R.expandReference(field) {
+				if !goutils.Contains(cells, func(other string) bool { return other == cell }) {
+					return fmt.Errorf("array %s has no cell %s", id, cell)
+				}
+				R.domainMap[cell] = append([]int{}, domain...)
+			}
+		}
+	}
+	return nil
+}
+
+// parseXCSPValues reads integers and ranges like "1 3..5".
+func parseXCSPValues(text string) ([]int, error) {
+	values := []int{}
+	for _, field := range strings.Fields(text) {
+		low, high, err := parseXCSPRange(field)
+		if err != nil {
+			return nil, err
+		}
+		for value := low; value <= high; value++ {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func parseXCSPRange(field string) (int, int, error) {
+	bounds := strings.SplitN(field, "..", 2)
+	low, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad value %q", field)
+	}
+	if len(bounds) == 1 {
+		return low, low, nil
+	}
+	high, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad value %q", field)
+	}
+	return low, high, nil
+}
+
+// expandReference turns q[], q[1..2] or m[][0] into the cells it covers, other references are returned as is.
+func (R *xcspReader) expandReference(reference string) []string {
+	open := strings.IndexByte(reference, '[')
+	if open == -1 {
+		return []string{reference}
+	}
+	sizes, ok := R.arrays[reference[:open]]
+	matches := xcspIndexPattern.FindAllStringSubmatch(reference[open:], -1)
+	if !ok || len(matches) != len(sizes) {
+		return []string{reference}
+	}
+	ret := []string{reference[:open]}
+	for dimension, match := range matches {
+		low, high := 0, sizes[dimension]-1
+		if match[1] != "" {
+			var err error
+			if low, high, err = parseXCSPRange(match[1]); err != nil {
+				return []string{reference}
+			}
+		}
+		next := []string{}
+		for _, prefix := range ret {
+			for index := low; index <= high; index++ {
+				next = append(next, fmt.Sprintf("%s[%d]", prefix, index))
+			}
+		}
+		ret = next
+	}
+	return ret
+}
+
+// expandList reads a whitespace separated list of variable references.
+func (R *xcspReader) expandList(text string) ([]string, error) {
+	ret := []string{}
+	for _, field := range strings.Fields(text) {
+		for _, variable := range R.expandReference(field) {
+			if _, ok := R.domainMap[variable]; !ok {
+				return nil, fmt.Errorf("unknown variable %s", variable)
+			}
+			ret = append(ret, variable)
+		}
+	}
+	return ret, nil
+}
+
+func (R *xcspReader) readConstraints(constraints xcspElement) error {
+	for _, element := range constraints.Children {
+		switch element.XMLName.Local {
+		case "block":
+			if err := R.readConstraints(element); err != nil {
+				return err
+			}
+		case "group":
+			if err := R.readGroup(element); err != nil {
+				return err
+			}
+		default:
+			constraints, err := R.readConstraint(element)
+			if err != nil {
+				return err
+			}
+			R.constraints = append(R.constraints, constraints...)
+		}
+	}
+	return nil
+}
+
+var xcspParameterPattern = regexp.MustCompile(`%(\d+|\.\.\.)`)
+
+// readGroup instantiates the group's template once per <args>, replacing %i with the i-th argument and %... with
+// the remaining ones.
+func (R *xcspReader) readGroup(group xcspElement) error {
+	if len(group.Children) == 0 {
+		return fmt.Errorf("empty group")
+	}
+	template := group.Children[0]
+	for _, args := range group.Children[1:] {
+		if args.XMLName.Local != "args" {
+			return fmt.Errorf("%w: <%s> in a group", ErrUnsupported, args.XMLName.Local)
+		}
+		values := []string{}
+		for _, field := range strings.Fields(args.Text) {
+			values = append(values, R.expandReference(field)...)
+		}
+		used := 0
+		var err error
+		substitute := func(text string) string {
+			return xcspParameterPattern.ReplaceAllStringFunc(text, func(parameter string) string {
+				if parameter == "%..." {
+					return strings.Join(values[min(used, len(values)):], " ")
+				}
+				index, _ := strconv.Atoi(parameter[1:])
+				if index >= len(values) {
+					err = fmt.Errorf("group parameter %s has no argument", parameter)
+					return parameter
+				}
+				used = max(used, index+1)
+				return values[index]
+			})
+		}
+		constraints, readErr := R.readConstraint(template.substitute(substitute))
+		if err != nil {
+			return err
+		}
+		if readErr != nil {
+			return readErr
+		}
+		R.constraints = append(R.constraints, constraints...)
+	}
+	return nil
+}
+
+func (e xcspElement) substitute(substitute func(string) string) xcspElement {
+	ret := e
+	ret.Text = substitute(e.Text)
+	ret.Children = make([]xcspElement, len(e.Children))
+	for i, child := range e.Children {
+		ret.Children[i] = child.substitute(substitute)
+	}
+	return ret
+}
+
+// readConstraint usually returns one constraint, a sum over a range becomes two.
+func (R *xcspReader) readConstraint(element xcspElement) ([]*Constraint[string, int], error) {
+	var constraint Constraint[string, int]
+	var ret []Constraint[string, int]
+	var err error
+	switch element.XMLName.Local {
+	case "intension":
+		text := element.Text
+		if function, ok := element.child("function"); ok {
+			text = function.Text
+		}
+		var intension *IntensionConstraint
+		if intension, err = NewIntensionConstraint(text); err == nil {
+			for _, variable := range intension.GetVariables() {
+				if _, ok := R.domainMap[variable]; !ok {
+					return nil, fmt.Errorf("unknown variable %s in %s", variable, intension.Expression)
+				}
+			}
+			constraint = intension
+		}
+	case "extension":
+		constraint, err = R.readExtension(element)
+	case "allDifferent":
+		constraint, err = R.readAllDifferent(element)
+	case "sum":
+		ret, err = R.readSum(element)
+	case "count":
+		constraint, err = R.readCount(element)
+	case "cardinality":
+		constraint, err = R.readCardinality(element)
+	default:
+		return nil, fmt.Errorf("%w: <%s>", ErrUnsupported, element.XMLName.Local)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if constraint != nil {
+		ret = []Constraint[string, int]{constraint}
+	}
+	label := element.attr("id")
+	if label == "" {
+		label = element.attr("note")
+	}
+	constraints := make([]*Constraint[string, int], len(ret))
+	for i := range ret {
+		c := ret[i]
+		if label != "" {
+			c = NewLabeledConstraint(label, c)
+		}
+		constraints[i] = &c
+	}
+	return constraints, nil
+}
+
+// list reads the scope from the <list> child, or from the element's own text when it has no children.
+func (R *xcspReader) list(element xcspElement) ([]string, error) {
+	if list, ok := element.child("list"); ok {
+		return R.expandList(list.Text)
+	}
+	if len(element.Children) > 0 {
+		return nil, fmt.Errorf("<%s> is missing its <list>", element.XMLName.Local)
+	}
+	return R.expandList(element.Text)
+}
+
+func (R *xcspReader) readExtension(element xcspElement) (Constraint[string, int], error) {
+	variables, err := R.list(element)
+	if err != nil {
+		return nil, err
+	}
+	tuplesElement, conflicts := element.child("conflicts")
+	if !conflicts {
+		if tuplesElement, _ = element.child("supports"); tuplesElement.XMLName.Local == "" {
+			return nil, fmt.Errorf("<extension> needs <supports> or <conflicts>")
+		}
+	}
+	tuples := [][]int{}
+	if len(variables) == 1 {
+		values, err := parseXCSPValues(tuplesElement.Text)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			tuples = append(tuples, []int{value})
+		}
+	} else {
+		text := strings.Join(strings.Fields(tuplesElement.Text), "")
+		for _, tupleText := range strings.Split(strings.Trim(text, "()"), ")(") {
+			if tupleText == "" {
+				continue
+			}
+			fields := strings.Split(tupleText, ",")
+			if len(fields) != len(variables) {
+				return nil, fmt.Errorf("tuple (%s) has %d values for %d variables", tupleText, len(fields), len(variables))
+			}
+			expanded := [][]int{{}}
+			for i, field := range fields {
+				values := []int{}
+				if field == "*" {
+					values = R.domainMap[variables[i]]
+				} else if value, err := strconv.Atoi(field); err == nil {
+					values = []int{value}
+				} else {
+					return nil, fmt.Errorf("bad tuple value %q", field)
+				}
+				next := [][]int{}
+				for _, prefix := range expanded {
+					for _, value := range values {
+						next = append(next, append(append([]int{}, prefix...), value))
+					}
+				}
+				expanded = next
+			}
+			tuples = append(tuples, expanded...)
+		}
+	}
+	return &ExtensionConstraint[string, int]{Variables: variables, Tuples: tuples, Conflicts: conflicts}, nil
+}
+
+func (R *xcspReader) readAllDifferent(element xcspElement) (Constraint[string, int], error) {
+	for _, child := range element.Children {
+		if child.XMLName.Local != "list" {
+			return nil, fmt.Errorf("%w: <%s> in <allDifferent>", ErrUnsupported, child.XMLName.Local)
+		}
+	}
+	if len(element.Children) > 1 {
+		return nil, fmt.Errorf("%w: <allDifferent> over several lists", ErrUnsupported)
+	}
+	variables, err := R.list(element)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalAllDifferentConstraint[string, int]{Variables: &variables}, nil
+}
+
+var xcspOperators = map[string]SumOperator{"lt": SUM_LT, "le": SUM_LE, "eq": SUM_EQ, "ne": SUM_NE, "ge": SUM_GE, "gt": SUM_GT}
+
+// xcspCondition is a condition like (le,10), (eq,z) or (in,2..5).
+type xcspCondition struct {
+	operator string
+	variable string
+	low      int
+	high     int
+}
+
+func (R *xcspReader) readCondition(element xcspElement) (xcspCondition, error) {
+	conditionElement, ok := element.child("condition")
+	if !ok {
+		return xcspCondition{}, fmt.Errorf("<%s> is missing its <condition>", element.XMLName.Local)
+	}
+	text := strings.Join(strings.Fields(conditionElement.Text), "")
+	parts := strings.Split(strings.Trim(text, "()"), ",")
+	if len(parts) != 2 {
+		return xcspCondition{}, fmt.Errorf("bad condition %q", conditionElement.Text)
+	}
+	ret := xcspCondition{operator: parts[0]}
+	if _, ok := xcspOperators[ret.operator]; !ok && ret.operator != "in" {
+		return xcspCondition{}, fmt.Errorf("%w: condition operator %s", ErrUnsupported, ret.operator)
+	}
+	low, high, err := parseXCSPRange(parts[1])
+	if err == nil {
+		ret.low, ret.high = low, high
+		return ret, nil
+	}
+	if _, ok := R.domainMap[parts[1]]; ok && ret.operator != "in" {
+		ret.variable = parts[1]
+		return ret, nil
+	}
+	return xcspCondition{}, fmt.Errorf("bad condition operand %q", parts[1])
+}
+
+// readSum moves a variable right operand into the sum with coefficient -1, and splits (in,a..b) into a (ge,a) and
+// a (le,b) sum.
+func (R *xcspReader) readSum(element xcspElement) ([]Constraint[string, int], error) {
+	variables, err := R.list(element)
+	if err != nil {
+		return nil, err
+	}
+	var coeffs []int
+	if coeffsElement, ok := element.child("coeffs"); ok {
+		values, err := parseXCSPValues(coeffsElement.Text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: coefficients %q", ErrUnsupported, strings.TrimSpace(coeffsElement.Text))
+		}
+		if len(values) != len(variables) {
+			return nil, fmt.Errorf("sum has %d coefficients for %d variables", len(values), len(variables))
+		}
+		coeffs = values
+	} else {
+		coeffs = make([]int, len(variables))
+		for i := range coeffs {
+			coeffs[i] = 1
+		}
+	}
+	condition, err := R.readCondition(element)
+	if err != nil {
+		return nil, err
+	}
+	if condition.variable != "" {
+		variables = append(variables, condition.variable)
+		coeffs = append(coeffs, -1)
+	}
+	if condition.operator == "in" && condition.low != condition.high {
+		return []Constraint[string, int]{
+			NewLinearSumConstraint(variables, coeffs, SUM_GE, condition.low, R.domainMap),
+			NewLinearSumConstraint(variables, coeffs, SUM_LE, condition.high, R.domainMap),
+		}, nil
+	}
+	operator, ok := xcspOperators[condition.operator]
+	if !ok {
+		operator = SUM_EQ
+	}
+	return []Constraint[string, int]{NewLinearSumConstraint(variables, coeffs, operator, condition.low, R.domainMap)}, nil
+}
+
+func (R *xcspReader) readCount(element xcspElement) (Constraint[string, int], error) {
+	variables, err := R.list(element)
+	if err != nil {
+		return nil, err
+	}
+	valuesElement, ok := element.child("values")
+	if !ok {
+		return nil, fmt.Errorf("<count> is missing its <values>")
+	}
+	values, err := parseXCSPValues(valuesElement.Text)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("%w: count of values %q", ErrUnsupported, strings.TrimSpace(valuesElement.Text))
+	}
+	condition, err := R.readCondition(element)
+	if err != nil {
+		return nil, err
+	}
+	if condition.variable != "" {
+		return nil, fmt.Errorf("%w: count compared to a variable", ErrUnsupported)
+	}
+	minCount, maxCount := 0, len(variables)
+	switch condition.operator {
+	case "lt":
+		maxCount = condition.low - 1
+	case "le":
+		maxCount = condition.low
+	case "eq", "in":
+		minCount, maxCount = condition.low, condition.high
+	case "ge":
+		minCount = condition.low
+	case "gt":
+		minCount = condition.low + 1
+	default:
+		return nil, fmt.Errorf("%w: count condition %s", ErrUnsupported, condition.operator)
+	}
+	return &CountConstraint[string, int]{Variables: variables, Domain: values[0], MinCount: minCount, MaxCount: maxCount}, nil
+}
+
+// readCardinality restricts the domains of the list to the values when they are closed.
+func (R *xcspReader) readCardinality(element xcspElement) (Constraint[string, int], error) {
+	variables, err := R.list(element)
+	if err != nil {
+		return nil, err
+	}
+	valuesElement, hasValues := element.child("values")
+	occursElement, hasOccurs := element.child("occurs")
+	if !hasValues || !hasOccurs {
+		return nil, fmt.Errorf("<cardinality> needs <values> and <occurs>")
+	}
+	values, err := parseXCSPValues(valuesElement.Text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cardinality values %q", ErrUnsupported, strings.TrimSpace(valuesElement.Text))
+	}
+	occurs := strings.Fields(occursElement.Text)
+	if len(occurs) != len(values) {
+		return nil, fmt.Errorf("cardinality has %d occurrences for %d values", len(occurs), len(values))
+	}
+	bounds := map[int]CardinalityBounds{}
+	for i, occur := range occurs {
+		low, high, err := parseXCSPRange(occur)
+		if err != nil {
+			return nil, fmt.Errorf("%w: occurrences %q", ErrUnsupported, occur)
+		}
+		bounds[values[i]] = CardinalityBounds{Min: low, Max: high}
+	}
+	if valuesElement.attr("closed") == "true" {
+		for _, variable := range variables {
+			domain := []int{}
+			for _, value := range R.domainMap[variable] {
+				if _, ok := bounds[value]; ok {
+					domain = append(domain, value)
+				}
+			}
+			R.domainMap[variable] = domain
+		}
+	}
+	return NewGlobalCardinalityConstraint(variables, bounds), nil
+}
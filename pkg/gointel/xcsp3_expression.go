@@ -0,0 +1,323 @@
+package gointel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// IntensionConstraint is a predicate written in the functional syntax of XCSP3, like ne(x,add(y,1)).
+// Booleans are integers, 0 is false and everything else is true.
+type IntensionConstraint struct {
+	Expression string
+	root       *xcspExpression
+	variables  []string
+}
+
+func NewIntensionConstraint(expression string) (*IntensionConstraint, error) {
+	parser := &xcspExpressionParser{input: expression}
+	root, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+	variables := []string{}
+	root.collectVariables(&variables, map[string]bool{})
+	return &IntensionConstraint{Expression: strings.TrimSpace(expression), root: root, variables: variables}, nil
+}
+
+// IsPossiblySatisfied only rejects an assignment once every variable of the expression is assigned.
+func (c *IntensionConstraint) IsPossiblySatisfied(assignment map[string]int) bool {
+	value, state := c.root.evaluate(assignment)
+	return state == xcspUnassigned || (state == xcspDefined && value != 0)
+}
+
+func (c *IntensionConstraint) GetVariables() []string {
+	return c.variables
+}
+
+func (c *IntensionConstraint) IsSatisfied(assignment map[string]int) bool {
+	return c.IsPossiblySatisfied(assignment)
+}
+
+func (c *IntensionConstraint) AsLocal() *LocalConstraint[string, int] {
+	var localConstraint LocalConstraint[string, int] = c
+	return &localConstraint
+}
+
+func (c *IntensionConstraint) IsReusable() bool {
+	return false
+}
+
+func (c *IntensionConstraint) ReduceDomain(variable string, assignment map[string]int, domain []int) []int {
+	return reduceByPossiblySatisfied[string, int](c, variable, assignment, domain)
+}
+
+type xcspEvaluation int
+
+const (
+	xcspDefined xcspEvaluation = iota
+	xcspUnassigned
+	// xcspUndefined is the result of a division by zero, which no assignment satisfies.
+	xcspUndefined
+)
+
+// xcspExpression is a constant, a variable or an operator applied to its arguments.
+type xcspExpression struct {
+	operator string
+	variable string
+	constant int
+	args     []*xcspExpression
+}
+
+func (e *xcspExpression) collectVariables(variables *[]string, seen map[string]bool) {
+	if e.variable != "" && !seen[e.variable] {
+		seen[e.variable] = true
+		*variables = append(*variables, e.variable)
+	}
+	for _, arg := range e.args {
+		arg.collectVariables(variables, seen)
+	}
+}
+
+func xcspBool(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (e *xcspExpression) evaluate(assignment map[string]int) (int, xcspEvaluation) {
+	if e.variable != "" {
+		value, ok := assignment[e.variable]
+		if !ok {
+			return 0, xcspUnassigned
+		}
+		return value, xcspDefined
+	}
+	if e.operator == "" {
+		return e.constant, xcspDefined
+	}
+	if e.operator == "set" {
+		return 0, xcspDefined
+	}
+	values := make([]int, len(e.args))
+	for i, arg := range e.args {
+		value, state := arg.evaluate(assignment)
+		if state != xcspDefined {
+			return 0, state
+		}
+		values[i] = value
+	}
+	switch e.operator {
+	case "neg":
+		return -values[0], xcspDefined
+	case "abs":
+		return max(values[0], -values[0]), xcspDefined
+	case "sqr":
+		return values[0] * values[0], xcspDefined
+	case "add":
+		sum := 0
+		for _, value := range values {
+			sum += value
+		}
+		return sum, xcspDefined
+	case "mul":
+		product := 1
+		for _, value := range values {
+			product *= value
+		}
+		return product, xcspDefined
+	case "sub":
+		return values[0] - values[1], xcspDefined
+	case "div", "mod":
+		if values[1] == 0 {
+			return 0, xcspUndefined
+		}
+		if e.operator == "div" {
+			return values[0] / values[1], xcspDefined
+		}
+		return values[0] % values[1], xcspDefined
+	case "pow":
+		power := 1
+		for i := 0; i < values[1]; i++ {
+			power *= values[0]
+		}
+		return power, xcspDefined
+	case "dist":
+		return max(values[0]-values[1], values[1]-values[0]), xcspDefined
+	case "min", "max":
+		ret := values[0]
+		for _, value := range values[1:] {
+			if (e.operator == "min") == (value < ret) {
+				ret = value
+			}
+		}
+		return ret, xcspDefined
+	case "lt":
+		return xcspBool(values[0] < values[1]), xcspDefined
+	case "le":
+		return xcspBool(values[0] <= values[1]), xcspDefined
+	case "gt":
+		return xcspBool(values[0] > values[1]), xcspDefined
+	case "ge":
+		return xcspBool(values[0] >= values[1]), xcspDefined
+	case "ne":
+		return xcspBool(values[0] != values[1]), xcspDefined
+	case "eq":
+		for _, value := range values[1:] {
+			if value != values[0] {
+				return 0, xcspDefined
+			}
+		}
+		return 1, xcspDefined
+	case "iff":
+		for _, value := range values[1:] {
+			if (value != 0) != (values[0] != 0) {
+				return 0, xcspDefined
+			}
+		}
+		return 1, xcspDefined
+	case "not":
+		return xcspBool(values[0] == 0), xcspDefined
+	case "and", "or", "xor":
+		count := 0
+		for _, value := range values {
+			if value != 0 {
+				count++
+			}
+		}
+		switch e.operator {
+		case "and":
+			return xcspBool(count == len(values)), xcspDefined
+		case "or":
+			return xcspBool(count > 0), xcspDefined
+		}
+		return count % 2, xcspDefined
+	case "imp":
+		return xcspBool(values[0] == 0 || values[1] != 0), xcspDefined
+	case "if":
+		if values[0] != 0 {
+			return values[1], xcspDefined
+		}
+		return values[2], xcspDefined
+	case "in", "notin":
+		found := false
+		for _, member := range e.args[1].args {
+			value, state := member.evaluate(assignment)
+			if state != xcspDefined {
+				return 0, state
+			}
+			found = found || value == values[0]
+		}
+		return xcspBool(found == (e.operator == "in")), xcspDefined
+	}
+	return 0, xcspUndefined
+}
+
+// xcspArity is the number of arguments of each operator, -1 for two or more and -2 for any number.
+var xcspArity = map[string]int{
+	"neg": 1, "abs": 1, "sqr": 1, "not": 1,
+	"sub": 2, "div": 2, "mod": 2, "pow": 2, "dist": 2, "lt": 2, "le": 2, "gt": 2, "ge": 2, "ne": 2, "imp": 2,
+	"in": 2, "notin": 2, "if": 3,
+	"add": -1, "mul": -1, "min": -1, "max": -1, "eq": -1, "and": -1, "or": -1, "xor": -1, "iff": -1,
+	"set": -2,
+}
+
+type xcspExpressionParser struct {
+	input    string
+	position int
+}
+
+func (p *xcspExpressionParser) parse() (*xcspExpression, error) {
+	ret, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.position != len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.position:])
+	}
+	return ret, nil
+}
+
+func (p *xcspExpressionParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("intension %q at %d: "+format, append([]any{p.input, p.position}, args...)...)
+}
+
+func (p *xcspExpressionParser) skipSpaces() {
+	for p.position < len(p.input) && unicode.IsSpace(rune(p.input[p.position])) {
+		p.position++
+	}
+}
+
+// token reads a name, a variable reference like x[2][3] or an integer.
+func (p *xcspExpressionParser) token() string {
+	p.skipSpaces()
+	start := p.position
+	for p.position < len(p.input) {
+		ch := rune(p.input[p.position])
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && !strings.ContainsRune("_-[].", ch) {
+			break
+		}
+		p.position++
+	}
+	return p.input[start:p.position]
+}
+
+func (p *xcspExpressionParser) parseTerm() (*xcspExpression, error) {
+	token := p.token()
+	if token == "" {
+		return nil, p.errorf("expected a term")
+	}
+	if value, err := strconv.Atoi(token); err == nil {
+		return &xcspExpression{constant: value}, nil
+	}
+	p.skipSpaces()
+	if p.position == len(p.input) || p.input[p.position] != '(' {
+		switch token {
+		case "true":
+			return &xcspExpression{constant: 1}, nil
+		case "false":
+			return &xcspExpression{constant: 0}, nil
+		}
+		return &xcspExpression{variable: token}, nil
+	}
+	arity, ok := xcspArity[token]
+	if !ok {
+		return nil, p.errorf("%w: operator %s", ErrUnsupported, token)
+	}
+	p.position++
+	ret := &xcspExpression{operator: token}
+	p.skipSpaces()
+	if p.position < len(p.input) && p.input[p.position] == ')' {
+		p.position++
+	} else {
+		for {
+			arg, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			ret.args = append(ret.args, arg)
+			p.skipSpaces()
+			if p.position == len(p.input) {
+				return nil, p.errorf("missing )")
+			}
+			ch := p.input[p.position]
+			p.position++
+			if ch == ')' {
+				break
+			}
+			if ch != ',' {
+				return nil, p.errorf("unexpected %q", ch)
+			}
+		}
+	}
+	if arity >= 0 && len(ret.args) != arity || arity == -1 && len(ret.args) < 2 {
+		return nil, p.errorf("%s takes %d arguments, got %d", token, max(arity, 2), len(ret.args))
+	}
+	if (token == "in" || token == "notin") && ret.args[1].operator != "set" {
+		return nil, p.errorf("%s needs a set", token)
+	}
+	return ret, nil
+}
@@ -0,0 +1,154 @@
+package gointel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const xcspQueens = `
+<instance format="XCSP3" type="CSP">
+  <variables>
+    <array id="q" size="[4]"> 0..3 </array>
+  </variables>
+  <constraints>
+    <allDifferent id="rows"> q[] </allDifferent>
+    <group>
+      <intension> ne(dist(%0,%1),%2) </intension>
+      <args> q[0] q[1] 1 </args>
+      <args> q[0] q[2] 2 </args>
+      <args> q[0] q[3] 3 </args>
+      <args> q[1] q[2] 1 </args>
+      <args> q[1] q[3] 2 </args>
+      <args> q[2] q[3] 1 </args>
+    </group>
+  </constraints>
+</instance>`
+
+const xcspMixed = `
+<instance format="XCSP3" type="CSP">
+  <variables>
+    <var id="x"> 0..3 </var>
+    <var id="y" as="x"/>
+    <var id="z"> 0 1 2 3 </var>
+  </variables>
+  <constraints>
+    <extension note="y follows x">
+      <list> x y </list>
+      <supports> (0,1)(1,2)(2,3)(3,*) </supports>
+    </extension>
+    <block>
+      <sum>
+        <list> x y z </list>
+        <condition> (eq,6) </condition>
+      </sum>
+      <count>
+        <list> x y z </list>
+        <values> 3 </values>
+        <condition> (le,1) </condition>
+      </count>
+    </block>
+    <cardinality>
+      <list> x y z </list>
+      <values> 0 </values>
+      <occurs> 0 </occurs>
+    </cardinality>
+  </constraints>
+</instance>`
+
+func countMixedSolutions() int {
+	count := 0
+	for x := 0; x <= 3; x++ {
+		for y := 0; y <= 3; y++ {
+			for z := 0; z <= 3; z++ {
+				threes := 0
+				for _, value := range []int{x, y, z} {
+					if value == 3 {
+						threes++
+					}
+				}
+				if (y == x+1 || x == 3) && x+y+z == 6 && threes <= 1 && x != 0 && y != 0 && z != 0 {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+func TestReadXCSP3(t *testing.T) {
+	queens, err := ReadXCSP3(strings.NewReader(xcspQueens))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solutions := queens.FindAllSolutions(); len(solutions) != 2 {
+		t.Errorf("expected 2 solutions to 4 queens, got %d", len(solutions))
+	}
+
+	mixed, err := ReadXCSP3(strings.NewReader(xcspMixed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, solutions := countMixedSolutions(), mixed.FindAllSolutions(); len(solutions) != expected {
+		t.Errorf("expected %d solutions, got %d", expected, len(solutions))
+	}
+
+	free := `<instance format="XCSP3" type="CSP"><variables><var id="x"> 0..2 </var><var id="y"> 0..2 </var>` +
+		`<var id="z"> 0 1 </var></variables><constraints><intension> lt(x,y) </intension></constraints></instance>`
+	withFree, err := ReadXCSP3(strings.NewReader(free))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solutions := withFree.FindAllSolutions(); len(solutions) != 6 {
+		t.Errorf("expected 6 solutions with z free, got %d", len(solutions))
+	}
+	buffer := &bytes.Buffer{}
+	if err := WriteXCSP3(buffer, withFree); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buffer.String(), "extension") {
+		t.Errorf("expected the padding of z to be left out, got\n%s", buffer.String())
+	}
+
+	unsupported := `<instance format="XCSP3" type="CSP"><variables><var id="x"> 0..1 </var></variables>` +
+		`<constraints><circuit> x </circuit></constraints></instance>`
+	if _, err := ReadXCSP3(strings.NewReader(unsupported)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestWriteXCSP3(t *testing.T) {
+	for _, instance := range []string{xcspQueens, xcspMixed} {
+		original, err := ReadXCSP3(strings.NewReader(instance))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lexLess Constraint[string, int] = NewLexLessEqConstraint[string, int]([]string{original.GetVariables()[0]}, []string{original.GetVariables()[1]}, func(a, b int) int { return a - b })
+		original.AddConstraint(&lexLess)
+		buffer := &bytes.Buffer{}
+		if err := WriteXCSP3(buffer, original); err != nil {
+			t.Fatal(err)
+		}
+		written := buffer.String()
+		copied, err := ReadXCSP3(strings.NewReader(written))
+		if err != nil {
+			t.Fatalf("%v in\n%s", err, written)
+		}
+		if expected, solutions := len(original.FindAllSolutions()), copied.FindAllSolutions(); len(solutions) != expected {
+			t.Errorf("expected %d solutions after the round trip, got %d in\n%s", expected, len(solutions), written)
+		}
+	}
+
+	csp := NewCSPDomain(map[string][]int{"a": {1}, "b": {1}, "c": {1}})
+	csp.SetSortingFunction(func(a, b string) bool { return a > b })
+	before := append([]string{}, csp.GetVariables()...)
+	if err := WriteXCSP3(io.Discard, csp); err != nil {
+		t.Fatal(err)
+	}
+	if variables := csp.GetVariables(); !reflect.DeepEqual(variables, before) {
+		t.Errorf("expected writing to keep the search order %v, got %v", before, variables)
+	}
+}
@@ -0,0 +1,275 @@
+package gointel
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XCSP3_MAX_TUPLES caps the tuples WriteXCSP3 enumerates for a constraint it can only export as an extension.
+const XCSP3_MAX_TUPLES = 1 << 16
+
+// WriteXCSP3 exports the csp as an XCSP3 instance that ReadXCSP3 and other XCSP3 solvers can load.
+// Variables named like q[0][1] are written as arrays, which must have every cell. Constraints of the package are
+// written as the matching XCSP3 element, and other local constraints as the supports of their scope, up to
+// XCSP3_MAX_TUPLES. Labels become ids, or notes when they aren't valid ids. Global constraints other than
+// all different return ErrUnsupported.
+func WriteXCSP3(w io.Writer, csp CSP[string, int]) error {
+	builder := &strings.Builder{}
+	builder.WriteString("<instance format=\"XCSP3\" type=\"CSP\">\n  <variables>\n")
+	variables := append([]string{}, csp.GetVariables()...)
+	sort.Strings(variables)
+	if err := writeXCSPVariables(builder, csp, variables); err != nil {
+		return err
+	}
+	builder.WriteString("  </variables>\n  <constraints>\n")
+	for _, local := range uniqueLocalConstraints(variables, csp.GetLocalConstraints()) {
+		if err := writeXCSPConstraint(builder, csp, *local, variables); err != nil {
+			return err
+		}
+	}
+	for _, global := range csp.GetGlobalConstraints() {
+		if err := writeXCSPConstraint(builder, csp, *global, variables); err != nil {
+			return err
+		}
+	}
+	builder.WriteString("  </constraints>\n</instance>\n")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+var (
+	xcspIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	xcspCellPattern       = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)((?:\[\d+\])+)$`)
+)
+
+type xcspArray struct {
+	sizes []int
+	cells []string
+}
+
+func writeXCSPVariables(builder *strings.Builder, csp CSP[string, int], variables []string) error {
+	arrays := map[string]*xcspArray{}
+	names := []string{}
+	for _, variable := range variables {
+		match := xcspCellPattern.FindStringSubmatch(variable)
+		if match == nil {
+			if !xcspIdentifierPattern.MatchString(variable) {
+				return fmt.Errorf("%w: variable name %q", ErrUnsupported, variable)
+			}
+			fmt.Fprintf(builder, "    <var id=\"%s\"> %s </var>\n", variable, formatXCSPValues(csp.GetDomainForVariable(variable)))
+			continue
+		}
+		array, ok := arrays[match[1]]
+		if !ok {
+			array = &xcspArray{}
+			arrays[match[1]] = array
+			names = append(names, match[1])
+		}
+		indices := xcspIndexPattern.FindAllStringSubmatch(match[2], -1)
+		if array.sizes == nil {
+			array.sizes = make([]int, len(indices))
+		} else if len(array.sizes) != len(indices) {
+			return fmt.Errorf("%w: array %s has cells with different dimensions", ErrUnsupported, match[1])
+		}
+		for dimension, index := range indices {
+			value, _ := strconv.Atoi(index[1])
+			array.sizes[dimension] = max(array.sizes[dimension], value+1)
+		}
+		array.cells = append(array.cells, variable)
+	}
+	for _, name := range names {
+		array := arrays[name]
+		cells := 1
+		size := ""
+		for _, dimension := range array.sizes {
+			cells *= dimension
+			size += fmt.Sprintf("[%d]", dimension)
+		}
+		if cells != len(array.cells) {
+			return fmt.Errorf("%w: array %s is missing cells", ErrUnsupported, name)
+		}
+		domains := map[string][]string{}
+		order := []string{}
+		for _, cell := range array.cells {
+			domain := formatXCSPValues(csp.GetDomainForVariable(cell))
+			if _, ok := domains[domain]; !ok {
+				order = append(order, domain)
+			}
+			domains[domain] = append(domains[domain], cell)
+		}
+		if len(order) == 1 {
+			fmt.Fprintf(builder, "    <array id=\"%s\" size=\"%s\"> %s </array>\n", name, size, order[0])
+			continue
+		}
+		fmt.Fprintf(builder, "    <array id=\"%s\" size=\"%s\">\n", name, size)
+		for _, domain := range order {
+			fmt.Fprintf(builder, "      <domain for=\"%s\"> %s </domain>\n", strings.Join(domains[domain], " "), domain)
+		}
+		builder.WriteString("    </array>\n")
+	}
+	return nil
+}
+
+// formatXCSPValues writes the sorted values, runs of three or more as ranges.
+func formatXCSPValues(domain []int) string {
+	values := append([]int{}, domain...)
+	sort.Ints(values)
+	fields := []string{}
+	for start := 0; start < len(values); {
+		end := start
+		for end+1 < len(values) && values[end+1] == values[end]+1 {
+			end++
+		}
+		if end-start >= 2 {
+			fields = append(fields, fmt.Sprintf("%d..%d", values[start], values[end]))
+		} else {
+			for i := start; i <= end; i++ {
+				fields = append(fields, strconv.Itoa(values[i]))
+			}
+		}
+		start = end + 1
+	}
+	return strings.Join(fields, " ")
+}
+
+func xcspLabelAttribute(label string) string {
+	if label == "" {
+		return ""
+	}
+	if xcspIdentifierPattern.MatchString(label) {
+		return fmt.Sprintf(" id=\"%s\"", label)
+	}
+	escaped := &strings.Builder{}
+	_ = xml.EscapeText(escaped, []byte(label))
+	return fmt.Sprintf(" note=\"%s\"", escaped.String())
+}
+
+var xcspOperatorNames = map[SumOperator]string{SUM_LT: "lt", SUM_LE: "le", SUM_EQ: "eq", SUM_NE: "ne", SUM_GE: "ge", SUM_GT: "gt"}
+
+func writeXCSPConstraint(builder *strings.Builder, csp CSP[string, int], constraint Constraint[string, int], variables []string) error {
+	label := ""
+	switch labeled := constraint.(type) {
+	case *LabeledConstraint[string, int]:
+		label, constraint = labeled.Label, labeled.Constraint
+	case *labeledLocalConstraint[string, int]:
+		label, constraint = labeled.Label, labeled.LocalConstraint
+	}
+	attribute := xcspLabelAttribute(label)
+	switch c := constraint.(type) {
+	case *IntensionConstraint:
+		fmt.Fprintf(builder, "    <intension%s> %s </intension>\n", attribute, c.Expression)
+	case *ExtensionConstraint[string, int]:
+		writeXCSPExtension(builder, attribute, c.Variables, c.Tuples, c.Conflicts)
+	case *LocalAllDifferentConstraint[string, int]:
+		fmt.Fprintf(builder, "    <allDifferent%s> %s </allDifferent>\n", attribute, strings.Join(*c.Variables, " "))
+	case *GlobalAllDifferentConstraint[string, int]:
+		fmt.Fprintf(builder, "    <allDifferent%s> %s </allDifferent>\n", attribute, strings.Join(variables, " "))
+	case *LinearSumConstraint[string]:
+		coeffs := make([]string, len(c.Coeffs))
+		for i, coeff := range c.Coeffs {
+			coeffs[i] = strconv.Itoa(coeff)
+		}
+		fmt.Fprintf(builder, "    <sum%s>\n      <list> %s </list>\n      <coeffs> %s </coeffs>\n      <condition> (%s,%d) </condition>\n    </sum>\n",
+			attribute, strings.Join(c.Variables, " "), strings.Join(coeffs, " "), xcspOperatorNames[c.Operator], c.Right)
+	case *CountConstraint[string, int]:
+		writeXCSPCount(builder, attribute, c.Variables, c.Domain, c.MinCount, c.MaxCount)
+	case *CardinalityConstraint[string, int]:
+		writeXCSPCount(builder, attribute, c.Variables, c.Domain, 0, c.MaxCount)
+	case *GlobalCardinalityConstraint[string, int]:
+		values := []int{}
+		for value := range c.Bounds {
+			values = append(values, value)
+		}
+		sort.Ints(values)
+		occurs := make([]string, len(values))
+		for i, value := range values {
+			occurs[i] = strconv.Itoa(c.Bounds[value].Min)
+			if bounds := c.Bounds[value]; bounds.Max != bounds.Min {
+				occurs[i] = fmt.Sprintf("%d..%d", bounds.Min, bounds.Max)
+			}
+		}
+		fmt.Fprintf(builder, "    <cardinality%s>\n      <list> %s </list>\n      <values> %s </values>\n      <occurs> %s </occurs>\n    </cardinality>\n",
+			attribute, strings.Join(c.Variables, " "), strings.Trim(fmt.Sprint(values), "[]"), strings.Join(occurs, " "))
+	default:
+		local := constraint.AsLocal()
+		if local == nil {
+			return fmt.Errorf("%w: global constraint %s", ErrUnsupported, constraintLabel(constraint, nil))
+		}
+		scope := (*local).GetVariables()
+		tuples, err := enumerateSupports(csp, *local, scope)
+		if err != nil {
+			return err
+		}
+		writeXCSPExtension(builder, attribute, scope, tuples, false)
+	}
+	return nil
+}
+
+func writeXCSPCount(builder *strings.Builder, attribute string, variables []string, value, minCount, maxCount int) {
+	condition := fmt.Sprintf("(in,%d..%d)", minCount, maxCount)
+	switch {
+	case minCount == maxCount:
+		condition = fmt.Sprintf("(eq,%d)", minCount)
+	case minCount <= 0:
+		condition = fmt.Sprintf("(le,%d)", maxCount)
+	case maxCount >= len(variables):
+		condition = fmt.Sprintf("(ge,%d)", minCount)
+	}
+	fmt.Fprintf(builder, "    <count%s>\n      <list> %s </list>\n      <values> %d </values>\n      <condition> %s </condition>\n    </count>\n",
+		attribute, strings.Join(variables, " "), value, condition)
+}
+
+func writeXCSPExtension(builder *strings.Builder, attribute string, variables []string, tuples [][]int, conflicts bool) {
+	kind := "supports"
+	if conflicts {
+		kind = "conflicts"
+	}
+	text := &strings.Builder{}
+	for _, tuple := range tuples {
+		if len(variables) == 1 {
+			fmt.Fprintf(text, "%d ", tuple[0])
+			continue
+		}
+		fields := make([]string, len(tuple))
+		for i, value := range tuple {
+			fields[i] = strconv.Itoa(value)
+		}
+		fmt.Fprintf(text, "(%s)", strings.Join(fields, ","))
+	}
+	fmt.Fprintf(builder, "    <extension%s>\n      <list> %s </list>\n      <%s> %s </%s>\n    </extension>\n",
+		attribute, strings.Join(variables, " "), kind, strings.TrimSpace(text.String()), kind)
+}
+
+// enumerateSupports lists the tuples of the scope's domains that satisfy the constraint.
+func enumerateSupports(csp CSP[string, int], constraint LocalConstraint[string, int], scope []string) ([][]int, error) {
+	count := 1
+	for _, variable := range scope {
+		count *= max(len(csp.GetDomainForVariable(variable)), 1)
+		if count > XCSP3_MAX_TUPLES {
+			return nil, fmt.Errorf("%w: %s has more than %d tuples", ErrUnsupported, constraintLabel[string, int](constraint, scope), XCSP3_MAX_TUPLES)
+		}
+	}
+	tuples := [][]int{}
+	assignment := map[string]int{}
+	var enumerate func(index int, tuple []int)
+	enumerate = func(index int, tuple []int) {
+		if index == len(scope) {
+			if constraint.IsSatisfied(assignment) {
+				tuples = append(tuples, append([]int{}, tuple...))
+			}
+			return
+		}
+		for _, value := range csp.GetDomainForVariable(scope[index]) {
+			assignment[scope[index]] = value
+			enumerate(index+1, append(tuple, value))
+		}
+		delete(assignment, scope[index])
+	}
+	enumerate(0, []int{})
+	return tuples, nil
+}